@@ -0,0 +1,519 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAgendaTimeout bounds fetching a calendar unless timeout is set
+const DefaultAgendaTimeout = 30 * time.Second
+
+// MaxRecurrencePeriods bounds expanding a recurring event, a century of
+// daily events, so a rule that never matches cannot loop forever
+const MaxRecurrencePeriods = 36525
+
+// icsEvent is a VEVENT from an iCalendar file
+type icsEvent struct {
+	UID     string
+	Summary string
+	Start   time.Time // in the event's own time zone
+	AllDay  bool
+	RRule   map[string]string
+	ExDates []time.Time
+	// RecurrenceID marks an event replacing one occurrence of the
+	// recurring event with the same UID
+	RecurrenceID time.Time
+	Cancelled    bool
+}
+
+// agendaItem is one event on a day's agenda
+type agendaItem struct {
+	Start   time.Time
+	AllDay  bool
+	Summary string
+}
+
+// runAgenda adds a heading for each of a day's calendar events to that
+// day's journal entry, so meeting notes slot under sections that already
+// exist. The calendar is an .ics file or an http(s)/webcal URL, such as a
+// CalDAV calendar's export link; credentials can go in the URL.
+//
+//	note --agenda [day] [--ics <file|url>]
+func runAgenda(config Config, flags *ParsedFlags, args []string) error {
+	source := config.option("agenda_ics")
+	var dayArgs []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--ics" {
+			if i+1 >= len(args) {
+				return usageErrorf("--ics requires a file or URL")
+			}
+			i++
+			source = args[i]
+			continue
+		}
+		dayArgs = append(dayArgs, args[i])
+	}
+	if source == "" {
+		return usageErrorf("usage: note --agenda [day] --ics <file|url> (or set agenda_ics=<file|url> in ~/.note)")
+	}
+	day, err := parseJournalDate(strings.Join(dayArgs, " "), time.Now())
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	data, err := readCalendar(ctx, config, source)
+	if err != nil {
+		return err
+	}
+	events, err := parseICS(data)
+	if err != nil {
+		return err
+	}
+	items := agendaFor(events, day)
+	if len(items) == 0 {
+		fmt.Printf("No events on %s\n", day.Format("2006-01-02"))
+		return nil
+	}
+
+	notePath, err := journalEntry(config, day)
+	if err != nil {
+		return err
+	}
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	return withNotesLock(config.NotesDir, func() error {
+		content, err := os.ReadFile(notePath)
+		if err != nil {
+			return err
+		}
+		updated, added := addAgendaHeadings(string(content), items)
+		if added > 0 {
+			if err := writeFileAtomic(notePath, []byte(updated), notePerm(notePath)); err != nil {
+				return fmt.Errorf("error writing %s: %w", noteRelPath(config, notePath), err)
+			}
+			postSave(config, notePath)
+		}
+		fmt.Printf("Added %d of %d event(s) to %s\n", added, len(items), noteRelPath(config, notePath))
+		return nil
+	})
+}
+
+// readCalendar reads an .ics file, or fetches it from an http(s) or webcal
+// URL
+func readCalendar(ctx context.Context, config Config, source string) (string, error) {
+	if rest, ok := strings.CutPrefix(source, "webcal://"); ok {
+		source = "https://" + rest
+	}
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(expandPath(source))
+		if err != nil {
+			return "", fmt.Errorf("error reading calendar: %w", err)
+		}
+		return string(data), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.netTimeout(DefaultAgendaTimeout))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error fetching calendar: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error fetching calendar (%s)", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error fetching calendar: %w", err)
+	}
+	return string(data), nil
+}
+
+// addAgendaHeadings appends a "## 09:30 Summary" heading (just the summary
+// for all-day events) for each item the note doesn't have yet, and returns
+// the new content and how many were added
+func addAgendaHeadings(content string, items []agendaItem) (string, int) {
+	lines := splitLines(content)
+	var b strings.Builder
+	added := 0
+	for _, item := range items {
+		heading := "## " + item.Summary
+		if !item.AllDay {
+			heading = "## " + item.Start.Format("15:04") + " " + item.Summary
+		}
+		if findHeading(lines, heading) != -1 {
+			continue
+		}
+		lines = append(lines, heading)
+		fmt.Fprintf(&b, "%s\n\n", heading)
+		added++
+	}
+	if added == 0 {
+		return content, 0
+	}
+	content = ensureTrailingNewline(content)
+	if content != "" && !strings.HasSuffix(content, "\n\n") {
+		content += "\n"
+	}
+	return content + b.String(), added
+}
+
+// agendaFor returns the events taking place on day, all-day events first
+// and then by start time, with times in day's time zone
+func agendaFor(events []icsEvent, day time.Time) []agendaItem {
+	// Occurrences moved or cancelled by an override are left out of the
+	// recurring event's own occurrences
+	overridden := make(map[string]bool)
+	for _, event := range events {
+		if !event.RecurrenceID.IsZero() {
+			overridden[event.UID+"@"+event.RecurrenceID.UTC().Format(time.RFC3339)] = true
+		}
+	}
+
+	var items []agendaItem
+	for _, event := range events {
+		if event.Cancelled {
+			continue
+		}
+		for _, start := range occurrencesOn(event, day) {
+			if event.RecurrenceID.IsZero() && overridden[event.UID+"@"+start.UTC().Format(time.RFC3339)] {
+				continue
+			}
+			item := agendaItem{Start: start.In(day.Location()), AllDay: event.AllDay, Summary: event.Summary}
+			if event.AllDay {
+				item.Start = start
+			}
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].AllDay != items[j].AllDay {
+			return items[i].AllDay
+		}
+		return items[i].Start.Before(items[j].Start)
+	})
+	return items
+}
+
+// sameDay reports whether t, read in day's time zone (or as a floating
+// date for all-day events), falls on day
+func sameDay(t time.Time, allDay bool, day time.Time) bool {
+	if !allDay {
+		t = t.In(day.Location())
+	}
+	return t.Year() == day.Year() && t.Month() == day.Month() && t.Day() == day.Day()
+}
+
+// occurrencesOn returns the starts of event that fall on day, expanding its
+// RRULE: DAILY (optionally on BYDAY weekdays), WEEKLY (with BYDAY), MONTHLY (on the start's day of the
+// month, or BYDAY such as 2TU or -1FR) and YEARLY, with INTERVAL, COUNT,
+// UNTIL and EXDATE
+func occurrencesOn(event icsEvent, day time.Time) []time.Time {
+	if event.RRule == nil {
+		if sameDay(event.Start, event.AllDay, day) {
+			return []time.Time{event.Start}
+		}
+		return nil
+	}
+
+	rule := event.RRule
+	interval, _ := strconv.Atoi(rule["INTERVAL"])
+	if interval < 1 {
+		interval = 1
+	}
+	count, _ := strconv.Atoi(rule["COUNT"])
+	var until time.Time
+	if rule["UNTIL"] != "" {
+		until, _, _ = parseICSTime(rule["UNTIL"], nil, event.Start.Location())
+	}
+	// Nothing after the end of day can fall on it
+	end := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+	if event.AllDay {
+		end = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, event.Start.Location())
+	}
+
+	var matches []time.Time
+	seen := 0
+	for period := 0; period < MaxRecurrencePeriods; period++ {
+		candidates := periodOccurrences(event.Start, rule, period*interval)
+		if candidates == nil {
+			break
+		}
+		for _, start := range candidates {
+			if start.Before(event.Start) {
+				continue
+			}
+			if !start.Before(end) || (!until.IsZero() && start.After(until)) {
+				return matches
+			}
+			if excluded(event.ExDates, start) {
+				continue
+			}
+			seen++
+			if count > 0 && seen > count {
+				return matches
+			}
+			if sameDay(start, event.AllDay, day) {
+				matches = append(matches, start)
+			}
+		}
+	}
+	return matches
+}
+
+// periodOccurrences returns the candidate starts in the offset'th day, week,
+// month or year after start, in order, or nil for an unsupported rule. A
+// period can have no candidates, e.g. a month without a 31st.
+func periodOccurrences(start time.Time, rule map[string]string, offset int) []time.Time {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	}
+	byDay := strings.Split(rule["BYDAY"], ",")
+	if rule["BYDAY"] == "" {
+		byDay = nil
+	}
+
+	switch rule["FREQ"] {
+	case "DAILY":
+		candidate := start.AddDate(0, 0, offset)
+		if byDay != nil && !onWeekday(candidate, byDay) {
+			return []time.Time{}
+		}
+		return []time.Time{candidate}
+	case "WEEKLY":
+		if byDay == nil {
+			return []time.Time{start.AddDate(0, 0, 7*offset)}
+		}
+		// Weeks start on Monday unless WKST says otherwise
+		weekStart := time.Monday
+		if day, ok := icsWeekday(rule["WKST"]); ok {
+			weekStart = day
+		}
+		back := (int(start.Weekday()) - int(weekStart) + 7) % 7
+		first := at(start.Year(), start.Month(), start.Day()-back+7*offset)
+		var starts []time.Time
+		for i := 0; i < 7; i++ {
+			if candidate := first.AddDate(0, 0, i); onWeekday(candidate, byDay) {
+				starts = append(starts, candidate)
+			}
+		}
+		return starts
+	case "MONTHLY":
+		month := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, start.Location())
+		if byDay == nil {
+			candidate := at(month.Year(), month.Month(), start.Day())
+			if candidate.Month() != month.Month() {
+				return []time.Time{}
+			}
+			return []time.Time{candidate}
+		}
+		var starts []time.Time
+		for _, spec := range byDay {
+			if day, ok := nthWeekday(month, spec); ok {
+				starts = append(starts, at(month.Year(), month.Month(), day))
+			}
+		}
+		sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+		return starts
+	case "YEARLY":
+		candidate := at(start.Year()+offset, start.Month(), start.Day())
+		if candidate.Month() != start.Month() {
+			return []time.Time{}
+		}
+		return []time.Time{candidate}
+	}
+	return nil
+}
+
+// nthWeekday returns the day of month's month a BYDAY entry such as 2TU
+// (second Tuesday) or -1FR (last Friday) falls on; a bare TU means the
+// first
+func nthWeekday(month time.Time, spec string) (int, bool) {
+	digits := strings.TrimRight(spec, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	weekday, ok := icsWeekday(spec[len(digits):])
+	n, err := strconv.Atoi(digits)
+	if !ok || (digits != "" && (err != nil || n == 0)) {
+		return 0, false
+	}
+	if digits == "" {
+		n = 1
+	}
+	daysIn := time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if n > 0 {
+		first := 1 + (int(weekday)-int(month.Weekday())+7)%7
+		day := first + 7*(n-1)
+		return day, day <= daysIn
+	}
+	lastWeekday := time.Date(month.Year(), month.Month(), daysIn, 0, 0, 0, 0, time.UTC).Weekday()
+	last := daysIn - (int(lastWeekday)-int(weekday)+7)%7
+	day := last + 7*(n+1)
+	return day, day >= 1
+}
+
+// onWeekday reports whether t falls on one of the BYDAY weekdays
+func onWeekday(t time.Time, byDay []string) bool {
+	for _, spec := range byDay {
+		if day, ok := icsWeekday(spec); ok && day == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// icsWeekday reads a two-letter iCalendar weekday such as MO
+func icsWeekday(code string) (time.Weekday, bool) {
+	for i, name := range []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"} {
+		if code == name {
+			return time.Weekday(i), true
+		}
+	}
+	return 0, false
+}
+
+// excluded reports whether start is one of an event's EXDATEs
+func excluded(exDates []time.Time, start time.Time) bool {
+	for _, exDate := range exDates {
+		if exDate.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseICS reads the events in an iCalendar file, unfolding continued lines
+func parseICS(data string) ([]icsEvent, error) {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var events []icsEvent
+	var event *icsEvent
+	calendar := false
+	for _, line := range lines {
+		nameAndParams, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(nameAndParams, ";")
+		name := strings.ToUpper(parts[0])
+		params := make(map[string]string)
+		for _, param := range parts[1:] {
+			if key, val, ok := strings.Cut(param, "="); ok {
+				params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+			}
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VCALENDAR":
+			calendar = true
+		case name == "BEGIN" && value == "VEVENT":
+			event = &icsEvent{}
+		case name == "END" && value == "VEVENT" && event != nil:
+			if !event.Start.IsZero() {
+				events = append(events, *event)
+			}
+			event = nil
+		case event == nil:
+			// Properties of the calendar or its time zones
+		case name == "UID":
+			event.UID = value
+		case name == "SUMMARY":
+			event.Summary = unescapeICS(value)
+		case name == "STATUS":
+			event.Cancelled = strings.EqualFold(value, "CANCELLED")
+		case name == "DTSTART":
+			event.Start, event.AllDay, _ = parseICSTime(value, params, time.Local)
+		case name == "RECURRENCE-ID":
+			event.RecurrenceID, _, _ = parseICSTime(value, params, time.Local)
+		case name == "EXDATE":
+			for _, exDate := range strings.Split(value, ",") {
+				if t, _, err := parseICSTime(exDate, params, time.Local); err == nil {
+					event.ExDates = append(event.ExDates, t)
+				}
+			}
+		case name == "RRULE":
+			event.RRule = make(map[string]string)
+			for _, part := range strings.Split(value, ";") {
+				if key, val, ok := strings.Cut(part, "="); ok {
+					event.RRule[strings.ToUpper(key)] = strings.ToUpper(val)
+				}
+			}
+		}
+	}
+	if !calendar {
+		return nil, fmt.Errorf("not an iCalendar file (no BEGIN:VCALENDAR)")
+	}
+	for i := range events {
+		if events[i].Summary == "" {
+			events[i].Summary = "(no title)"
+		}
+	}
+	return events, nil
+}
+
+// parseICSTime reads an iCalendar DATE or DATE-TIME: UTC with a trailing
+// Z, in the TZID parameter's zone, or floating in fallback. Dates are all
+// day and come back at midnight in fallback.
+func parseICSTime(value string, params map[string]string, fallback *time.Location) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, fallback)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	location := fallback
+	if tzid := params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			location = zone
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, location)
+	return t, false, err
+}
+
+// unescapeICS undoes iCalendar TEXT escaping; newlines become spaces since
+// summaries end up in headings
+func unescapeICS(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
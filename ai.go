@@ -0,0 +1,240 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxAskNotes is how many of the best matching notes --ask sends
+const MaxAskNotes = 5
+
+// MaxNoteChars caps how much of each note is sent to the model
+const MaxNoteChars = 12000
+
+// DefaultLLMTimeout bounds a request to llm_url unless timeout is set
+const DefaultLLMTimeout = 2 * time.Minute
+
+// askStopWords are ignored when picking notes relevant to a question
+var askStopWords = map[string]bool{
+	"what": true, "when": true, "where": true, "which": true, "who": true, "why": true, "how": true,
+	"did": true, "does": true, "the": true, "and": true, "for": true, "with": true, "about": true,
+	"that": true, "this": true, "have": true, "from": true, "are": true, "was": true, "were": true,
+}
+
+// llmSource is a note sent to the model, cited by its path
+type llmSource struct {
+	Path    string
+	Content string
+}
+
+// runSummarize prints a model-written summary of one note
+func runSummarize(config Config, noteName string) error {
+	notePath, err := existingNotePath(config, noteName)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	sources := []llmSource{{Path: filepath.Base(notePath), Content: string(content)}}
+	prompt := "Summarize the following note in a few short bullet points. Keep decisions, dates and action items.\n\n" + formatSources(sources)
+	answer, err := queryLLM(ctx, config, prompt)
+	if err != nil {
+		return err
+	}
+	fmt.Println(answer)
+	return nil
+}
+
+// runAsk answers a question from the notes that best match it, citing them
+func runAsk(config Config, flags *ParsedFlags, question string) error {
+	if strings.TrimSpace(question) == "" {
+		return usageErrorf("usage: note --ask \"<question>\"")
+	}
+	ctx, stop := interruptible()
+	defer stop()
+	sources, err := relevantNotes(ctx, config, question, flags.Archive)
+	if err != nil {
+		return fmt.Errorf("search %w", errInterrupted)
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("no notes match the question")
+	}
+
+	prompt := "Answer the question using only the notes below. Cite the notes you used by " +
+		"their file name in square brackets, e.g. [meeting-20260101.md]. If the notes don't " +
+		"contain the answer, say so.\n\nQuestion: " + question + "\n\n" + formatSources(sources)
+	answer, err := queryLLM(ctx, config, prompt)
+	if err != nil {
+		return err
+	}
+	fmt.Println(answer)
+	fmt.Println("\nSources:")
+	for _, source := range sources {
+		fmt.Printf("  %s\n", source.Path)
+	}
+	return nil
+}
+
+// relevantNotes ranks notes by how many of the question's keywords they
+// contain and returns the best MaxAskNotes
+func relevantNotes(ctx context.Context, config Config, question string, includeArchived bool) ([]llmSource, error) {
+	var keywords []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	}) {
+		if len(word) > 2 && !askStopWords[word] {
+			keywords = append(keywords, word)
+		}
+	}
+
+	scores := make(map[string]int)
+	for _, keyword := range keywords {
+		results, err := searchDirs(ctx, config, searchRoots(config, includeArchived), []string{keyword})
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			scores[result.Path] += len(result.Matches)
+		}
+	}
+	paths := make([]string, 0, len(scores))
+	for path := range scores {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if scores[paths[i]] != scores[paths[j]] {
+			return scores[paths[i]] > scores[paths[j]]
+		}
+		return paths[i] < paths[j]
+	})
+	if len(paths) > MaxAskNotes {
+		paths = paths[:MaxAskNotes]
+	}
+
+	var sources []llmSource
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Join(config.NotesDir, path))
+		if err == nil {
+			sources = append(sources, llmSource{Path: path, Content: string(content)})
+		}
+	}
+	return sources, nil
+}
+
+// formatSources lays out notes for a prompt, truncating long ones
+func formatSources(sources []llmSource) string {
+	var b strings.Builder
+	for _, source := range sources {
+		content := source.Content
+		if len(content) > MaxNoteChars {
+			content = content[:MaxNoteChars] + "\n[truncated]"
+		}
+		fmt.Fprintf(&b, "=== %s ===\n%s\n\n", source.Path, strings.TrimSpace(content))
+	}
+	return b.String()
+}
+
+// queryLLM sends prompt to the configured model. Nothing is sent anywhere
+// unless one is configured: either llm=<command>, which gets the prompt on
+// stdin (e.g. "ollama run llama3" or "llm"), or llm_url with llm_model and
+// optionally llm_key for an OpenAI-compatible chat completions endpoint.
+// Cancelling ctx stops the command or request.
+func queryLLM(ctx context.Context, config Config, prompt string) (string, error) {
+	if command := strings.Fields(config.option("llm")); len(command) > 0 {
+		var stdout bytes.Buffer
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(prompt)
+		cmd.Stdout = &stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("%s failed: %w", command[0], err)
+		}
+		return strings.TrimSpace(stdout.String()), nil
+	}
+
+	url := config.option("llm_url")
+	if url == "" {
+		return "", fmt.Errorf("no model configured; set llm=<command> or llm_url and llm_model in ~/.note")
+	}
+	ctx, cancel := context.WithTimeout(ctx, config.netTimeout(DefaultLLMTimeout))
+	defer cancel()
+	return queryChatEndpoint(ctx, url, config.option("llm_model"), config.option("llm_key"), prompt)
+}
+
+func queryChatEndpoint(ctx context.Context, url, model, key, prompt string) (string, error) {
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	body, err := json.Marshal(struct {
+		Model    string    `json:"model"`
+		Messages []message `json:"messages"`
+	}{Model: model, Messages: []message{{Role: "user", Content: prompt}}})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("invalid llm_url: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error contacting %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		Choices []struct {
+			Message message `json:"message"`
+		} `json:"choices"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return "", fmt.Errorf("unexpected response from %s (%s)", url, resp.Status)
+	}
+	if reply.Error != nil {
+		return "", fmt.Errorf("model error: %s", reply.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK || len(reply.Choices) == 0 {
+		return "", fmt.Errorf("unexpected response from %s (%s)", url, resp.Status)
+	}
+	return strings.TrimSpace(reply.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,292 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// appendOptions controls where appended text lands inside a note
+type appendOptions struct {
+	Prepend bool   // insert at the top (or top of the heading's section)
+	Under   string // heading to insert under, e.g. "## Inbox"
+}
+
+// runAppend handles `note --append <name> [text...]`, reading the text from
+// stdin when none is given on the command line
+func runAppend(config Config, flags *ParsedFlags, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("--append requires a note name")
+	}
+	noteName := args[0]
+	text := strings.Join(args[1:], " ")
+
+	if text == "" && !isInputFromTerminal() {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("error reading stdin: %w", err)
+		}
+		text = string(data)
+	}
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("nothing to append")
+	}
+
+	notePath := resolveNotePath(config, noteName)
+	if flags.Dated || inDatedSections(config, notePath) {
+		// A log with dated sections is one undated file, not one per day
+		notePath = captureNotePath(config, noteName)
+	}
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	text = unfurlText(config, text)
+	if flags.Stamp {
+		text = stampedLine(strings.TrimSpace(text), time.Now())
+	}
+
+	opts := appendOptions{Prepend: flags.Prepend, Under: appendHeading(config, flags, notePath, time.Now())}
+	logged, err := appendOrLog(config, notePath, text, opts)
+	if err != nil {
+		return err
+	}
+	if !logged {
+		postSave(config, notePath)
+	}
+	fmt.Printf("Appended to %s\n", filepath.Base(notePath))
+	return nil
+}
+
+// appendHeading returns the heading appended text goes under: the --under
+// heading, or today's "## YYYY-MM-DD" with --dated or for notes listed in
+// dated_sections, so a running log keeps one section per day in one file
+func appendHeading(config Config, flags *ParsedFlags, notePath string, now time.Time) string {
+	if flags.Under != "" {
+		return flags.Under
+	}
+	if flags.Dated || inDatedSections(config, notePath) {
+		return "## " + now.Format("2006-01-02")
+	}
+	return ""
+}
+
+// inDatedSections reports whether the note's name matches one of the
+// comma-separated globs in dated_sections, e.g. "worklog, log-*"; a dated
+// note's name is matched without its date stamp
+func inDatedSections(config Config, notePath string) bool {
+	name := strings.ToLower(dateStamp.ReplaceAllString(filepath.Base(notePath), ".md"))
+	name = strings.TrimSuffix(name, ".md")
+	for _, pattern := range strings.Split(config.option("dated_sections"), ",") {
+		pattern = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(pattern), ".md"))
+		if matched, _ := filepath.Match(pattern, name); pattern != "" && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// appendToNote inserts text into the note at notePath, creating the note if
+// it doesn't exist yet. The notes lock is held from read to write, so
+// concurrent appends can't drop each other's text. Encrypted notes are
+// refused.
+func appendToNote(config Config, notePath, text string, opts appendOptions) error {
+	if isEncryptedNote(notePath) {
+		return encryptedNoteError(notePath)
+	}
+	return withNotesLock(config.NotesDir, func() error {
+		content, err := os.ReadFile(notePath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+		}
+
+		updated := insertText(string(content), text, opts)
+		if err := writeFileAtomic(notePath, []byte(updated), notePerm(notePath)); err != nil {
+			return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+		}
+		return nil
+	})
+}
+
+// insertText returns content with text inserted according to opts
+func insertText(content, text string, opts appendOptions) string {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	lines := splitLines(content)
+
+	if opts.Under == "" {
+		if opts.Prepend {
+			at := frontmatterEnd(lines)
+			return joinLines(insertLines(lines, at, splitLines(text)))
+		}
+		return ensureTrailingNewline(content) + text
+	}
+
+	headingLine := findHeading(lines, opts.Under)
+	if headingLine == -1 {
+		// Heading missing: create it at the end of the note
+		var b strings.Builder
+		b.WriteString(ensureTrailingNewline(content))
+		if content != "" && !strings.HasSuffix(content, "\n\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString(strings.TrimSpace(opts.Under) + "\n")
+		b.WriteString(text)
+		return b.String()
+	}
+
+	at := headingLine + 1
+	if !opts.Prepend {
+		at = sectionEnd(lines, headingLine)
+	}
+	return joinLines(insertLines(lines, at, splitLines(text)))
+}
+
+// findHeading returns the index of the line matching heading, or -1
+func findHeading(lines []string, heading string) int {
+	want := strings.TrimSpace(heading)
+	for i, line := range lines {
+		if strings.TrimSpace(line) == want {
+			return i
+		}
+	}
+	return -1
+}
+
+// sectionEnd returns the line index where new text should be appended to
+// the section starting at headingLine: before the next heading of the same
+// or higher level, and before any blank lines separating the sections
+func sectionEnd(lines []string, headingLine int) int {
+	level := headingLevel(lines[headingLine])
+	end := len(lines)
+	for i := headingLine + 1; i < len(lines); i++ {
+		if l := headingLevel(lines[i]); l > 0 && (level == 0 || l <= level) {
+			end = i
+			break
+		}
+	}
+	for end > headingLine+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return end
+}
+
+// insertLines returns lines with extra inserted at index at
+func insertLines(lines []string, at int, extra []string) []string {
+	result := make([]string, 0, len(lines)+len(extra))
+	result = append(result, lines[:at]...)
+	result = append(result, extra...)
+	return append(result, lines[at:]...)
+}
+
+// ensureTrailingNewline terminates non-empty content with a newline
+func ensureTrailingNewline(content string) string {
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		return content + "\n"
+	}
+	return content
+}
+
+// DefaultCaptureFormat is the time layout quick captures are stamped with
+// unless capture_format is set, e.g. "- [2025-06-01 09:30] call Bob"
+const DefaultCaptureFormat = "2006-01-02 15:04"
+
+// runCapture handles `note -c [note] [text...]`, appending the text (read
+// from stdin when none is given) as timestamped list items without opening
+// the editor. With no note the capture_note setting, or the inbox, is used.
+func runCapture(config Config, flags *ParsedFlags, args []string) error {
+	var notePath string
+	if len(args) > 0 {
+		notePath = captureNotePath(config, args[0])
+		args = args[1:]
+	} else if name := config.option("capture_note"); name != "" {
+		notePath = captureNotePath(config, name)
+	} else {
+		notePath = inboxPath(config)
+	}
+
+	text := strings.Join(args, " ")
+	if text == "" && !isInputFromTerminal() {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("error reading stdin: %w", err)
+		}
+		text = string(data)
+	}
+	if strings.TrimSpace(text) == "" {
+		return usageErrorf("nothing to capture; give the text after the note name or pipe it in")
+	}
+
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	layout := config.option("capture_format")
+	if layout == "" {
+		layout = DefaultCaptureFormat
+	}
+	now := time.Now()
+	text = capturedLines(unfurlText(config, text), now, layout)
+	opts := appendOptions{Prepend: flags.Prepend, Under: appendHeading(config, flags, notePath, now)}
+	logged, err := appendOrLog(config, notePath, text, opts)
+	if err != nil {
+		return err
+	}
+	if !logged {
+		postSave(config, notePath)
+	}
+	fmt.Printf("Captured to %s\n", filepath.Base(notePath))
+	return nil
+}
+
+// captureNotePath returns the note a capture to name goes to: the note it
+// resolves to if that exists, otherwise a new undated name.md, since a
+// capture target collects lines across many days
+func captureNotePath(config Config, name string) string {
+	if notePath := resolveNotePath(config, name); pathExists(notePath) {
+		return notePath
+	}
+	if !strings.HasSuffix(name, ".md") {
+		name += ".md"
+	}
+	return filepath.Join(config.NotesDir, name)
+}
+
+// capturedLines turns each non-blank line of text into a list item stamped
+// with at, e.g. "- [2025-06-01 09:30] call Bob"
+func capturedLines(text string, at time.Time, layout string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "- [%s] %s\n", at.Format(layout), strings.TrimPrefix(line, "- "))
+	}
+	return b.String()
+}
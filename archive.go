@@ -0,0 +1,155 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"note/pkg/notes"
+)
+
+// runArchive browses the archive on its own, unlike -a which always merges
+// archived notes with active ones:
+//
+//	note --archive ls [pattern]
+//	note --archive search <term>
+func runArchive(config Config, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: note --archive ls [pattern] | note --archive search <term>")
+	}
+	rest := strings.Join(args[1:], " ")
+
+	switch args[0] {
+	case "ls", "list":
+		for _, note := range archivedNotes(config, rest) {
+			if rest != "" {
+				fmt.Println(highlightTerm(note, rest))
+			} else {
+				fmt.Println(note)
+			}
+		}
+		return nil
+	case "search":
+		if rest == "" {
+			return usageErrorf("--archive search requires a search term")
+		}
+		fmt.Printf("Searching the archive for '%s'...\n\n", rest)
+		ctx, stop := interruptible()
+		defer stop()
+		terms := []string{rest}
+		results, err := searchDirs(ctx, config, []string{getArchiveDir(config.NotesDir)}, terms)
+		if err != nil {
+			return fmt.Errorf("search %w", errInterrupted)
+		}
+		printSearchResults(results, terms)
+		return nil
+	}
+	return fmt.Errorf("unknown archive command '%s' (use ls or search)", args[0])
+}
+
+// archivedNotes returns the sorted archived notes matching pattern,
+// prefixed by the archive directory name as in -a listings
+func archivedNotes(config Config, pattern string) []string {
+	archiveDir := getArchiveDir(config.NotesDir)
+	notes := findMatchingNotes(archiveDir, pattern, false)
+	for i, note := range notes {
+		notes[i] = filepath.Base(archiveDir) + "/" + note
+	}
+	sort.Strings(notes)
+	return notes
+}
+
+// runRestore moves the archived notes matching pattern back to the notes
+// directory. A note whose name has been taken since is skipped, or with
+// --keep-both restored under the next free name, e.g. plan-20240101-2.md.
+//
+//	note --restore <pattern> [--keep-both]
+func runRestore(config Config, args []string) error {
+	keepBoth := false
+	var rest []string
+	for _, arg := range args {
+		if arg == "--keep-both" {
+			keepBoth = true
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	pattern := strings.Join(rest, " ")
+	if pattern == "" {
+		return usageErrorf("usage: note --restore <pattern> [--keep-both]")
+	}
+
+	store := noteStore(config)
+	archiveDir := store.ArchiveDir()
+	selected := store.List(notes.Query{Pattern: pattern, Dirs: []string{archiveDir}, Encrypted: true})
+	if len(selected) == 0 {
+		fmt.Printf("No archived notes found matching '%s'\n", pattern)
+		return nil
+	}
+
+	// Decide every target first, so the journal records the whole restore
+	var steps []journalStep
+	var skipped []string
+	for _, note := range selected {
+		name := filepath.Base(note.Path)
+		target := filepath.Join(config.NotesDir, name)
+		if pathExists(target) {
+			if !keepBoth {
+				skipped = append(skipped, name)
+				continue
+			}
+			target = filepath.Join(config.NotesDir, freeNoteName(config.NotesDir, name))
+		}
+		steps = append(steps, journalStep{From: note.Path, To: target})
+	}
+	for _, name := range skipped {
+		fmt.Fprintf(os.Stderr, "Skipping %s: a note with that name already exists\n", name)
+	}
+	if len(steps) > 0 {
+		if err := beginJournal(config.NotesDir, "restore", steps); err != nil {
+			return err
+		}
+		fmt.Println("Restoring:")
+		failed := false
+		for _, step := range steps {
+			name, newName := filepath.Base(step.From), filepath.Base(step.To)
+			if name == newName {
+				fmt.Printf("  %s\n", name)
+			} else {
+				fmt.Printf("  %s as %s\n", name, newName)
+			}
+			if err := store.Restore(name, newName); err != nil {
+				fmt.Fprintf(os.Stderr, "Error restoring %s: %v\n", name, err)
+				failed = true
+			}
+		}
+		if failed {
+			return fmt.Errorf("some notes could not be restored; run 'note --repair' to retry or undo the restore")
+		}
+		finishJournal(config.NotesDir)
+		autoCommit(config, fmt.Sprintf("Restore %d note(s)", len(steps)))
+	}
+	if len(skipped) > 0 {
+		return fmt.Errorf("%d note(s) not restored because the name is taken; use --keep-both to restore them under a new name", len(skipped))
+	}
+	return nil
+}
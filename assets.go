@@ -0,0 +1,206 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Images and links like "![alt](attachments/a.png "Title")", with the
+	// destination captured
+	assetLink = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^()\s<>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	// HTML images like <img src="attachments/a.png" width="300">
+	htmlImage = regexp.MustCompile(`<img\s[^>]*src=["']([^"']+)["']`)
+)
+
+// runOpenAsset lists the attachments a note references and opens one with
+// the system opener:
+//
+//	note --open-asset <name> [number]
+//
+// A note with a single existing attachment opens it straight away; with
+// several, the list is shown and the choice read from the terminal.
+func runOpenAsset(config Config, args []string) error {
+	choice := 0
+	if len(args) > 1 {
+		if n, err := strconv.Atoi(args[len(args)-1]); err == nil {
+			choice, args = n, args[:len(args)-1]
+		}
+	}
+	if len(args) == 0 {
+		return usageErrorf("usage: note --open-asset <name> [number]")
+	}
+	notePath, err := existingNotePath(config, strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+
+	assets := noteAssets(string(content))
+	if len(assets) == 0 {
+		fmt.Printf("%s references no attachments\n", filepath.Base(notePath))
+		return nil
+	}
+	paths := make([]string, len(assets))
+	var found []int
+	for i, asset := range assets {
+		paths[i] = resolveAsset(config.NotesDir, notePath, asset)
+		if paths[i] != "" {
+			found = append(found, i+1)
+		}
+	}
+
+	if choice == 0 && len(found) == 1 && len(assets) == 1 {
+		choice = found[0]
+	}
+	if choice == 0 {
+		for i, asset := range assets {
+			status := ""
+			if paths[i] == "" {
+				status = " (missing)"
+			}
+			fmt.Printf("%3d  %s%s\n", i+1, asset, status)
+		}
+		if !isInputFromTerminal() {
+			return nil
+		}
+		fmt.Print("Open which (number, Enter to cancel): ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.TrimSpace(answer); answer == "" {
+			return nil
+		}
+		if choice, err = strconv.Atoi(answer); err != nil {
+			return fmt.Errorf("'%s' is not a number", answer)
+		}
+	}
+
+	if choice < 1 || choice > len(assets) {
+		return fmt.Errorf("no attachment %d (the note has %d)", choice, len(assets))
+	}
+	if paths[choice-1] == "" {
+		return fmt.Errorf("%s does not exist", assets[choice-1])
+	}
+	return openWithSystem(config, paths[choice-1])
+}
+
+// noteAssets returns the local files a note links to or embeds, in the
+// order they first appear. Web links, anchors and other notes are left out,
+// as is anything inside code.
+func noteAssets(content string) []string {
+	lines := splitLines(content)
+	inCode := codeLines(lines)
+	seen := make(map[string]bool)
+	var assets []string
+	add := func(destination string) {
+		if decoded, err := url.PathUnescape(destination); err == nil {
+			destination = decoded
+		}
+		if !isLocalAsset(destination) || seen[destination] {
+			return
+		}
+		seen[destination] = true
+		assets = append(assets, destination)
+	}
+
+	for i, line := range lines {
+		if inCode[i] {
+			continue
+		}
+		if match := linkDefinition.FindStringSubmatch(line); match != nil {
+			if fields := strings.Fields(match[2]); len(fields) > 0 {
+				add(strings.Trim(fields[0], "<>"))
+			}
+			continue
+		}
+		outsideInlineCode(line, func(text string) string {
+			for _, match := range assetLink.FindAllStringSubmatch(text, -1) {
+				add(match[1])
+			}
+			for _, match := range htmlImage.FindAllStringSubmatch(text, -1) {
+				add(match[1])
+			}
+			return text
+		})
+	}
+	return assets
+}
+
+// isLocalAsset reports whether a link destination is a file next to the
+// notes rather than a URL, an anchor or another note
+func isLocalAsset(destination string) bool {
+	if destination == "" || strings.HasPrefix(destination, "#") || strings.Contains(destination, ":") {
+		return false
+	}
+	file, _, _ := strings.Cut(destination, "#")
+	return !strings.EqualFold(filepath.Ext(file), ".md")
+}
+
+// resolveAsset finds an asset relative to the note, as markdown viewers do,
+// falling back to the notes directory for links written from its root. It
+// returns "" when neither exists.
+func resolveAsset(notesDir, notePath, asset string) string {
+	file, _, _ := strings.Cut(filepath.FromSlash(asset), "#")
+	if filepath.IsAbs(file) {
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+		return ""
+	}
+	for _, dir := range []string{filepath.Dir(notePath), notesDir} {
+		path := filepath.Join(dir, file)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// openWithSystem opens a file in its default application, or with the
+// opener setting when it is set
+func openWithSystem(config Config, path string) error {
+	command := strings.Fields(config.option("opener"))
+	if len(command) == 0 {
+		switch runtime.GOOS {
+		case "darwin":
+			command = []string{"open"}
+		case "windows":
+			command = []string{"cmd", "/c", "start", ""}
+		default:
+			command = []string{"xdg-open"}
+		}
+	}
+	cmd := exec.Command(command[0], append(command[1:], path)...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error opening %s with %s: %w", filepath.Base(path), command[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MinAutolinkTitle is the shortest title --autolink looks for; shorter ones
+// match too much ordinary text
+const MinAutolinkTitle = 3
+
+// autolinkProtected matches the parts of a line that are never linked:
+// inline code, existing wiki and markdown links, and URLs
+var autolinkProtected = regexp.MustCompile("`[^`]*`|\\[\\[[^\\]]*\\]\\]|\\[[^\\]]*\\]\\([^)]*\\)|https?://\\S+")
+
+// linkProposal is a mention of another note's title that could become a
+// [[wiki-link]]: bytes Start to End of line Line
+type linkProposal struct {
+	Line, Start, End int
+	Title            string
+}
+
+// runAutolink turns mentions of other notes' titles into [[wiki-links]] in
+// the note named, or every note matching a pattern. Each link is confirmed
+// first unless --yes is given.
+//
+//	note --autolink <name|pattern> [--yes]
+func runAutolink(config Config, flags *ParsedFlags, args []string) error {
+	confirm := true
+	var nameArgs []string
+	for _, arg := range args {
+		if arg == "--yes" {
+			confirm = false
+		} else {
+			nameArgs = append(nameArgs, arg)
+		}
+	}
+	if len(nameArgs) == 0 {
+		return usageErrorf("usage: note --autolink <name|pattern> [--yes]")
+	}
+	paths := selectNotes(config, strings.Join(nameArgs, " "))
+	if len(paths) == 0 {
+		return fmt.Errorf("no notes match '%s'", strings.Join(nameArgs, " "))
+	}
+	return autolinkNotes(config, flags, paths, confirm, os.Stdin, os.Stdout)
+}
+
+// autolinkNotes links title mentions in each note, asking on out and
+// reading answers from in when confirm is set: y links the mention, n
+// leaves it, a links it and every mention after it, and q stops. A title is
+// linked at its first mention only, and never in the note it titles.
+func autolinkNotes(config Config, flags *ParsedFlags, paths []string, confirm bool, in io.Reader, out io.Writer) error {
+	titles := loadTitleIndex(config.NotesDir)
+	reader := bufio.NewReader(in)
+	for _, notePath := range paths {
+		name := noteRelPath(config, notePath)
+		content, err := os.ReadFile(notePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", name, err)
+		}
+		lines := splitLines(string(content))
+		proposals := findAutolinks(lines, autolinkTitles(titles, filepath.Base(notePath), string(content)))
+
+		var accepted []linkProposal
+		quit := false
+		for _, proposal := range proposals {
+			if confirm {
+				preview := applyAutolinks(lines[proposal.Line:proposal.Line+1], []linkProposal{{Start: proposal.Start, End: proposal.End, Title: proposal.Title}})
+				fmt.Fprintf(out, "\n%s:%d\n  %s\n", name, proposal.Line+1, strings.TrimSpace(preview[0]))
+				fmt.Fprint(out, "Link? (y = yes, n = no, a = this and all the rest, q = quit): ")
+				answer, err := reader.ReadString('\n')
+				answer = strings.ToLower(strings.TrimSpace(answer))
+				if answer == "q" || (answer == "" && err != nil) {
+					quit = true
+					break
+				}
+				if answer == "a" {
+					confirm = false
+				} else if answer != "y" {
+					continue
+				}
+			}
+			accepted = append(accepted, proposal)
+		}
+
+		if len(accepted) > 0 {
+			if err := writeAutolinks(config, flags, notePath, lines, accepted); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Linked %d mention(s) in %s\n", len(accepted), name)
+		}
+		if quit {
+			break
+		}
+	}
+	return nil
+}
+
+// autolinkTitles returns the titles worth linking from a note: those of
+// other notes that the note does not link to yet, longest first
+func autolinkTitles(titles map[string]string, note, content string) []string {
+	linked := make(map[string]bool)
+	for _, link := range extractMetadata(content).Links {
+		linked[strings.ToLower(link)] = true
+	}
+	seen := make(map[string]bool)
+	var result []string
+	for other, title := range titles {
+		key := strings.ToLower(title)
+		if other == note || strings.EqualFold(title, titles[note]) || seen[key] || utf8.RuneCountInString(title) < MinAutolinkTitle {
+			continue
+		}
+		if linked[key] || linked[strings.ToLower(strings.TrimSuffix(other, ".md"))] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, title)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i]) != len(result[j]) {
+			return len(result[i]) > len(result[j])
+		}
+		return result[i] < result[j]
+	})
+	return result
+}
+
+// findAutolinks finds the first mention of each title as a whole word,
+// ignoring case, outside frontmatter, headings, code and existing links.
+// Longer titles are placed first and win where mentions overlap.
+func findAutolinks(lines []string, titles []string) []linkProposal {
+	start := frontmatterEnd(lines)
+	code := codeLines(lines)
+	var proposals []linkProposal
+	overlaps := func(line, from, to int) bool {
+		for _, p := range proposals {
+			if p.Line == line && from < p.End && to > p.Start {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, title := range titles {
+		pattern := regexp.MustCompile("(?i)" + regexp.QuoteMeta(title))
+	search:
+		for i := start; i < len(lines); i++ {
+			line := lines[i]
+			if code[i] || headingLevel(line) > 0 {
+				continue
+			}
+			protected := autolinkProtected.FindAllStringIndex(line, -1)
+			for _, match := range pattern.FindAllStringIndex(line, -1) {
+				if !wordBoundary(line, match[0], match[1]) || overlaps(i, match[0], match[1]) {
+					continue
+				}
+				inside := false
+				for _, span := range protected {
+					inside = inside || (match[0] < span[1] && match[1] > span[0])
+				}
+				if inside {
+					continue
+				}
+				proposals = append(proposals, linkProposal{Line: i, Start: match[0], End: match[1], Title: title})
+				break search
+			}
+		}
+	}
+
+	sort.Slice(proposals, func(i, j int) bool {
+		if proposals[i].Line != proposals[j].Line {
+			return proposals[i].Line < proposals[j].Line
+		}
+		return proposals[i].Start < proposals[j].Start
+	})
+	return proposals
+}
+
+// wordBoundary reports whether line[start:end] is not part of a longer word
+func wordBoundary(line string, start, end int) bool {
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' }
+	if before, _ := utf8.DecodeLastRuneInString(line[:start]); start > 0 && isWord(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(line[end:]); end < len(line) && isWord(after) {
+		return false
+	}
+	return true
+}
+
+// applyAutolinks returns lines with each proposal turned into a link:
+// [[Title]], or [[Title|text]] when the mention is cased differently
+func applyAutolinks(lines []string, proposals []linkProposal) []string {
+	result := append([]string(nil), lines...)
+	// Work from the end so earlier offsets stay valid
+	for i := len(proposals) - 1; i >= 0; i-- {
+		p := proposals[i]
+		line := result[p.Line]
+		text := line[p.Start:p.End]
+		link := "[[" + p.Title + "]]"
+		if text != p.Title {
+			link = "[[" + p.Title + "|" + text + "]]"
+		}
+		result[p.Line] = line[:p.Start] + link + line[p.End:]
+	}
+	return result
+}
+
+// writeAutolinks saves a note with the accepted links added
+func writeAutolinks(config Config, flags *ParsedFlags, notePath string, lines []string, accepted []linkProposal) error {
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	// The note was read before asking, so it is checked again under the
+	// notes lock rather than overwriting whatever was added meanwhile
+	err = withNotesLock(config.NotesDir, func() error {
+		current, err := os.ReadFile(notePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+		}
+		if joinLines(splitLines(string(current))) != joinLines(lines) {
+			return fmt.Errorf("%s changed while linking; run --autolink again", filepath.Base(notePath))
+		}
+		if err := writeFileAtomic(notePath, []byte(joinLines(applyAutolinks(lines, accepted))), notePerm(notePath)); err != nil {
+			return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	postSave(config, notePath)
+	return nil
+}
@@ -0,0 +1,313 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runBackup writes the whole notes directory to one archive:
+//
+//	note --backup [file] [--zip [--password]] [--encrypt]
+//
+// The default is a gzipped tar named for today. --encrypt pipes it through
+// age, to the backup_recipients keys or else a passphrase, so it can be
+// mailed or kept on untrusted media. --zip --password makes a zip that
+// stock unzip tools can open instead; it needs the zip tool and uses its
+// weaker legacy encryption. Notebooks listed in local_only are left out.
+func runBackup(config Config, args []string) error {
+	var zipped, password, encrypt bool
+	var out string
+	for _, arg := range args {
+		switch arg {
+		case "--zip":
+			zipped = true
+		case "--password":
+			password = true
+		case "--encrypt":
+			encrypt = true
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return fmt.Errorf("unknown backup option '%s'", arg)
+			}
+			out = arg
+		}
+	}
+	if password && !zipped {
+		return fmt.Errorf("--password needs --zip; use --encrypt to protect a tar backup")
+	}
+	if password && encrypt {
+		return fmt.Errorf("use either --password or --encrypt")
+	}
+
+	if out == "" {
+		out = "notes-" + time.Now().Format("20060102") + ".tar.gz"
+		if zipped {
+			out = strings.TrimSuffix(out, ".tar.gz") + ".zip"
+		}
+		if encrypt {
+			out += ".age"
+		}
+	}
+	out, err := filepath.Abs(out)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(out); err == nil {
+		return fmt.Errorf("%s already exists", out)
+	}
+	if rel, err := filepath.Rel(config.NotesDir, out); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("write the backup outside the notes directory")
+	}
+
+	if password {
+		err = passwordZip(config, out)
+	} else {
+		err = writeBackupFile(config, out, zipped, encrypt)
+	}
+	if err != nil {
+		os.Remove(out)
+		return err
+	}
+	fmt.Printf("Backed up %s to %s\n", config.NotesDir, out)
+	if notebooks := localOnlyNotebooks(config); len(notebooks) > 0 {
+		fmt.Printf("Left out local-only notebooks: %s\n", strings.Join(notebooks, ", "))
+	}
+	return nil
+}
+
+// writeBackupFile writes the archive to out, through age when encrypting
+func writeBackupFile(config Config, out string, zipped, encrypt bool) error {
+	file, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", out, err)
+	}
+	defer file.Close()
+
+	if !encrypt {
+		if err := writeBackup(config.NotesDir, localOnlyNotebooks(config), file, zipped); err != nil {
+			return err
+		}
+		return file.Close()
+	}
+
+	cmd, err := ageCommand(config)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = file
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting age: %w", err)
+	}
+	writeErr := writeBackup(config.NotesDir, localOnlyNotebooks(config), stdin, zipped)
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("age failed: %w", err)
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	return file.Close()
+}
+
+// ageCommand encrypts stdin to the backup_recipients public keys (age or
+// ssh keys, or files of them, separated by commas), or with a passphrase
+// age asks for
+func ageCommand(config Config) (*exec.Cmd, error) {
+	if _, err := exec.LookPath("age"); err != nil {
+		return nil, fmt.Errorf("age not found in PATH; install it to encrypt backups")
+	}
+	var args []string
+	for _, recipient := range strings.Split(config.option("backup_recipients"), ",") {
+		recipient = strings.TrimSpace(recipient)
+		if recipient == "" {
+			continue
+		}
+		if path := expandPath(recipient); pathExists(path) {
+			args = append(args, "--recipients-file", path)
+		} else {
+			args = append(args, "--recipient", recipient)
+		}
+	}
+	if len(args) == 0 {
+		return exec.Command("age", "--passphrase"), nil
+	}
+	return exec.Command("age", args...), nil
+}
+
+// backupSkipped leaves out state that is only meaningful while note runs
+func backupSkipped(rel string) bool {
+	return strings.HasSuffix(rel, ".lock") || filepath.Base(rel) == JournalFile
+}
+
+// writeBackup writes every file in the notes directory outside the
+// excluded notebooks to w as a gzipped tar, or as a zip. Symlinked notes
+// are kept as links in a tar; a zip stores what they point to.
+func writeBackup(notesDir string, exclude []string, w io.Writer, zipped bool) error {
+	var add func(rel string, info fs.FileInfo, path string) error
+	var finish func() error
+
+	if zipped {
+		zw := zip.NewWriter(w)
+		add = func(rel string, info fs.FileInfo, path string) error {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			entry, err := zw.CreateHeader(&zip.FileHeader{Name: rel, Method: zip.Deflate, Modified: info.ModTime()})
+			if err != nil {
+				return err
+			}
+			_, err = entry.Write(data)
+			return err
+		}
+		finish = zw.Close
+	} else {
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		add = func(rel string, info fs.FileInfo, path string) error {
+			link := ""
+			if info.Mode()&os.ModeSymlink != 0 {
+				var err error
+				if link, err = os.Readlink(path); err != nil {
+					return err
+				}
+			}
+			header, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			header.Name = rel
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			_, err = io.Copy(tw, file)
+			return err
+		}
+		finish = func() error {
+			if err := tw.Close(); err != nil {
+				return err
+			}
+			return gz.Close()
+		}
+	}
+
+	err := filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(notesDir, path)
+		if d.IsDir() && inNotebook(filepath.ToSlash(rel), exclude) {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || backupSkipped(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if zipped && info.Mode()&os.ModeSymlink != 0 {
+			if info, err = os.Stat(path); err != nil || info.IsDir() {
+				return nil
+			}
+		}
+		return add(filepath.ToSlash(rel), info, path)
+	})
+	if err != nil {
+		return fmt.Errorf("error writing backup: %w", err)
+	}
+	if err := finish(); err != nil {
+		return fmt.Errorf("error writing backup: %w", err)
+	}
+	return nil
+}
+
+// passwordZip runs zip -e in the notes directory, which asks for the
+// password on the terminal
+func passwordZip(config Config, out string) error {
+	if _, err := exec.LookPath("zip"); err != nil {
+		return fmt.Errorf("zip not found in PATH; install it or use --encrypt")
+	}
+	args := []string{"-q", "-r", "-e", out, ".", "-x", "*.lock", JournalFile}
+	for _, dir := range notebookDirs(config.NotesDir, localOnlyNotebooks(config)) {
+		args = append(args, dir+"/*")
+	}
+	cmd := exec.Command("zip", args...)
+	cmd.Dir = config.NotesDir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("zip failed: %w", err)
+	}
+	return nil
+}
+
+// notebookDirs returns the folders under notesDir that are among
+// notebooks, spelled as they are on disk: notebooks match whatever their
+// case, but zip's -x patterns don't. Symlinked folders count, since zip
+// follows them.
+func notebookDirs(notesDir string, notebooks []string) []string {
+	if len(notebooks) == 0 {
+		return nil
+	}
+	var dirs []string
+	filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == notesDir {
+			return nil
+		}
+		rel, _ := filepath.Rel(notesDir, path)
+		rel = filepath.ToSlash(rel)
+		if !inNotebook(rel, notebooks) {
+			return nil
+		}
+		if d.IsDir() {
+			dirs = append(dirs, rel)
+			return filepath.SkipDir
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dirs = append(dirs, rel)
+		}
+		return nil
+	})
+	return dirs
+}
@@ -0,0 +1,369 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Board columns, in order
+const (
+	ColumnTodo = iota
+	ColumnDoing
+	ColumnDone
+)
+
+var columnNames = []string{"Todo", "Doing", "Done"}
+
+// ColorSelected marks the selected task on the board
+const ColorSelected = "\033[7m"
+
+// statusAnnotation matches "@status(doing)", which puts an open task in a
+// column other than Todo
+var statusAnnotation = regexp.MustCompile(`\s*@status\((\w+)\)`)
+
+// boardTask is a checkbox line shown on the board
+type boardTask struct {
+	Note   string // note filename, relative to the notes directory
+	Line   int
+	Text   string // the line as it is in the note
+	Title  string
+	Column int
+}
+
+// runBoard shows tasks from every note (or notes tagged tag) as a kanban
+// board. On a terminal, arrow keys or h/j/k/l select a task and < and >
+// (or H and L) move it, rewriting its line in the note.
+func runBoard(config Config, args []string) error {
+	tag := strings.TrimPrefix(strings.Join(args, " "), "#")
+	tasks := boardTasks(config.NotesDir, tag)
+
+	if !isInputFromTerminal() || !isOutputToTerminal() {
+		fmt.Print(renderBoard(tasks, -1, -1, terminalWidth()))
+		return nil
+	}
+
+	restore, err := rawTerminal()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	col, row := 0, 0
+	message := ""
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		items := columnTasks(tasks, col)
+		row = max(0, min(row, len(items)-1))
+		fmt.Print("\033[H\033[2J" + renderBoard(tasks, col, row, terminalWidth()))
+		if len(items) > 0 {
+			fmt.Printf("\n%s:%d", items[row].Note, items[row].Line+1)
+		}
+		fmt.Printf("\n%s\n←↓↑→/hjkl select  </> move  q quit", message)
+		message = ""
+
+		key, err := readKey(reader)
+		if err != nil {
+			return nil
+		}
+		switch key {
+		case "q", "\x03", "\x1b":
+			fmt.Println()
+			return nil
+		case "h", "left":
+			col = max(0, col-1)
+		case "l", "right":
+			col = min(len(columnNames)-1, col+1)
+		case "k", "up":
+			row--
+		case "j", "down":
+			row++
+		case "<", "H", ">", "L":
+			if len(items) == 0 {
+				continue
+			}
+			target := col + 1
+			if key == "<" || key == "H" {
+				target = col - 1
+			}
+			if target < 0 || target >= len(columnNames) {
+				continue
+			}
+			task := items[row]
+			if err := moveTask(config, task, target); err != nil {
+				message = "Error: " + err.Error()
+				continue
+			}
+			// Keep the moved task selected
+			col = target
+			for i, item := range columnTasks(tasks, col) {
+				if item == task {
+					row = i
+				}
+			}
+		}
+	}
+}
+
+// boardTasks collects the checkbox tasks from every note, or only from
+// notes tagged tag (in frontmatter or inline) when tag is given
+func boardTasks(notesDir, tag string) []*boardTask {
+	var tasks []*boardTask
+	metadata := loadMetadata(notesDir)
+	for _, note := range sortedKeys(metadata) {
+		tagged := tag == ""
+		for _, noteTag := range metadata[note].Tags {
+			tagged = tagged || strings.EqualFold(noteTag, tag)
+		}
+		if !tagged {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(notesDir, note))
+		if err != nil {
+			continue
+		}
+		inCode := false
+		for i, line := range splitLines(string(content)) {
+			if strings.HasPrefix(strings.TrimSpace(line), "```") {
+				inCode = !inCode
+				continue
+			}
+			title, column, ok := parseTaskLine(line)
+			if inCode || !ok {
+				continue
+			}
+			tasks = append(tasks, &boardTask{Note: note, Line: i, Text: line, Title: title, Column: column})
+		}
+	}
+	return tasks
+}
+
+// parseTaskLine reads a checkbox line: "- [x]" is done, "- [ ]" is todo
+// unless an @status annotation says otherwise
+func parseTaskLine(line string) (string, int, bool) {
+	items := checklistItems(line)
+	if len(items) == 0 {
+		return "", 0, false
+	}
+	column := ColumnTodo
+	if items[0].Done {
+		column = ColumnDone
+	}
+	if match := statusAnnotation.FindStringSubmatch(line); match != nil {
+		for i, name := range columnNames {
+			if strings.EqualFold(match[1], name) {
+				column = i
+			}
+		}
+	}
+	return strings.TrimSpace(statusAnnotation.ReplaceAllString(items[0].Text, "")), column, true
+}
+
+// setTaskColumn rewrites a checkbox line so it belongs in column, keeping
+// its indentation and text
+func setTaskColumn(line string, column int) string {
+	line = statusAnnotation.ReplaceAllString(line, "")
+	box := strings.Index(line, "[")
+	if box < 0 || box+3 > len(line) {
+		return line
+	}
+	mark := " "
+	if column == ColumnDone {
+		mark = "x"
+	}
+	line = line[:box+1] + mark + line[box+2:]
+	if column == ColumnDoing {
+		line += " @status(doing)"
+	}
+	return line
+}
+
+// moveTask moves task to column, rewriting its line in the note under the
+// notes lock. The move is refused if the line changed since the board was
+// loaded.
+func moveTask(config Config, task *boardTask, column int) error {
+	notePath := filepath.Join(config.NotesDir, task.Note)
+	wasLocked, err := checkNoteWritable(notePath, false)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	return withNotesLock(config.NotesDir, func() error {
+		content, err := os.ReadFile(notePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", task.Note, err)
+		}
+		lines := splitLines(string(content))
+		if task.Line >= len(lines) || lines[task.Line] != task.Text {
+			return fmt.Errorf("%s changed since the board was loaded", task.Note)
+		}
+		lines[task.Line] = setTaskColumn(task.Text, column)
+		if err := writeFileAtomic(notePath, []byte(joinLines(lines)), notePerm(notePath)); err != nil {
+			return fmt.Errorf("error writing %s: %w", task.Note, err)
+		}
+		task.Text = lines[task.Line]
+		task.Column = column
+		return nil
+	})
+}
+
+func columnTasks(tasks []*boardTask, column int) []*boardTask {
+	var result []*boardTask
+	for _, task := range tasks {
+		if task.Column == column {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// renderBoard lays the columns out side by side in width characters,
+// highlighting the task at row in column col
+func renderBoard(tasks []*boardTask, col, row, width int) string {
+	colWidth := max(10, (width-2*(len(columnNames)-1))/len(columnNames))
+	columns := make([][]*boardTask, len(columnNames))
+	height := 0
+	for i := range columnNames {
+		columns[i] = columnTasks(tasks, i)
+		height = max(height, len(columns[i]))
+	}
+
+	headings := make([]string, len(columnNames))
+	for i, name := range columnNames {
+		headings[i] = padRight(fmt.Sprintf("%s (%d)", name, len(columns[i])), colWidth)
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(strings.Join(headings, "  "), " ") + "\n")
+	b.WriteString(strings.Repeat("─", min(width, colWidth*len(columnNames)+2*(len(columnNames)-1))) + "\n")
+
+	for r := 0; r < height; r++ {
+		var line strings.Builder
+		for i := range columnNames {
+			cell := ""
+			if r < len(columns[i]) {
+				cell = "• " + columns[i][r].Title
+			}
+			cell = padRight(cell, colWidth)
+			if i == col && r == row {
+				cell = ColorSelected + cell + ColorReset
+			}
+			line.WriteString(cell)
+			if i < len(columnNames)-1 {
+				line.WriteString("  ")
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+	return b.String()
+}
+
+// padRight truncates or pads s to exactly width runes
+func padRight(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+// terminalWidth returns the width of the terminal, or 80 when unknown
+func terminalWidth() int {
+	width, _ := terminalSize()
+	return width
+}
+
+// terminalSize returns the width and height of the terminal from $COLUMNS
+// and $LINES or stty, or 80 by 24 when unknown
+func terminalSize() (int, int) {
+	width, height := 80, 24
+	cmd := exec.Command("stty", "size")
+	cmd.Stdin = os.Stdin
+	if output, err := cmd.Output(); err == nil {
+		if fields := strings.Fields(string(output)); len(fields) == 2 {
+			if rows, err := strconv.Atoi(fields[0]); err == nil && rows > 0 {
+				height = rows
+			}
+			if columns, err := strconv.Atoi(fields[1]); err == nil && columns > 0 {
+				width = columns
+			}
+		}
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		width = columns
+	}
+	if rows, err := strconv.Atoi(os.Getenv("LINES")); err == nil && rows > 0 {
+		height = rows
+	}
+	return width, height
+}
+
+// rawTerminal switches the terminal to unbuffered, unechoed input with stty
+// and returns a function that restores it. Signal keys are turned off, so
+// Ctrl-C reaches readKey as "\x03" and the caller gets to restore the
+// terminal instead of dying with echo off. Output processing is left on, so
+// "\n" still starts a new line.
+func rawTerminal() (func(), error) {
+	stty := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = os.Stdin
+		return cmd.Output()
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("error reading terminal settings: %w", err)
+	}
+	if _, err := stty("-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return nil, fmt.Errorf("error setting up terminal: %w", err)
+	}
+	return func() { stty(strings.TrimSpace(string(saved))) }, nil
+}
+
+// readKey reads one key press, naming the arrow keys
+func readKey(reader *bufio.Reader) (string, error) {
+	r, _, err := reader.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	if r != '\x1b' || reader.Buffered() < 2 {
+		return string(r), nil
+	}
+	seq := make([]byte, 2)
+	reader.Read(seq)
+	switch string(seq) {
+	case "[A":
+		return "up", nil
+	case "[B":
+		return "down", nil
+	case "[C":
+		return "right", nil
+	case "[D":
+		return "left", nil
+	case "[Z":
+		return "backtab", nil
+	}
+	return "", nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BookmarksFile keeps one reading position per note, "note<TAB>line" per
+// line, so marking a place never touches the note itself
+const BookmarksFile = ".note_bookmarks"
+
+// runMark saves, shows or clears the reading position in a note:
+//
+//	note --mark <name> <line>
+//	note --mark <name>            show the bookmark
+//	note --mark <name> --clear
+//	note --mark                   list every bookmark
+func runMark(config Config, args []string) error {
+	path := filepath.Join(config.NotesDir, BookmarksFile)
+	if len(args) == 0 {
+		marks := loadBookmarks(path)
+		if len(marks) == 0 {
+			fmt.Println("No bookmarks (set one with 'note --mark <name> <line>')")
+		}
+		for _, note := range sortedKeys(marks) {
+			fmt.Printf("%s:%d\n", note, marks[note])
+		}
+		return nil
+	}
+
+	last := args[len(args)-1]
+	line, lineErr := strconv.Atoi(last)
+	clearing := last == "--clear"
+	nameArgs := args
+	if clearing || lineErr == nil {
+		nameArgs = args[:len(args)-1]
+	}
+	if len(nameArgs) == 0 {
+		return usageErrorf("usage: note --mark <name> [<line> | --clear]")
+	}
+	notePath, err := existingNotePath(config, strings.Join(nameArgs, " "))
+	if err != nil {
+		return err
+	}
+	note, _ := filepath.Rel(config.NotesDir, notePath)
+
+	switch {
+	case clearing:
+		if err := setBookmark(path, note, 0); err != nil {
+			return err
+		}
+		fmt.Printf("Cleared the bookmark in %s\n", note)
+	case lineErr == nil:
+		content, err := os.ReadFile(notePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", note, err)
+		}
+		if lines := len(splitLines(string(content))); line < 1 || line > lines {
+			return usageErrorf("%s has lines 1 to %d", note, lines)
+		}
+		if err := setBookmark(path, note, line); err != nil {
+			return err
+		}
+		fmt.Printf("Marked %s at line %d\n", note, line)
+	default:
+		if line, ok := loadBookmarks(path)[note]; ok {
+			fmt.Printf("%s:%d\n", note, line)
+		} else {
+			fmt.Printf("No bookmark in %s\n", note)
+		}
+	}
+	return nil
+}
+
+// runResume opens a note at its bookmark, or at the top if it has none
+func runResume(config Config, flags *ParsedFlags, name string) error {
+	notePath, err := existingNotePath(config, name)
+	if err != nil {
+		return err
+	}
+	note, _ := filepath.Rel(config.NotesDir, notePath)
+	line, ok := loadBookmarks(filepath.Join(config.NotesDir, BookmarksFile))[note]
+	if !ok {
+		fmt.Printf("No bookmark in %s; opening at the top\n", note)
+	}
+
+	wasLocked, err := checkNoteEditable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+	return editNoteAt(config, notePath, line)
+}
+
+// loadBookmarks returns the bookmarked line of each note
+func loadBookmarks(path string) map[string]int {
+	marks := make(map[string]int)
+	file, err := os.Open(path)
+	if err != nil {
+		return marks
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		note, value, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		if line, err := strconv.Atoi(value); err == nil && line > 0 {
+			marks[note] = line
+		}
+	}
+	return marks
+}
+
+// setBookmark records line as the bookmark for note; 0 removes it
+func setBookmark(path, note string, line int) error {
+	return withStateLock(path, func() error {
+		marks := loadBookmarks(path)
+		if line > 0 {
+			marks[note] = line
+		} else {
+			delete(marks, note)
+		}
+		var b strings.Builder
+		for _, name := range sortedKeys(marks) {
+			fmt.Fprintf(&b, "%s\t%d\n", name, marks[name])
+		}
+		if err := writeFileAtomic(path, []byte(b.String()), 0644); err != nil {
+			return fmt.Errorf("error saving bookmarks: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,445 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BridgeStateFile remembers how far each chat channel has been pulled,
+// "service:channel<TAB>position" per line, so no message is captured twice
+const BridgeStateFile = ".note_bridge"
+
+// BridgeFirstPull is how far back the first pull from a channel reaches
+const BridgeFirstPull = 24 * time.Hour
+
+// DefaultBridgeInterval is how often --watch polls unless bridge_interval
+// is set
+const DefaultBridgeInterval = time.Minute
+
+// DefaultBridgeTimeout bounds each pull unless timeout is set
+const DefaultBridgeTimeout = 30 * time.Second
+
+// SlackAPIURL is the base of Slack's Web API
+var SlackAPIURL = "https://slack.com/api"
+
+// chatMessage is one message pulled from a chat channel
+type chatMessage struct {
+	Position string // where the channel has been read up to once this is captured
+	Text     string
+}
+
+// chatPull fetches the messages after position, oldest first; an empty
+// position means the channel has never been pulled
+type chatPull func(ctx context.Context, position string) ([]chatMessage, error)
+
+// runBridge pulls messages from a chat channel into the inbox note, so
+// captures made on a phone can go through chat:
+//
+//	note --bridge slack --channel notes-inbox [--watch]
+//	note --bridge matrix --room '#notes:example.org' [--watch]
+//
+// Slack needs slack_token (a bot token with channels:history and
+// channels:read); Matrix needs matrix_url and matrix_token. Store tokens
+// with --encrypt-config to keep them in the keyring rather than ~/.note.
+func runBridge(config Config, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: note --bridge slack|matrix --channel <name> [--watch]")
+	}
+	service, channel, watch := args[0], "", false
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--channel", "--room":
+			if i+1 >= len(args) {
+				return usageErrorf("%s requires a name", args[i])
+			}
+			i++
+			channel = args[i]
+		case "--watch":
+			watch = true
+		default:
+			return usageErrorf("unknown argument '%s'", args[i])
+		}
+	}
+	if channel == "" {
+		return usageErrorf("usage: note --bridge %s --channel <name> [--watch]", service)
+	}
+
+	var pull chatPull
+	switch service {
+	case "slack":
+		token := config.option("slack_token")
+		if token == "" {
+			return fmt.Errorf("no slack_token; store one with 'note --encrypt-config slack_token'")
+		}
+		channel = strings.TrimPrefix(channel, "#")
+		pull = slackPull(token, channel)
+	case "matrix":
+		server, token := config.option("matrix_url"), config.option("matrix_token")
+		if server == "" || token == "" {
+			return fmt.Errorf("set matrix_url and matrix_token (with 'note --encrypt-config matrix_token') to use the Matrix bridge")
+		}
+		pull = matrixPull(server, token, channel)
+	default:
+		return usageErrorf("unknown bridge '%s' (use slack or matrix)", service)
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	source := service + ":" + channel
+	if !watch {
+		_, err := bridgeOnce(ctx, config, source, pull)
+		return err
+	}
+
+	interval := DefaultBridgeInterval
+	if value, err := time.ParseDuration(config.option("bridge_interval")); err == nil && value > 0 {
+		interval = value
+	}
+	fmt.Printf("Watching %s every %s (Ctrl-C to stop)\n", source, interval)
+	for {
+		if _, err := bridgeOnce(ctx, config, source, pull); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// bridgeOnce appends any new messages from source to the inbox, returning
+// how many there were. The saved position only moves once they are in the
+// inbox, so a failed pull is retried rather than lost.
+func bridgeOnce(ctx context.Context, config Config, source string, pull chatPull) (int, error) {
+	statePath := filepath.Join(config.NotesDir, BridgeStateFile)
+	positions := loadBridgeState(statePath)
+
+	pullCtx, cancel := context.WithTimeout(ctx, config.netTimeout(DefaultBridgeTimeout))
+	defer cancel()
+	messages, err := pull(pullCtx, positions[source])
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", source, err)
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	var captured strings.Builder
+	for _, message := range messages {
+		captured.WriteString(inboxBullet(message.Text))
+	}
+	notePath := inboxPath(config)
+	wasLocked, err := checkNoteWritable(notePath, false)
+	if err != nil {
+		return 0, err
+	}
+	defer restoreLock(notePath, wasLocked)
+	if _, err := appendOrLog(config, notePath, captured.String(), appendOptions{}); err != nil {
+		return 0, err
+	}
+
+	err = withStateLock(statePath, func() error {
+		positions := loadBridgeState(statePath)
+		positions[source] = messages[len(messages)-1].Position
+		return saveBridgeState(statePath, positions)
+	})
+	if err != nil {
+		return 0, err
+	}
+	fmt.Printf("Captured %d message(s) from %s to %s\n", len(messages), source, filepath.Base(notePath))
+	return len(messages), nil
+}
+
+func loadBridgeState(statePath string) map[string]string {
+	positions := make(map[string]string)
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		return positions
+	}
+	for _, line := range splitLines(string(data)) {
+		if source, position, ok := strings.Cut(line, "\t"); ok {
+			positions[source] = position
+		}
+	}
+	return positions
+}
+
+func saveBridgeState(statePath string, positions map[string]string) error {
+	var b strings.Builder
+	for _, source := range sortedKeys(positions) {
+		fmt.Fprintf(&b, "%s\t%s\n", source, positions[source])
+	}
+	if err := writeFileAtomic(statePath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("error saving bridge state: %w", err)
+	}
+	return nil
+}
+
+// getJSON fetches url with a bearer token and decodes the JSON reply
+func getJSON(ctx context.Context, url, token string, reply interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(reply); err != nil {
+		return fmt.Errorf("unexpected response (%s)", resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request failed (%s)", resp.Status)
+	}
+	return nil
+}
+
+// slackPull reads a Slack channel by name or ID. Positions are Slack
+// message timestamps such as 1712345678.000100.
+func slackPull(token, channel string) chatPull {
+	channelID := ""
+	return func(ctx context.Context, position string) ([]chatMessage, error) {
+		if channelID == "" {
+			id, err := slackChannelID(ctx, token, channel)
+			if err != nil {
+				return nil, err
+			}
+			channelID = id
+		}
+		if position == "" {
+			position = strconv.FormatInt(time.Now().Add(-BridgeFirstPull).Unix(), 10)
+		}
+
+		type slackMessage struct {
+			TS      string `json:"ts"`
+			Text    string `json:"text"`
+			Subtype string `json:"subtype"`
+		}
+		var all []slackMessage
+		cursor := ""
+		for {
+			params := url.Values{"channel": {channelID}, "oldest": {position}, "limit": {"200"}}
+			if cursor != "" {
+				params.Set("cursor", cursor)
+			}
+			var reply struct {
+				OK       bool           `json:"ok"`
+				Error    string         `json:"error"`
+				Messages []slackMessage `json:"messages"`
+				Metadata struct {
+					NextCursor string `json:"next_cursor"`
+				} `json:"response_metadata"`
+			}
+			if err := getJSON(ctx, SlackAPIURL+"/conversations.history?"+params.Encode(), token, &reply); err != nil {
+				return nil, err
+			}
+			if !reply.OK {
+				return nil, fmt.Errorf("slack error: %s", reply.Error)
+			}
+			all = append(all, reply.Messages...)
+			if cursor = reply.Metadata.NextCursor; cursor == "" {
+				break
+			}
+		}
+
+		// Slack returns newest first; joins, topic changes and the like
+		// have a subtype and are not captures
+		sort.Slice(all, func(i, j int) bool { return slackTSLess(all[i].TS, all[j].TS) })
+		var messages []chatMessage
+		for _, message := range all {
+			if (message.Subtype == "" || message.Subtype == "file_share") && strings.TrimSpace(message.Text) != "" && slackTSLess(position, message.TS) {
+				messages = append(messages, chatMessage{Position: message.TS, Text: slackText(message.Text)})
+			}
+		}
+		return messages, nil
+	}
+}
+
+// slackChannelID looks up a channel's ID from its name; IDs are returned
+// as they are
+func slackChannelID(ctx context.Context, token, channel string) (string, error) {
+	if regexp.MustCompile(`^[CG][A-Z0-9]{6,}$`).MatchString(channel) {
+		return channel, nil
+	}
+	cursor := ""
+	for {
+		params := url.Values{"types": {"public_channel,private_channel"}, "exclude_archived": {"true"}, "limit": {"1000"}}
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+		var reply struct {
+			OK       bool   `json:"ok"`
+			Error    string `json:"error"`
+			Channels []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"channels"`
+			Metadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+		if err := getJSON(ctx, SlackAPIURL+"/conversations.list?"+params.Encode(), token, &reply); err != nil {
+			return "", err
+		}
+		if !reply.OK {
+			return "", fmt.Errorf("slack error: %s", reply.Error)
+		}
+		for _, c := range reply.Channels {
+			if c.Name == channel {
+				return c.ID, nil
+			}
+		}
+		if cursor = reply.Metadata.NextCursor; cursor == "" {
+			return "", fmt.Errorf("no channel named '%s' (is the bot a member?)", channel)
+		}
+	}
+}
+
+// slackTSLess orders Slack timestamps, which are too precise for float64
+func slackTSLess(a, b string) bool {
+	split := func(ts string) (int64, int64) {
+		seconds, fraction, _ := strings.Cut(ts, ".")
+		s, _ := strconv.ParseInt(seconds, 10, 64)
+		f, _ := strconv.ParseInt((fraction + "000000")[:6], 10, 64)
+		return s, f
+	}
+	as, af := split(a)
+	bs, bf := split(b)
+	return as < bs || (as == bs && af < bf)
+}
+
+var slackMarkup = regexp.MustCompile(`<([^<>|]+)(?:\|([^<>]*))?>`)
+
+// slackText turns Slack's message markup into markdown: <url|label> links
+// become [label](url), channel references #name and @here stays @here
+func slackText(text string) string {
+	text = slackMarkup.ReplaceAllStringFunc(text, func(markup string) string {
+		match := slackMarkup.FindStringSubmatch(markup)
+		target, label := match[1], match[2]
+		switch {
+		case strings.HasPrefix(target, "#"):
+			if label != "" {
+				return "#" + label
+			}
+			return target
+		case strings.HasPrefix(target, "!"):
+			return "@" + strings.TrimPrefix(target, "!")
+		case strings.HasPrefix(target, "@"):
+			return target
+		case label != "" && label != target:
+			return "[" + label + "](" + target + ")"
+		}
+		return strings.TrimPrefix(target, "mailto:")
+	})
+	return html.UnescapeString(text)
+}
+
+// matrixPull reads a Matrix room by ID (!abc:server) or alias
+// (#name:server). Positions are origin_server_ts values in milliseconds.
+func matrixPull(server, token, room string) chatPull {
+	server = strings.TrimRight(server, "/")
+	roomID := ""
+	return func(ctx context.Context, position string) ([]chatMessage, error) {
+		if roomID == "" {
+			roomID = room
+			if strings.HasPrefix(room, "#") {
+				var reply struct {
+					RoomID string `json:"room_id"`
+					Error  string `json:"error"`
+				}
+				if err := getJSON(ctx, server+"/_matrix/client/v3/directory/room/"+url.PathEscape(room), token, &reply); err != nil {
+					return nil, fmt.Errorf("no room '%s': %w", room, err)
+				}
+				roomID = reply.RoomID
+			}
+		}
+		since, err := strconv.ParseInt(position, 10, 64)
+		if err != nil {
+			since = time.Now().Add(-BridgeFirstPull).UnixMilli()
+		}
+
+		// Page backwards from the newest event until reaching the position
+		var messages []chatMessage
+		from := ""
+		for {
+			params := url.Values{"dir": {"b"}, "limit": {"100"}}
+			if from != "" {
+				params.Set("from", from)
+			}
+			var reply struct {
+				Chunk []struct {
+					Type    string `json:"type"`
+					TS      int64  `json:"origin_server_ts"`
+					Content struct {
+						MsgType string `json:"msgtype"`
+						Body    string `json:"body"`
+					} `json:"content"`
+				} `json:"chunk"`
+				End   string `json:"end"`
+				Error string `json:"error"`
+			}
+			if err := getJSON(ctx, server+"/_matrix/client/v3/rooms/"+url.PathEscape(roomID)+"/messages?"+params.Encode(), token, &reply); err != nil {
+				if reply.Error != "" {
+					return nil, fmt.Errorf("matrix error: %s", reply.Error)
+				}
+				return nil, err
+			}
+			reachedPosition := false
+			for _, event := range reply.Chunk {
+				if event.TS <= since {
+					reachedPosition = true
+					break
+				}
+				switch event.Content.MsgType {
+				case "m.text", "m.notice", "m.emote":
+					if event.Type == "m.room.message" && strings.TrimSpace(event.Content.Body) != "" {
+						messages = append(messages, chatMessage{Position: strconv.FormatInt(event.TS, 10), Text: event.Content.Body})
+					}
+				}
+			}
+			if reachedPosition || reply.End == "" || len(reply.Chunk) == 0 {
+				break
+			}
+			from = reply.End
+		}
+
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+		return messages, nil
+	}
+}
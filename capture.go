@@ -0,0 +1,181 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// intoArg extracts "--into <note>" from args, returning the note name and
+// the remaining arguments
+func intoArg(args []string) (string, []string) {
+	var into string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--into" && i+1 < len(args) {
+			i++
+			into = args[i]
+			continue
+		}
+		rest = append(rest, args[i])
+	}
+	return into, rest
+}
+
+// runTranscribe runs the configured transcriber on an audio file and
+// appends the transcript to a note:
+//
+//	note --transcribe memo.m4a --into ideas
+func runTranscribe(config Config, flags *ParsedFlags, args []string) error {
+	into, rest := intoArg(args)
+	if into == "" || len(rest) != 1 {
+		return usageErrorf("usage: note --transcribe <audio-file> --into <note>")
+	}
+	transcriber := strings.Fields(config.option("transcriber"))
+	if len(transcriber) == 0 {
+		return fmt.Errorf("no transcriber configured; set transcriber=<command> in ~/.note, e.g. transcriber=whisper-cli -m <model> -nt -f")
+	}
+
+	fmt.Printf("Transcribing %s...\n", filepath.Base(rest[0]))
+	transcript, err := runCaptureCommand(transcriber, rest[0])
+	if err != nil {
+		return err
+	}
+	heading := fmt.Sprintf("### %s Voice memo (%s)", time.Now().Format("2006-01-02 15:04"), filepath.Base(rest[0]))
+	return appendCapture(config, flags, into, heading+"\n\n"+transcript)
+}
+
+// runCapturedCommand runs a shell command, showing its output as usual, and
+// appends the command line and everything it printed to a note as a fenced
+// block:
+//
+//	note --run "kubectl get pods" --into incident-2025
+func runCapturedCommand(config Config, flags *ParsedFlags, args []string) error {
+	into, rest := intoArg(args)
+	command := strings.TrimSpace(strings.Join(rest, " "))
+	if into == "" || command == "" {
+		return usageErrorf("usage: note --run \"<command>\" --into <note>")
+	}
+
+	started := time.Now()
+	var output bytes.Buffer
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &output)
+	status := ""
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Errorf("error running command: %w", err)
+		}
+		status = exitErr.Error()
+	}
+
+	return appendCapture(config, flags, into, commandCaptureBlock(command, output.String(), status, started))
+}
+
+// commandCaptureBlock formats a captured command run
+func commandCaptureBlock(command, output, status string, at time.Time) string {
+	body := "$ " + command + "\n" + strings.TrimRight(output, "\n")
+	if status != "" {
+		body += "\n[" + status + "]"
+	}
+	fence := fenceFor(body)
+	return fmt.Sprintf("### %s Ran `%s`\n\n%sconsole\n%s\n%s", at.Format("2006-01-02 15:04:05"), command, fence, strings.TrimRight(body, "\n"), fence)
+}
+
+// fenceFor returns a code fence longer than any backtick run in body, so
+// the block cannot be cut short by output that contains a fence itself
+func fenceFor(body string) string {
+	fence, run := "```", 0
+	for _, r := range body {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		if run++; run >= len(fence) {
+			fence += "`"
+		}
+	}
+	return fence
+}
+
+// runCaptureCommand runs command on file and returns what it printed,
+// trimmed. The file replaces a {} argument, or is passed last if there is
+// none.
+func runCaptureCommand(command []string, file string) (string, error) {
+	if _, err := os.Stat(file); err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", file, err)
+	}
+	args := make([]string, 0, len(command))
+	placed := false
+	for _, arg := range command[1:] {
+		if arg == "{}" {
+			arg, placed = file, true
+		}
+		args = append(args, arg)
+	}
+	if !placed {
+		args = append(args, file)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(command[0], args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed: %w", command[0], err)
+	}
+	output := strings.TrimSpace(stdout.String())
+	if output == "" {
+		return "", fmt.Errorf("%s produced no text for %s", command[0], filepath.Base(file))
+	}
+	return output, nil
+}
+
+// appendCapture appends captured text to a note as its own paragraph,
+// honoring --prepend, --under and locks like --append
+func appendCapture(config Config, flags *ParsedFlags, into, text string) error {
+	notePath := resolveNotePath(config, into)
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	if content, err := os.ReadFile(notePath); err == nil && len(bytes.TrimSpace(content)) > 0 && !flags.Prepend {
+		// Keep the capture apart from whatever came before it
+		text = "\n" + text
+	}
+	opts := appendOptions{Prepend: flags.Prepend, Under: flags.Under}
+	if err := appendToNote(config, notePath, text, opts); err != nil {
+		return err
+	}
+	postSave(config, notePath)
+	fmt.Printf("Appended to %s\n", filepath.Base(notePath))
+	return nil
+}
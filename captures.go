@@ -0,0 +1,235 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CapturesDir holds the append-only capture log. With capture_log=true,
+// plain appends and inbox captures go to
+// .captures/<note>/<device>-<YYYYMMDD>.jsonl instead of the note, so
+// devices capturing at the same time never write the same file. Pending
+// captures are merged in when a note is read, and --compact (or opening the
+// note to edit it) folds them into the note itself.
+const CapturesDir = ".captures"
+
+// captureEntry is one logged capture, stored as a JSON line
+type captureEntry struct {
+	At   time.Time `json:"at"`
+	Text string    `json:"text"`
+}
+
+var unsafeDeviceChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// deviceName names this machine's capture files: the device setting, or
+// the hostname
+func deviceName(config Config) string {
+	name := config.option("device")
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	if name = strings.Trim(unsafeDeviceChars.ReplaceAllString(name, "-"), "-"); name == "" {
+		name = "device"
+	}
+	return name
+}
+
+// captureLogDir returns the directory holding a note's pending captures
+func captureLogDir(config Config, notePath string) string {
+	rel, err := filepath.Rel(config.NotesDir, notePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(notePath)
+	}
+	return filepath.Join(config.NotesDir, CapturesDir, strings.TrimSuffix(rel, ".md"))
+}
+
+// appendOrLog appends text to the end of a note, or logs it when the
+// capture log is on. Appends that place text elsewhere always go straight
+// to the note. It reports whether the text was logged.
+func appendOrLog(config Config, notePath, text string, opts appendOptions) (bool, error) {
+	if !config.boolOption("capture_log") || opts != (appendOptions{}) {
+		return false, appendToNote(config, notePath, text, opts)
+	}
+	return true, logCapture(config, notePath, text, time.Now())
+}
+
+// logCapture appends one capture to this device's log for the day
+func logCapture(config Config, notePath, text string, now time.Time) error {
+	dir := captureLogDir(config, notePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating capture log: %w", err)
+	}
+	line, err := json.Marshal(captureEntry{At: now, Text: text})
+	if err != nil {
+		return err
+	}
+	logPath := filepath.Join(dir, deviceName(config)+"-"+now.Format("20060102")+".jsonl")
+	return withStateLock(dir, func() error {
+		file, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("error writing capture log: %w", err)
+		}
+		if _, err := file.Write(append(line, '\n')); err != nil {
+			file.Close()
+			return fmt.Errorf("error writing capture log: %w", err)
+		}
+		return file.Close()
+	})
+}
+
+// pendingCaptures returns a note's logged captures from every device in
+// the order they were made, and the log files they came from
+func pendingCaptures(config Config, notePath string) ([]captureEntry, []string, error) {
+	logs, _ := filepath.Glob(filepath.Join(captureLogDir(config, notePath), "*.jsonl"))
+	var entries []captureEntry
+	for _, logPath := range logs {
+		file, err := os.Open(logPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading capture log: %w", err)
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+		for scanner.Scan() {
+			var entry captureEntry
+			// A torn last line from a crash is skipped rather than fatal
+			if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+				entries = append(entries, entry)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading capture log: %w", err)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.Before(entries[j].At) })
+	return entries, logs, nil
+}
+
+// mergeCaptures returns content with the captures appended as appendToNote
+// would have
+func mergeCaptures(content string, entries []captureEntry) string {
+	for _, entry := range entries {
+		content = insertText(content, entry.Text, appendOptions{})
+	}
+	return content
+}
+
+// readNoteWithCaptures reads a note with its pending captures merged in
+func readNoteWithCaptures(config Config, notePath string) ([]byte, error) {
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return nil, err
+	}
+	entries, _, err := pendingCaptures(config, notePath)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(mergeCaptures(string(content), entries)), nil
+}
+
+// compactNote folds a note's pending captures into the note and removes
+// their logs, returning how many were folded. The note is rewritten under
+// the notes lock as well as the logs' own. A crash between writing the
+// note and removing the logs can duplicate captures but never loses one.
+func compactNote(config Config, notePath string) (int, error) {
+	dir := captureLogDir(config, notePath)
+	if logs, _ := filepath.Glob(filepath.Join(dir, "*.jsonl")); len(logs) == 0 {
+		return 0, nil
+	}
+	folded := 0
+	err := withNotesLock(config.NotesDir, func() error {
+		return withStateLock(dir, func() error {
+			entries, logs, err := pendingCaptures(config, notePath)
+			if err != nil || len(logs) == 0 {
+				return err
+			}
+			if len(entries) > 0 {
+				content, err := os.ReadFile(notePath)
+				if err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+				}
+				if err := writeFileAtomic(notePath, []byte(mergeCaptures(string(content), entries)), notePerm(notePath)); err != nil {
+					return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+				}
+			}
+			for _, logPath := range logs {
+				os.Remove(logPath)
+			}
+			os.Remove(dir)
+			folded = len(entries)
+			return nil
+		})
+	})
+	return folded, err
+}
+
+// runCompact folds pending captures into one note, or into every note that
+// has some:
+//
+//	note --compact [name]
+func runCompact(config Config, args []string) error {
+	var notes []string
+	if name := strings.Join(args, " "); name != "" {
+		notes = []string{resolveNotePath(config, name)}
+	} else {
+		root := filepath.Join(config.NotesDir, CapturesDir)
+		filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && strings.HasSuffix(path, ".jsonl") {
+				rel, _ := filepath.Rel(root, filepath.Dir(path))
+				note := filepath.Join(config.NotesDir, rel+".md")
+				if len(notes) == 0 || notes[len(notes)-1] != note {
+					notes = append(notes, note)
+				}
+			}
+			return nil
+		})
+	}
+
+	total := 0
+	for _, notePath := range notes {
+		wasLocked, err := checkNoteWritable(notePath, false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		folded, err := compactNote(config, notePath)
+		restoreLock(notePath, wasLocked)
+		if err != nil {
+			return err
+		}
+		if folded > 0 {
+			postSave(config, notePath)
+			fmt.Printf("Folded %d capture(s) into %s\n", folded, filepath.Base(notePath))
+			total += folded
+		}
+	}
+	if total == 0 {
+		fmt.Println("No pending captures")
+	}
+	return nil
+}
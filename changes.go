@@ -0,0 +1,221 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultChangesSince is the period --changes covers without --since
+const DefaultChangesSince = "1w"
+
+// noteChanges lists the notes created, modified, archived and deleted in a
+// period, by path relative to the notes directory
+type noteChanges struct {
+	Created, Modified, Archived, Deleted []string
+	// FromGit is set when the changes come from the notes' git history;
+	// otherwise only file times are known
+	FromGit bool
+}
+
+// runChanges prints the notes that changed since a date (YYYY-MM-DD) or
+// for a period (e.g. 2w), ready to paste into a status update:
+//
+//	note --changes [--since 2025-03-01]
+func runChanges(config Config, args []string) error {
+	spec := DefaultChangesSince
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--since" || i+1 >= len(args) {
+			return usageErrorf("usage: note --changes [--since YYYY-MM-DD|2w]")
+		}
+		i++
+		spec = args[i]
+	}
+	now := time.Now()
+	since, err := parseSince(spec, now)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	changes, err := collectChanges(config, since)
+	if err != nil {
+		return err
+	}
+	printChanges(os.Stdout, config, since, changes)
+	return nil
+}
+
+// parseSince reads a date, or a period back from now as for --ttl
+func parseSince(spec string, now time.Time) (time.Time, error) {
+	if date, err := time.ParseInLocation("2006-01-02", spec, time.Local); err == nil {
+		return date, nil
+	}
+	if period, err := parseTTL(spec); err == nil {
+		return now.Add(-period), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since '%s' (use YYYY-MM-DD, or a period such as 7d or 2w)", spec)
+}
+
+// collectChanges finds what changed since a time, from the git history
+// when the notes directory is a repository, and from file times otherwise
+// (which cannot tell when a note was archived or deleted)
+func collectChanges(config Config, since time.Time) (noteChanges, error) {
+	changes := noteChanges{}
+	created := make(map[string]bool)
+	modified := make(map[string]bool)
+
+	if _, err := gitIn(config.NotesDir, "rev-parse", "--show-toplevel"); err == nil {
+		changes.FromGit = true
+		output, err := gitIn(config.NotesDir, "log", "--reverse", "--relative", "--find-renames",
+			"--name-status", "--format=commit %P", "--since="+since.Format(time.RFC3339), "--", ".")
+		if err != nil {
+			return changes, err
+		}
+		archived := make(map[string]bool)
+		deleted := make(map[string]bool)
+		archiveDir := filepath.Base(getArchiveDir(config.NotesDir)) + "/"
+		rootCommit := false
+		for _, line := range strings.Split(output, "\n") {
+			// The first commit brings in whatever notes existed when history
+			// was switched on, which is not news
+			if parents, ok := strings.CutPrefix(line, "commit"); ok {
+				rootCommit = strings.TrimSpace(parents) == ""
+				continue
+			}
+			fields := strings.Split(line, "\t")
+			if rootCommit || len(fields) < 2 || !isChangeTracked(fields[len(fields)-1]) {
+				continue
+			}
+			path := fields[len(fields)-1]
+			switch status := fields[0]; {
+			case status == "A":
+				created[path] = true
+				delete(deleted, path)
+			case status == "M":
+				modified[path] = true
+			case status == "D":
+				if created[path] {
+					// Created and deleted in the period: never worth reporting
+					delete(created, path)
+				} else {
+					deleted[path] = true
+				}
+				delete(modified, path)
+			case strings.HasPrefix(status, "R") && len(fields) == 3:
+				from := fields[1]
+				if strings.HasPrefix(path, archiveDir) && !strings.HasPrefix(from, archiveDir) {
+					archived[path] = true
+				} else if created[from] {
+					created[path] = true
+				} else {
+					modified[path] = true
+				}
+				delete(created, from)
+				delete(modified, from)
+			}
+		}
+		changes.Archived = sortedKeys(archived)
+		changes.Deleted = sortedKeys(deleted)
+	}
+
+	// Saves not committed yet still count, and are all there is without git
+	archiveDir := getArchiveDir(config.NotesDir)
+	walkNotes(config.NotesDir, func(path string, info os.FileInfo) error {
+		if info.ModTime().Before(since) || strings.HasPrefix(path, archiveDir+string(os.PathSeparator)) {
+			return nil
+		}
+		rel := noteRelPath(config, path)
+		if created[rel] || modified[rel] {
+			return nil
+		}
+		if match := dateStamp.FindStringSubmatch(info.Name()); match != nil && match[1] >= since.Format("20060102") {
+			created[rel] = true
+		} else {
+			modified[rel] = true
+		}
+		return nil
+	})
+
+	for path := range created {
+		delete(modified, path)
+	}
+	changes.Created = sortedKeys(created)
+	changes.Modified = sortedKeys(modified)
+	return changes, nil
+}
+
+// isChangeTracked reports whether a path in the git history is a note
+func isChangeTracked(path string) bool {
+	return strings.HasSuffix(path, ".md") || isEncryptedNote(path)
+}
+
+// printChanges writes the changes as a plain list for a status update,
+// naming each note by its title where it has one
+func printChanges(w io.Writer, config Config, since time.Time, changes noteChanges) {
+	sections := []struct {
+		Heading string
+		Notes   []string
+	}{
+		{"Created", changes.Created},
+		{"Modified", changes.Modified},
+		{"Archived", changes.Archived},
+		{"Deleted", changes.Deleted},
+	}
+	total := 0
+	for _, section := range sections {
+		total += len(section.Notes)
+	}
+	if total == 0 {
+		fmt.Fprintf(w, "No notes changed since %s\n", since.Format("2006-01-02"))
+	} else {
+		fmt.Fprintf(w, "Notes changed since %s\n", since.Format("2006-01-02"))
+	}
+	for _, section := range sections {
+		if len(section.Notes) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%d):\n", section.Heading, len(section.Notes))
+		for _, note := range section.Notes {
+			fmt.Fprintf(w, "- %s\n", describeChangedNote(config, note))
+		}
+	}
+	if !changes.FromGit {
+		fmt.Fprintln(w, "\nArchived and deleted notes are only tracked with git=true.")
+	}
+}
+
+// describeChangedNote names a note by its title and file, or just its file
+// when it has no title or no longer exists
+func describeChangedNote(config Config, note string) string {
+	if isEncryptedNote(note) {
+		return note
+	}
+	content, err := os.ReadFile(filepath.Join(config.NotesDir, note))
+	if err != nil {
+		return note
+	}
+	if title := noteTitle(string(content)); title != "" {
+		return fmt.Sprintf("%s (%s)", title, note)
+	}
+	return note
+}
@@ -0,0 +1,153 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// checklistItem is one checkbox line in a note
+type checklistItem struct {
+	Text string
+	Done bool
+	Line int // index into the note's lines
+}
+
+// runChecklist starts a dated copy of a checklist template, or reports how
+// far along one is:
+//
+//	note --checklist deploy                    create and open deploy-YYYYMMDD.md
+//	note --checklist status deploy-20250314    show completion
+func runChecklist(config Config, flags *ParsedFlags, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: note --checklist <template> | --checklist status <note>")
+	}
+	if args[0] == "status" {
+		return runChecklistStatus(config, strings.Join(args[1:], " "))
+	}
+
+	name := strings.Join(args, " ")
+	templatePath := filepath.Join(config.NotesDir, TemplatesDir, name+".md")
+	if _, err := os.Stat(templatePath); err != nil {
+		return fmt.Errorf("no checklist template %s", filepath.Join(TemplatesDir, name+".md"))
+	}
+	created, notePath, err := createDatedNote(config, name, time.Now())
+	if err != nil {
+		return err
+	}
+	if !created {
+		fmt.Printf("%s already exists; opening it\n", filepath.Base(notePath))
+	}
+
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+	return editNote(config, notePath)
+}
+
+// runChecklistStatus prints a note's completion and its open items
+func runChecklistStatus(config Config, name string) error {
+	notePath, err := existingNotePath(config, name)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+	items := checklistItems(string(content))
+	if len(items) == 0 {
+		return fmt.Errorf("%s has no checklist items", filepath.Base(notePath))
+	}
+
+	done := 0
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+	fmt.Printf("%s: %d/%d done (%d%%)\n", filepath.Base(notePath), done, len(items), done*100/len(items))
+	for _, item := range items {
+		if !item.Done {
+			fmt.Printf("  [ ] %s\n", item.Text)
+		}
+	}
+	return nil
+}
+
+// checklistItems returns the checkbox list items in a note, outside code
+// blocks
+func checklistItems(content string) []checklistItem {
+	var items []checklistItem
+	inCode := false
+	for i, line := range splitLines(content) {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode || len(trimmed) < 6 || !strings.ContainsRune("-*+", rune(trimmed[0])) || trimmed[1] != ' ' {
+			continue
+		}
+		switch trimmed[2:5] {
+		case "[ ]":
+			items = append(items, checklistItem{Text: strings.TrimSpace(trimmed[5:]), Line: i})
+		case "[x]", "[X]":
+			items = append(items, checklistItem{Text: strings.TrimSpace(trimmed[5:]), Done: true, Line: i})
+		}
+	}
+	return items
+}
+
+// openChecklistItems counts the checklist items in the note at path and how
+// many of them are still open
+func openChecklistItems(path string) (open, total int) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0
+	}
+	items := checklistItems(string(content))
+	for _, item := range items {
+		if !item.Done {
+			open++
+		}
+	}
+	return open, len(items)
+}
+
+// runChecklistHook runs the checklist_done command with the note's path
+// when an edit ticked off a note's last open item
+func runChecklistHook(config Config, notePath string) {
+	hook := strings.Fields(config.option("checklist_done"))
+	if len(hook) == 0 {
+		return
+	}
+	cmd := exec.Command(hook[0], append(hook[1:], notePath)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: checklist_done hook %s failed: %v\n", hook[0], err)
+	}
+}
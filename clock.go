@@ -0,0 +1,231 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ClockTimeFormat is how @clock-in and @clock-out markers write the time
+const ClockTimeFormat = "2006-01-02 15:04"
+
+// clockMarker matches "@clock-in 2025-03-14 09:30" and "@clock-out ..."
+// anywhere in a line, so markers can also be typed by hand
+var clockMarker = regexp.MustCompile(`@clock-(in|out) (\d{4}-\d{2}-\d{2} \d{2}:\d{2})`)
+
+// clockSession is a stretch of tracked time in one note
+type clockSession struct {
+	Note       string
+	Start, End time.Time
+	Running    bool // no @clock-out yet; End is now
+}
+
+// runClock tracks time with markers appended to notes:
+//
+//	note --clock in <note>         start the clock in a note
+//	note --clock out               stop the running clock
+//	note --clock                   show the running clock
+//	note --clock report [--week]   time per note and tag
+func runClock(config Config, flags *ParsedFlags, args []string) error {
+	now := time.Now()
+	if len(args) == 0 {
+		running := runningClock(clockSessions(config.NotesDir, now))
+		if running == nil {
+			fmt.Println("No clock running")
+			return nil
+		}
+		fmt.Printf("Clocked in to %s since %s (%s)\n", running.Note, running.Start.Format("15:04"), formatDuration(running.End.Sub(running.Start)))
+		return nil
+	}
+
+	switch args[0] {
+	case "in":
+		if len(args) < 2 {
+			return usageErrorf("usage: note --clock in <note>")
+		}
+		notePath := resolveNotePath(config, strings.Join(args[1:], " "))
+		// Only one clock runs at a time
+		if running := runningClock(clockSessions(config.NotesDir, now)); running != nil {
+			if err := clockOut(config, flags, running, now); err != nil {
+				return err
+			}
+		}
+		if err := appendClockMarker(config, flags, notePath, "in", now); err != nil {
+			return err
+		}
+		fmt.Printf("Clocked in to %s at %s\n", filepath.Base(notePath), now.Format("15:04"))
+		return nil
+	case "out":
+		running := runningClock(clockSessions(config.NotesDir, now))
+		if running == nil {
+			return fmt.Errorf("no clock running")
+		}
+		return clockOut(config, flags, running, now)
+	case "report":
+		var since time.Time
+		for _, arg := range args[1:] {
+			if arg == "--week" {
+				since = weekStart(now)
+			}
+		}
+		printClockReport(config.NotesDir, clockSessions(config.NotesDir, now), since)
+		return nil
+	}
+	return fmt.Errorf("unknown clock command '%s' (use in, out or report)", args[0])
+}
+
+func clockOut(config Config, flags *ParsedFlags, running *clockSession, now time.Time) error {
+	if err := appendClockMarker(config, flags, filepath.Join(config.NotesDir, running.Note), "out", now); err != nil {
+		return err
+	}
+	fmt.Printf("Clocked out of %s (%s)\n", running.Note, formatDuration(now.Sub(running.Start)))
+	return nil
+}
+
+func appendClockMarker(config Config, flags *ParsedFlags, notePath, kind string, at time.Time) error {
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+	return appendToNote(config, notePath, fmt.Sprintf("@clock-%s %s", kind, at.Format(ClockTimeFormat)), appendOptions{})
+}
+
+// clockSessions reads the clock markers of every note. A running session
+// ends now.
+func clockSessions(notesDir string, now time.Time) []clockSession {
+	var sessions []clockSession
+	for _, note := range sortedKeys(loadMetadata(notesDir)) {
+		content, err := os.ReadFile(filepath.Join(notesDir, note))
+		if err != nil || !strings.Contains(string(content), "@clock-") {
+			continue
+		}
+		sessions = append(sessions, parseClockMarkers(note, string(content), now)...)
+	}
+	return sessions
+}
+
+// parseClockMarkers pairs each @clock-in with the @clock-out after it. A
+// second @clock-in before a @clock-out starts the session over; an
+// unmatched @clock-out is ignored.
+func parseClockMarkers(note, content string, now time.Time) []clockSession {
+	var sessions []clockSession
+	var open *time.Time
+	for _, match := range clockMarker.FindAllStringSubmatch(content, -1) {
+		at, err := time.ParseInLocation(ClockTimeFormat, match[2], time.Local)
+		if err != nil {
+			continue
+		}
+		if match[1] == "in" {
+			open = &at
+			continue
+		}
+		if open != nil && !at.Before(*open) {
+			sessions = append(sessions, clockSession{Note: note, Start: *open, End: at})
+		}
+		open = nil
+	}
+	if open != nil {
+		sessions = append(sessions, clockSession{Note: note, Start: *open, End: now, Running: true})
+	}
+	return sessions
+}
+
+// runningClock returns the most recently started running session
+func runningClock(sessions []clockSession) *clockSession {
+	var running *clockSession
+	for i := range sessions {
+		if sessions[i].Running && (running == nil || sessions[i].Start.After(running.Start)) {
+			running = &sessions[i]
+		}
+	}
+	return running
+}
+
+// printClockReport prints tracked time per note and per tag for sessions
+// starting at or after since
+func printClockReport(notesDir string, sessions []clockSession, since time.Time) {
+	metadata := loadMetadata(notesDir)
+	byNote := make(map[string]time.Duration)
+	byTag := make(map[string]time.Duration)
+	running := make(map[string]bool)
+	var total time.Duration
+	for _, session := range sessions {
+		if session.Start.Before(since) {
+			continue
+		}
+		d := session.End.Sub(session.Start)
+		byNote[session.Note] += d
+		for _, tag := range metadata[session.Note].Tags {
+			byTag[tag] += d
+		}
+		running[session.Note] = running[session.Note] || session.Running
+		total += d
+	}
+
+	if since.IsZero() {
+		fmt.Println("Time tracked")
+	} else {
+		fmt.Printf("Time tracked since %s\n", since.Format("Mon 2006-01-02"))
+	}
+	if total == 0 {
+		fmt.Println("\nNothing tracked")
+		return
+	}
+
+	fmt.Println("\nBy note:")
+	for _, note := range byDuration(byNote) {
+		suffix := ""
+		if running[note] {
+			suffix = " (running)"
+		}
+		fmt.Printf("  %8s  %s%s\n", formatDuration(byNote[note]), note, suffix)
+	}
+	if len(byTag) > 0 {
+		fmt.Println("\nBy tag:")
+		for _, tag := range byDuration(byTag) {
+			fmt.Printf("  %8s  #%s\n", formatDuration(byTag[tag]), tag)
+		}
+	}
+	fmt.Printf("\nTotal: %s\n", formatDuration(total))
+}
+
+// byDuration returns the keys of durations, longest first
+func byDuration(durations map[string]time.Duration) []string {
+	keys := sortedKeys(durations)
+	sort.SliceStable(keys, func(i, j int) bool { return durations[keys[i]] > durations[keys[j]] })
+	return keys
+}
+
+// weekStart returns midnight on the Monday of t's week
+func weekStart(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location())
+}
+
+// formatDuration formats d as hours and minutes, e.g. 2h05m
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
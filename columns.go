@@ -0,0 +1,171 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// listColumns are the columns -l can show with --columns or the columns
+// config setting
+var listColumns = []string{"name", "title", "date", "tags", "words", "size", "notebook"}
+
+// shrinkableColumns may be truncated to fit a narrow terminal, in the order
+// they give up space
+var shrinkableColumns = []string{"title", "tags", "name", "notebook"}
+
+// parseColumns parses a comma-separated column list such as
+// "name,date,tags"
+func parseColumns(spec string) ([]string, error) {
+	var columns []string
+	for _, column := range strings.Split(spec, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" {
+			continue
+		}
+		known := false
+		for _, name := range listColumns {
+			known = known || name == column
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown column '%s' (use %s)", column, strings.Join(listColumns, ", "))
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns given")
+	}
+	return columns, nil
+}
+
+// printNoteColumns prints the listed notes as an aligned table. On a
+// terminal it adds a header and truncates columns to fit the width.
+func printNoteColumns(config Config, notes []string, columns []string) {
+	rows := make([][]string, 0, len(notes)+1)
+	terminal := isOutputToTerminal()
+	if terminal {
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = strings.ToUpper(column)
+		}
+		rows = append(rows, header)
+	}
+	for _, note := range notes {
+		rows = append(rows, noteColumns(config, note, columns))
+	}
+
+	width := 0
+	if terminal {
+		width = terminalWidth()
+	}
+	for _, line := range alignColumns(rows, columns, width) {
+		fmt.Println(line)
+	}
+}
+
+// noteColumns returns the values of columns for a listed note
+func noteColumns(config Config, note string, columns []string) []string {
+	notePath := filepath.Join(config.NotesDir, note)
+	info, _ := os.Stat(notePath)
+	content, _ := os.ReadFile(notePath)
+	var meta *noteMetadata
+
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "name":
+			values[i] = filepath.Base(note)
+		case "title", "tags":
+			if meta == nil {
+				m := extractMetadata(string(content))
+				meta = &m
+			}
+			if column == "title" {
+				values[i] = meta.Title
+			} else if len(meta.Tags) > 0 {
+				values[i] = "#" + strings.Join(meta.Tags, " #")
+			}
+		case "date":
+			if info != nil {
+				values[i] = info.ModTime().Format("2006-01-02")
+			}
+		case "words":
+			lines := splitLines(string(content))
+			body := strings.Join(lines[frontmatterEnd(lines):], "\n")
+			values[i] = strconv.Itoa(len(strings.Fields(body)))
+		case "size":
+			if info != nil {
+				values[i] = formatByteSize(info.Size())
+			}
+		case "notebook":
+			values[i] = "-"
+			if dir := filepath.Dir(note); dir != "." {
+				values[i] = dir
+			}
+		}
+	}
+	return values
+}
+
+// alignColumns pads rows into aligned columns two spaces apart. With a
+// width, the shrinkable columns are truncated, widest first, until the
+// rows fit.
+func alignColumns(rows [][]string, columns []string, width int) []string {
+	widths := make([]int, len(columns))
+	for _, row := range rows {
+		for i, value := range row {
+			widths[i] = max(widths[i], len([]rune(value)))
+		}
+	}
+
+	total := func() int {
+		sum := 2 * (len(widths) - 1)
+		for _, w := range widths {
+			sum += w
+		}
+		return sum
+	}
+	for width > 0 && total() > width {
+		widest := -1
+		for _, name := range shrinkableColumns {
+			for i, column := range columns {
+				if column == name && widths[i] > 8 && (widest == -1 || widths[i] > widths[widest]) {
+					widest = i
+				}
+			}
+		}
+		if widest == -1 {
+			break
+		}
+		widths[widest] = max(8, widths[widest]-(total()-width))
+	}
+
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = padRight(value, widths[i])
+		}
+		lines[r] = strings.TrimRight(strings.Join(cells, "  "), " ")
+	}
+	return lines
+}
@@ -248,6 +248,11 @@ complete -c note -f
 complete -c note -s l -d "List notes"
 complete -c note -s s -d "Search notes" -r
 complete -c note -s a -d "Include archived notes"
+complete -c note -s t -d "List notes by tag" -x -a '(note --complete-tags (commandline -ct))'
+complete -c note -s i -d "Browse notes interactively"
+complete -c note -s f -d "Find a note with a fuzzy finder"
+complete -c note -s j -d "Open the daily journal"
+complete -c note -s c -d "Capture a timestamped line to a note"
 complete -c note -s d -d "Archive notes" -r
 complete -c note -l config -d "Run setup/reconfigure"
 complete -c note -l configure -d "Run setup/reconfigure"
@@ -260,13 +265,18 @@ complete -c note -s h -l help -d "Show help"
 complete -c note -n '__fish_is_first_token' -a '(__note_get_notes)'
 
 # Complete note names after flags that take note arguments
-complete -c note -n '__fish_seen_argument -s l -s a -s d' -a '(__note_get_notes)'
+complete -c note -n '__fish_seen_argument -s l -s a -s d -s c' -a '(__note_get_notes)'
 
 # Alias: n (same as note)
 complete -c n -f
 complete -c n -s l -d "List notes"
 complete -c n -s s -d "Search notes" -r
 complete -c n -s a -d "Include archived notes"
+complete -c n -s t -d "List notes by tag" -x -a '(note --complete-tags (commandline -ct))'
+complete -c n -s i -d "Browse notes interactively"
+complete -c n -s f -d "Find a note with a fuzzy finder"
+complete -c n -s j -d "Open the daily journal"
+complete -c n -s c -d "Capture a timestamped line to a note"
 complete -c n -s d -d "Archive notes" -r
 complete -c n -l config -d "Run setup/reconfigure"
 complete -c n -l configure -d "Run setup/reconfigure"
@@ -275,7 +285,7 @@ complete -c n -l alias -d "Setup shell aliases"
 complete -c n -s v -l version -d "Show version"
 complete -c n -s h -l help -d "Show help"
 complete -c n -n '__fish_is_first_token' -a '(__note_get_notes)'
-complete -c n -n '__fish_seen_argument -s l -s a -s d' -a '(__note_get_notes)'
+complete -c n -n '__fish_seen_argument -s l -s a -s d -s c' -a '(__note_get_notes)'
 
 # Alias: nls (note -l)
 complete -c nls -f
@@ -287,7 +297,7 @@ complete -c nrm -a '(__note_get_notes)'
 `
 
 	noteCompletionFile := filepath.Join(fishCompletionDir, "note.fish")
-	if err := os.WriteFile(noteCompletionFile, []byte(fishCompletionScript), 0644); err != nil {
+	if err := writeFileAtomic(noteCompletionFile, []byte(fishCompletionScript), 0644); err != nil {
 		fmt.Fprintf(os.Stderr, "Error writing fish completion script: %v\n", err)
 		return
 	}
@@ -463,15 +473,23 @@ func cleanupShellConfig(configFile string) {
 	}
 
 	// Write the cleaned file back
-	outFile, err := os.Create(configFile)
-	if err != nil {
-		return
+	var content strings.Builder
+	for _, line := range lines {
+		content.WriteString(line + "\n")
+	}
+	if err := rewriteShellFile(configFile, content.String()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not update %s: %v\n", configFile, err)
 	}
-	defer outFile.Close()
+}
 
-	for _, line := range lines {
-		fmt.Fprintln(outFile, line)
+// rewriteShellFile replaces a shell startup file atomically, keeping its
+// permissions, so another note process or a crash never leaves it cut short
+func rewriteShellFile(path, content string) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
 	}
+	return writeFileAtomic(path, []byte(content), perm)
 }
 
 // detectShell detects the current shell from environment variables
@@ -526,6 +544,28 @@ func generateBashConfig(aliasesEnabled, completionEnabled bool, notePath string)
     local cur="${COMP_WORDS[COMP_CWORD]}"
     local prev="${COMP_WORDS[COMP_CWORD-1]}"
 
+    # While appending, complete :emoji: shortcodes. Bash splits words at
+    # ":", so rebuild the shortcode from the line and complete the rest.
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local word="${line##* }"
+    if [[ "$word" == :* && " ${COMP_WORDS[*]} " == *" --append "* ]]; then
+        local strip="${word%"$cur"}"
+        COMPREPLY=()
+        for code in $(` + shellQuote(notePath) + ` --complete-emoji "$word" 2>/dev/null | cut -f1); do
+            COMPREPLY+=("${code#"$strip"}")
+        done
+        return
+    fi
+
+    # After -t, complete tags one level of nesting at a time
+    if [[ "$prev" == -*t && "$prev" != --* ]]; then
+        COMPREPLY=($(` + shellQuote(notePath) + ` --complete-tags "$cur" 2>/dev/null))
+        if [[ ${#COMPREPLY[@]} -eq 1 && "${COMPREPLY[0]}" == */ ]]; then
+            compopt -o nospace
+        fi
+        return
+    fi
+
     # Check if -a flag is present in the command line
     local include_archive=false
     for word in "${COMP_WORDS[@]}"; do
@@ -565,7 +605,7 @@ func generateBashConfig(aliasesEnabled, completionEnabled bool, notePath string)
     if [[ ${COMP_CWORD} -eq 1 ]]; then
         # If user starts typing a dash, offer flags
         if [[ "$cur" == -* ]]; then
-            local flags="-l -s -a -d -v --config --configure --autocomplete --alias --help --version -h"
+            local flags="-l -s -a -t -i -f -j -c -d -v --config --configure --autocomplete --alias --help --version -h"
             COMPREPLY=($(compgen -W "$flags" -- "${cur}"))
         else
             # Otherwise, prioritize note names
@@ -629,6 +669,22 @@ func generateZshConfig(aliasesEnabled, completionEnabled bool, notePath string)
     local cur="${words[CURRENT]}"
     local prev="${words[CURRENT-1]}"
 
+    # While appending, complete :emoji: shortcodes
+    if [[ "$cur" == :* && ${words[(I)--append]} -gt 0 ]]; then
+        local codes=(${(f)"$(` + shellQuote(notePath) + ` --complete-emoji "$cur" 2>/dev/null | cut -f1)"})
+        compadd -Q -a codes
+        return
+    fi
+
+    # After -t, complete tags one level of nesting at a time
+    if [[ "$prev" == -*t && "$prev" != --* ]]; then
+        local tags=(${(f)"$(` + shellQuote(notePath) + ` --complete-tags "$cur" 2>/dev/null)"})
+        local branches=(${(M)tags:#*/}) leaves=(${tags:#*/})
+        compadd -S '' -a branches
+        compadd -a leaves
+        return
+    fi
+
     # Check if -a flag is present in the command line
     local include_archive=false
     for word in "${words[@]}"; do
@@ -673,7 +729,7 @@ func generateZshConfig(aliasesEnabled, completionEnabled bool, notePath string)
     if [[ $CURRENT -eq 2 ]]; then
         # If user starts typing a dash, offer flags
         if [[ "$cur" == -* ]]; then
-            local flags=("-l" "-s" "-a" "-d" "-v" "--config" "--configure" "--autocomplete" "--alias" "--help" "--version" "-h")
+            local flags=("-l" "-s" "-a" "-t" "-i" "-f" "-j" "-c" "-d" "-v" "--config" "--configure" "--autocomplete" "--alias" "--help" "--version" "-h")
             compadd -a flags
         else
             # Otherwise, prioritize note names
@@ -767,7 +823,7 @@ func WriteCentralizedConfig(shell string, aliasesEnabled, completionEnabled bool
 		return fmt.Errorf("unsupported shell: %s", shell)
 	}
 
-	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+	if err := writeFileAtomic(configPath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("error writing config file: %w", err)
 	}
 
@@ -978,7 +1034,9 @@ func cleanupLegacyShellConfig(configFile string) {
 	if len(newContent) > 0 && !strings.HasSuffix(newContent, "\n") {
 		newContent += "\n"
 	}
-	os.WriteFile(configFile, []byte(newContent), 0644)
+	if err := rewriteShellFile(configFile, newContent); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not update %s: %v\n", configFile, err)
+	}
 }
 
 // cleanupLegacyFishConfig removes old note command aliases from fish config
@@ -1028,5 +1086,7 @@ func cleanupLegacyFishConfig(configFile string) {
 	if !strings.HasSuffix(newContent, "\n") {
 		newContent += "\n"
 	}
-	os.WriteFile(configFile, []byte(newContent), 0644)
+	if err := rewriteShellFile(configFile, newContent); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not update %s: %v\n", configFile, err)
+	}
 }
@@ -0,0 +1,295 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entries of a --config export archive. Template and snippet files keep
+// their paths under the directory entries.
+const (
+	BundleConfig     = "note.conf"
+	BundleTemplates  = "templates"
+	BundleSnippets   = "snippets"
+	BundleDictionary = "dictionary"
+	BundleShell      = "shell"
+)
+
+// bundleDirs maps the archive directories to their place in the notes
+// directory
+var bundleDirs = map[string]string{
+	BundleTemplates: TemplatesDir,
+	BundleSnippets:  SnippetsDir,
+}
+
+// runConfigBundle moves a setup between machines:
+//
+//	note --config export > note-config.tar
+//	note --config import [--force] [note-config.tar]
+//
+// It runs before ~/.note is loaded so a new machine can import without
+// going through first-time setup.
+func runConfigBundle(args []string) error {
+	configPath, err := configFilePath()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "export":
+		if isOutputToTerminal() {
+			return fmt.Errorf("redirect the archive to a file, e.g. note --config export > note-config.tar")
+		}
+		return exportConfigBundle(configPath, os.Stdout)
+	case "import":
+		force := false
+		var file string
+		for _, arg := range args[1:] {
+			if arg == "--force" {
+				force = true
+			} else {
+				file = arg
+			}
+		}
+		in := io.Reader(os.Stdin)
+		if file != "" {
+			f, err := os.Open(file)
+			if err != nil {
+				return fmt.Errorf("error opening %s: %w", file, err)
+			}
+			defer f.Close()
+			in = f
+		} else if isInputFromTerminal() {
+			return usageErrorf("usage: note --config import [--force] <file>")
+		}
+		return importConfigBundle(configPath, in, force)
+	}
+	return fmt.Errorf("unknown config command '%s' (use export or import)", args[0])
+}
+
+// exportConfigBundle writes ~/.note, the templates, snippets and custom
+// dictionary from the notes directory, and which shell integrations are
+// enabled as a tar archive
+func exportConfigBundle(configPath string, out io.Writer) error {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+	config := parseConfig(strings.NewReader(string(content)))
+
+	tw := tar.NewWriter(out)
+	now := time.Now()
+	add := func(name string, data []byte) error {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: now}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := add(BundleConfig, content); err != nil {
+		return fmt.Errorf("error writing archive: %w", err)
+	}
+	for _, entry := range sortedKeys(bundleDirs) {
+		dir := filepath.Join(config.NotesDir, bundleDirs[entry])
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return nil
+			}
+			rel, _ := filepath.Rel(dir, p)
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return add(path.Join(entry, filepath.ToSlash(rel)), data)
+		})
+		if err != nil {
+			return fmt.Errorf("error writing archive: %w", err)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(config.NotesDir, DictionaryFile)); err == nil {
+		if err := add(BundleDictionary, data); err != nil {
+			return fmt.Errorf("error writing archive: %w", err)
+		}
+	}
+	if shell := detectShell(); shell != "" {
+		aliases, completion := GetCentralizedConfigStatus(shell)
+		if err := add(BundleShell, []byte(fmt.Sprintf("aliases=%t\ncompletion=%t\n", aliases, completion))); err != nil {
+			return fmt.Errorf("error writing archive: %w", err)
+		}
+	}
+	return tw.Close()
+}
+
+// importConfigBundle restores an archive from exportConfigBundle. An
+// existing ~/.note is kept as ~/.note.bak and its notesdir wins, since
+// where notes live is particular to each machine. Templates and snippets
+// that already exist are kept unless force is set; dictionary words are
+// merged.
+func importConfigBundle(configPath string, in io.Reader, force bool) error {
+	var imported *Config
+	var dictionary []byte
+	var shell map[string]string
+	files := make(map[string][]byte)
+
+	tr := tar.NewReader(in)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading archive: %w", err)
+		}
+		name := path.Clean(header.Name)
+		if header.Typeflag != tar.TypeReg || !fs.ValidPath(name) {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("error reading archive: %w", err)
+		}
+		switch name {
+		case BundleConfig:
+			config := parseConfig(strings.NewReader(string(data)))
+			imported = &config
+		case BundleDictionary:
+			dictionary = data
+		case BundleShell:
+			shell = parseConfig(strings.NewReader(string(data))).Options
+		default:
+			files[name] = data
+		}
+	}
+	if imported == nil {
+		return fmt.Errorf("not a note config archive (no %s)", BundleConfig)
+	}
+
+	if current, err := os.ReadFile(configPath); err == nil {
+		if notesDir := parseConfig(strings.NewReader(string(current))).NotesDir; notesDir != "" {
+			imported.NotesDir = notesDir
+		}
+		if err := writeFileAtomic(configPath+".bak", current, 0644); err != nil {
+			return fmt.Errorf("error backing up config: %w", err)
+		}
+		fmt.Printf("Kept the previous config as %s\n", configPath+".bak")
+	}
+	if imported.NotesDir == "" {
+		return fmt.Errorf("the imported config has no notesdir")
+	}
+	if err := os.MkdirAll(imported.NotesDir, 0755); err != nil {
+		return fmt.Errorf("error creating notes directory: %w", err)
+	}
+	if err := saveConfig(*imported); err != nil {
+		return err
+	}
+	fmt.Printf("Imported config (notes in %s)\n", imported.NotesDir)
+
+	written, skipped := 0, 0
+	for _, name := range sortedKeys(files) {
+		entry, rel, _ := strings.Cut(name, "/")
+		dir, ok := bundleDirs[entry]
+		if !ok || rel == "" {
+			continue
+		}
+		target := filepath.Join(imported.NotesDir, dir, filepath.FromSlash(rel))
+		if _, err := os.Stat(target); err == nil && !force {
+			skipped++
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("error creating %s: %w", filepath.Dir(target), err)
+		}
+		if err := writeFileAtomic(target, files[name], 0644); err != nil {
+			return fmt.Errorf("error writing %s: %w", target, err)
+		}
+		written++
+	}
+	fmt.Printf("Imported %d template and snippet file(s)", written)
+	if skipped > 0 {
+		fmt.Printf(", kept %d existing (use --force to replace)", skipped)
+	}
+	fmt.Println()
+
+	if dictionary != nil {
+		if err := mergeDictionary(imported.NotesDir, dictionary); err != nil {
+			return err
+		}
+	}
+
+	encrypted := 0
+	for _, value := range imported.Options {
+		if strings.HasPrefix(value, EncryptedPrefix) {
+			encrypted++
+		}
+	}
+	if encrypted > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d encrypted setting(s) need the master key from the old machine; set them again with --encrypt-config\n", encrypted)
+	}
+
+	if shell != nil {
+		restoreShellIntegration(isTrue(shell["aliases"]), isTrue(shell["completion"]))
+	}
+	return nil
+}
+
+// mergeDictionary adds the imported words to the notes directory's custom
+// dictionary
+func mergeDictionary(notesDir string, imported []byte) error {
+	words := loadDictionary(notesDir)
+	for _, word := range strings.Fields(string(imported)) {
+		words[strings.ToLower(word)] = true
+	}
+	content := strings.Join(sortedKeys(words), "\n") + "\n"
+	if err := writeFileAtomic(filepath.Join(notesDir, DictionaryFile), []byte(content), 0644); err != nil {
+		return fmt.Errorf("error writing dictionary: %w", err)
+	}
+	return nil
+}
+
+// restoreShellIntegration regenerates the aliases and completion that were
+// enabled on the exported machine for the current shell. The files are
+// generated rather than copied because they embed the path to note.
+func restoreShellIntegration(aliases, completion bool) {
+	shell := detectShell()
+	if !aliases && !completion || shell == "" {
+		return
+	}
+	err := WriteCentralizedConfig(shell, aliases, completion)
+	if err == nil {
+		err = EnsureSourceLine(shell)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not set up shell integration: %v\n", err)
+		return
+	}
+	if completion && shell == "fish" {
+		SetupFishCompletion()
+	}
+	fmt.Printf("Set up %s integration; restart your shell to use it\n", shell)
+}
@@ -0,0 +1,44 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// interruptible returns a context that is cancelled by Ctrl-C or SIGTERM,
+// for work that can stop cleanly part way: searches, the server and network
+// calls. Interactive commands don't use it, so Ctrl-C still quits them at
+// once. Calling stop restores the default signal handling.
+func interruptible() (ctx context.Context, stop context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// netTimeout returns the timeout setting for network calls, e.g. 10s or
+// 2m, or fallback when it is unset or invalid
+func (c Config) netTimeout(fallback time.Duration) time.Duration {
+	timeout, err := time.ParseDuration(c.option("timeout"))
+	if err != nil || timeout <= 0 {
+		return fallback
+	}
+	return timeout
+}
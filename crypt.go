@@ -0,0 +1,222 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"note/pkg/notes"
+)
+
+// DefaultEncryptWith is the tool --encrypt uses unless encrypt_with says
+// otherwise
+const DefaultEncryptWith = "age"
+
+var isEncryptedNote = notes.IsEncrypted
+
+// runEncrypt replaces a note with a copy encrypted with age or GnuPG,
+// which note then decrypts for editing and encrypts again on save:
+//
+//	note --encrypt <name>
+func runEncrypt(config Config, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: note --encrypt <name>")
+	}
+	notePath, err := existingNotePath(config, strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	name := noteRelPath(config, notePath)
+	if isEncryptedNote(notePath) {
+		return fmt.Errorf("%s is already encrypted", name)
+	}
+	tool := config.option("encrypt_with")
+	if tool == "" {
+		tool = DefaultEncryptWith
+	}
+	encryptedPath := notePath + "." + tool
+	if !isEncryptedNote(encryptedPath) {
+		return fmt.Errorf("unknown encrypt_with %q (use age or gpg)", tool)
+	}
+	if pathExists(encryptedPath) {
+		return fmt.Errorf("%s already exists", noteRelPath(config, encryptedPath))
+	}
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", name, err)
+	}
+	sealed, err := encryptNote(config, encryptedPath, content)
+	if err != nil {
+		return err
+	}
+	if err := createNoteFile(encryptedPath, sealed); err != nil {
+		return err
+	}
+	if err := os.Remove(notePath); err != nil {
+		return fmt.Errorf("error removing unencrypted %s: %w", name, err)
+	}
+	fmt.Printf("Encrypted %s as %s\n", name, noteRelPath(config, encryptedPath))
+	if config.boolOption("git") {
+		fmt.Println("Earlier versions in the note history are not encrypted.")
+	}
+	autoCommit(config, "Encrypt "+name)
+	return nil
+}
+
+// editEncryptedNote decrypts a note into a private temp directory, opens
+// the copy in the editor and encrypts it back over the note if it changed.
+// The plaintext is removed afterwards, even if the editor fails.
+func editEncryptedNote(config Config, notePath string, line int) error {
+	plain, err := decryptNote(config, notePath)
+	if err != nil {
+		return err
+	}
+	tempDir, err := os.MkdirTemp("", "note-decrypted-")
+	if err != nil {
+		return fmt.Errorf("error creating temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Drop the .age or .gpg so the editor sees a markdown file
+	tempPath := filepath.Join(tempDir, strings.TrimSuffix(filepath.Base(notePath), filepath.Ext(notePath)))
+	if err := os.WriteFile(tempPath, plain, 0600); err != nil {
+		return fmt.Errorf("error writing decrypted copy: %w", err)
+	}
+	if err := openInEditor(editorFor(config, tempPath), tempPath, line); err != nil {
+		return err
+	}
+	edited, err := os.ReadFile(tempPath)
+	if err != nil {
+		return fmt.Errorf("error reading decrypted copy: %w", err)
+	}
+	recordAccess(config.NotesDir, notePath)
+	if bytes.Equal(edited, plain) {
+		return nil
+	}
+
+	sealed, err := encryptNote(config, notePath, edited)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(notePath, sealed, 0600); err != nil {
+		return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+	}
+	autoCommit(config, "Update "+noteRelPath(config, notePath))
+	return nil
+}
+
+// encryptNote encrypts content for the note at notePath, with age or gpg
+// depending on its extension. age encrypts to encrypt_recipients, or to
+// the key in encrypt_identity; gpg to encrypt_recipients or your own key.
+func encryptNote(config Config, notePath string, content []byte) ([]byte, error) {
+	var recipients []string
+	for _, recipient := range strings.Split(config.option("encrypt_recipients"), ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+
+	var args []string
+	switch filepath.Ext(notePath) {
+	case ".age":
+		for _, recipient := range recipients {
+			if path := expandPath(recipient); pathExists(path) {
+				args = append(args, "--recipients-file", path)
+			} else {
+				args = append(args, "--recipient", recipient)
+			}
+		}
+		if len(args) == 0 {
+			identity := config.option("encrypt_identity")
+			if identity == "" {
+				return nil, fmt.Errorf("set encrypt_recipients or encrypt_identity in ~/.note to encrypt with age")
+			}
+			args = []string{"--encrypt", "--identity", expandPath(identity)}
+		}
+		return runCrypt("age", args, content)
+	default:
+		args = []string{"--quiet", "--yes", "--encrypt"}
+		for _, recipient := range recipients {
+			args = append(args, "--recipient", recipient)
+		}
+		if len(recipients) == 0 {
+			args = append(args, "--default-recipient-self")
+		}
+		return runCrypt("gpg", args, content)
+	}
+}
+
+// decryptNote returns the plaintext of an encrypted note, using the age
+// identity in encrypt_identity or gpg's own keys
+func decryptNote(config Config, notePath string) ([]byte, error) {
+	if filepath.Ext(notePath) == ".age" {
+		identity := config.option("encrypt_identity")
+		if identity == "" {
+			return nil, fmt.Errorf("set encrypt_identity in ~/.note to the age identity file that decrypts %s", filepath.Base(notePath))
+		}
+		return runCrypt("age", []string{"--decrypt", "--identity", expandPath(identity), notePath}, nil)
+	}
+	return runCrypt("gpg", []string{"--quiet", "--decrypt", notePath}, nil)
+}
+
+// canDecrypt reports whether encrypted notes can be read without asking
+// for anything: age with an identity file, or gpg with a secret key
+func canDecrypt(config Config) bool {
+	if identity := config.option("encrypt_identity"); identity != "" && pathExists(expandPath(identity)) {
+		if _, err := exec.LookPath("age"); err == nil {
+			return true
+		}
+	}
+	output, err := exec.Command("gpg", "--list-secret-keys", "--with-colons").Output()
+	return err == nil && strings.Contains("\n"+string(output), "\nsec:")
+}
+
+// runCrypt runs age or gpg with input on stdin and returns its output
+func runCrypt(tool string, args []string, input []byte) ([]byte, error) {
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("%s not found in PATH; install it to use encrypted notes", tool)
+	}
+	cmd := exec.Command(tool, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s failed: %s", tool, message)
+		}
+		return nil, fmt.Errorf("%s failed: %w", tool, err)
+	}
+	return output, nil
+}
+
+// readNoteText returns a note's text for commands that only read it: the
+// decrypted content of an encrypted note, or the note with its pending
+// captures merged in
+func readNoteText(config Config, notePath string) ([]byte, error) {
+	if isEncryptedNote(notePath) {
+		return decryptNote(config, notePath)
+	}
+	return readNoteWithCaptures(config, notePath)
+}
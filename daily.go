@@ -0,0 +1,169 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultJournalDir is the notebook daily journal entries live in, one
+// YYYY-MM-DD.md note per day, unless journal_dir says otherwise
+const DefaultJournalDir = "journal"
+
+// DefaultJournalTemplate starts a new journal entry; a custom one can be
+// put in .templates/journal.md
+const DefaultJournalTemplate = `# {{weekday}}, {{date}}
+
+`
+
+// journalEntryName matches journal entry filenames
+var journalEntryName = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}\.md$`)
+
+// relativeDayPattern matches "3 days ago" or "2 weeks ago"
+var relativeDayPattern = regexp.MustCompile(`^(\d+)\s+(day|week)s?\s+ago$`)
+
+// runJournal opens the journal entry for a day, creating it from the
+// journal template, or lists the entries with -l:
+//
+//	note -j [today|yesterday|monday|last friday|3 days ago|2025-05-20]
+//	note -j -l [pattern]
+func runJournal(config Config, flags *ParsedFlags, args []string) error {
+	if flags.List {
+		names := journalEntries(config, strings.Join(args, " "))
+		if len(names) == 0 {
+			fmt.Println("No journal entries yet")
+			return nil
+		}
+		return printNoteList(config, flags, names, strings.Join(args, " "))
+	}
+
+	now := time.Now()
+	day, err := parseJournalDate(strings.Join(args, " "), now)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	notePath, err := journalEntry(config, day)
+	if err != nil {
+		return err
+	}
+
+	wasLocked, err := checkNoteEditable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+	return editNote(config, notePath)
+}
+
+// journalEntry returns the path of the journal entry for day, creating it
+// from the journal template if it doesn't exist yet
+func journalEntry(config Config, day time.Time) (string, error) {
+	dir := journalDir(config)
+	notePath := filepath.Join(dir, day.Format("2006-01-02")+".md")
+	if pathExists(notePath) {
+		return notePath, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating %s: %w", noteRelPath(config, dir), err)
+	}
+	template := DefaultJournalTemplate
+	if custom, err := os.ReadFile(filepath.Join(config.NotesDir, TemplatesDir, "journal.md")); err == nil {
+		template = string(custom)
+	}
+	name := day.Format("2006-01-02")
+	if err := createNoteFile(notePath, []byte(expandTemplate(template, templateVars(name, day)))); err != nil {
+		return "", err
+	}
+	return notePath, nil
+}
+
+// journalDir returns the directory journal entries are kept in
+func journalDir(config Config) string {
+	dir := config.option("journal_dir")
+	if dir == "" {
+		dir = DefaultJournalDir
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(config.NotesDir, dir)
+}
+
+// journalEntries returns the journal entries matching pattern, newest
+// first, named relative to the notes directory
+func journalEntries(config Config, pattern string) []string {
+	dir := journalDir(config)
+	var names []string
+	for _, name := range findMatchingNotes(dir, pattern, false) {
+		if journalEntryName.MatchString(name) {
+			names = append(names, noteRelPath(config, filepath.Join(dir, name)))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names
+}
+
+// parseJournalDate reads the day a journal entry is for: a date
+// (YYYY-MM-DD), today, yesterday, tomorrow, a weekday (the latest one up to
+// today), "last <weekday>" (the one before today) or "N days/weeks ago".
+// An empty spec is today.
+func parseJournalDate(spec string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	spec = strings.ToLower(strings.Join(strings.Fields(spec), " "))
+	switch spec {
+	case "", "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", spec, now.Location()); err == nil {
+		return date, nil
+	}
+	if match := relativeDayPattern.FindStringSubmatch(spec); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		if match[2] == "week" {
+			n *= 7
+		}
+		return today.AddDate(0, 0, -n), nil
+	}
+	weekday, last := spec, false
+	if rest, ok := strings.CutPrefix(spec, "last "); ok {
+		weekday, last = rest, true
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if weekday != name && weekday != name[:3] {
+			continue
+		}
+		back := (int(today.Weekday()) - int(day) + 7) % 7
+		if back == 0 && last {
+			back = 7
+		}
+		return today.AddDate(0, 0, -back), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown date '%s' (use e.g. yesterday, monday, last friday, 3 days ago or 2025-05-20)", spec)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// diagramRenderers are the external tools that turn a diagram block into
+// SVG, by fence language. {in} and {out} are replaced with the source and
+// SVG paths.
+var diagramRenderers = map[string][]string{
+	"mermaid":  {"mmdc", "-i", "{in}", "-o", "{out}"},
+	"dot":      {"dot", "-Tsvg", "-o", "{out}", "{in}"},
+	"graphviz": {"dot", "-Tsvg", "-o", "{out}", "{in}"},
+}
+
+// runDiagrams writes a copy of a note to a directory with its mermaid
+// and dot blocks rendered to SVG images next to it:
+//
+//	note --diagrams architecture --out ./site
+func runDiagrams(config Config, args []string) error {
+	var outDir string
+	var nameParts []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--out" && i+1 < len(args) {
+			i++
+			outDir = args[i]
+			continue
+		}
+		nameParts = append(nameParts, args[i])
+	}
+	if outDir == "" || len(nameParts) == 0 {
+		return usageErrorf("usage: note --diagrams <name> --out <dir>")
+	}
+
+	notePath, err := existingNotePath(config, strings.Join(nameParts, " "))
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %w", outDir, err)
+	}
+
+	base := strings.TrimSuffix(filepath.Base(notePath), ".md")
+	rendered, count := renderDiagrams(expandEmoji(config, string(content)), outDir, base)
+	outPath := filepath.Join(outDir, base+".md")
+	if err := writeFileAtomic(outPath, []byte(rendered), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", outPath, err)
+	}
+	fmt.Printf("Wrote %s with %d rendered diagram(s)\n", outPath, count)
+	return nil
+}
+
+// renderDiagrams renders the diagram blocks in content to outDir as
+// <base>-diagram-N.svg and replaces each with an image link to its SVG. A
+// block whose renderer isn't installed or fails is kept as code, with a
+// warning, so nothing is lost on the way out.
+func renderDiagrams(content, outDir, base string) (string, int) {
+	lines := splitLines(content)
+	blocks := codeBlocks(lines)
+	warned := make(map[string]bool)
+
+	type replacement struct {
+		start, end int
+		image      string
+	}
+	var replacements []replacement
+	for _, block := range blocks {
+		renderer, ok := diagramRenderers[block.Lang]
+		if !ok || block.End >= len(lines) {
+			continue
+		}
+		if _, err := exec.LookPath(renderer[0]); err != nil {
+			if !warned[renderer[0]] {
+				fmt.Fprintf(os.Stderr, "Warning: %s not found; leaving %s diagrams as code\n", renderer[0], block.Lang)
+				warned[renderer[0]] = true
+			}
+			continue
+		}
+
+		name := fmt.Sprintf("%s-diagram-%d.svg", base, len(replacements)+1)
+		source := strings.Join(lines[block.Start+1:block.End], "\n") + "\n"
+		if err := renderDiagram(renderer, source, filepath.Join(outDir, name)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not render %s diagram on line %d: %v\n", block.Lang, block.Start+1, err)
+			continue
+		}
+		replacements = append(replacements, replacement{block.Start, block.End, fmt.Sprintf("![%s diagram](%s)", block.Lang, name)})
+	}
+
+	// Replace from the bottom up so earlier line numbers stay valid
+	for i := len(replacements) - 1; i >= 0; i-- {
+		r := replacements[i]
+		lines = append(lines[:r.start], append([]string{r.image}, lines[r.end+1:]...)...)
+	}
+	return joinLines(lines), len(replacements)
+}
+
+// renderDiagram runs renderer on source, writing the SVG to outPath
+func renderDiagram(renderer []string, source, outPath string) error {
+	sourceFile, err := os.CreateTemp("", "note-diagram-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(sourceFile.Name())
+	if _, err := sourceFile.WriteString(source); err != nil {
+		sourceFile.Close()
+		return err
+	}
+	sourceFile.Close()
+
+	args := make([]string, 0, len(renderer)-1)
+	for _, arg := range renderer[1:] {
+		arg = strings.ReplaceAll(arg, "{in}", sourceFile.Name())
+		args = append(args, strings.ReplaceAll(arg, "{out}", outPath))
+	}
+	output, err := exec.Command(renderer[0], args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %v: %s", renderer[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffContext is how many unchanged lines surround each change in --diff
+const DiffContext = 3
+
+// diffOp is one line of a diff: kept (' '), removed ('-') or added ('+')
+type diffOp struct {
+	Kind byte
+	Text string
+}
+
+// runDiff prints a unified diff between two notes, colored on a terminal,
+// e.g. to compare minutes two people took of the same meeting:
+//
+//	note --diff <noteA> <noteB>
+func runDiff(config Config, args []string) error {
+	if len(args) != 2 {
+		return usageErrorf("usage: note --diff <noteA> <noteB>")
+	}
+	var names [2]string
+	var lines [2][]string
+	for i, name := range args {
+		notePath, err := existingNotePath(config, name)
+		if err != nil {
+			return err
+		}
+		content, err := readNoteText(config, notePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", noteRelPath(config, notePath), err)
+		}
+		names[i] = noteRelPath(config, notePath)
+		lines[i] = splitLines(string(content))
+	}
+
+	hunks := unifiedDiff(diffLines(lines[0], lines[1]), DiffContext)
+	if len(hunks) == 0 {
+		return nil
+	}
+	color := isOutputToTerminal()
+	paint := func(code, line string) string {
+		if color {
+			return code + line + ColorReset
+		}
+		return line
+	}
+	fmt.Println(paint(ColorRed, "--- "+names[0]))
+	fmt.Println(paint(ColorGreen, "+++ "+names[1]))
+	for _, line := range hunks {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			line = paint(ColorCyan, line)
+		case strings.HasPrefix(line, "-"):
+			line = paint(ColorRed, line)
+		case strings.HasPrefix(line, "+"):
+			line = paint(ColorGreen, line)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// diffLines returns the shortest edit turning a into b, found with Myers'
+// algorithm
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+
+	// Find how far each diagonal k = x-y gets with d edits, keeping every
+	// round so the path can be walked back
+	found := false
+	for d := 0; d <= n+m && !found; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x, y = x-1, y-1
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{'+', b[y-1]})
+			} else {
+				ops = append(ops, diffOp{'-', a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// unifiedDiff groups the changes in ops into hunks with context unchanged
+// lines around them, each under an "@@ -a,n +b,m @@" header. It returns
+// nothing when nothing changed.
+func unifiedDiff(ops []diffOp, context int) []string {
+	// Line numbers in each file before each op
+	aLine, bLine := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, op := range ops {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if op.Kind != '+' {
+			aLine[i+1]++
+		}
+		if op.Kind != '-' {
+			bLine[i+1]++
+		}
+	}
+
+	var out []string
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == ' ' {
+			i++
+			continue
+		}
+		// Changes closer than twice the context share a hunk
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].Kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		start, stop := max(0, i-context), min(len(ops), end+context)
+		out = append(out, fmt.Sprintf("@@ -%s +%s @@",
+			hunkRange(aLine[start], aLine[stop]-aLine[start]),
+			hunkRange(bLine[start], bLine[stop]-bLine[start])))
+		for _, op := range ops[start:stop] {
+			out = append(out, string(op.Kind)+op.Text)
+		}
+		i = stop
+	}
+	return out
+}
+
+// hunkRange formats the lines of one file a hunk covers, starting after
+// line before; an empty range names the line it follows
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// editorFor picks the editor for a note: an editor: key in its frontmatter,
+// then an [editors] rule for its extension, then one for its notebook
+// (innermost first), and otherwise the editor setting
+func editorFor(config Config, notePath string) string {
+	if content, err := os.ReadFile(notePath); err == nil {
+		if editor := parseFrontmatter(string(content))["editor"]; editor != "" {
+			if _, err := exec.LookPath(editor); err == nil {
+				return editor
+			}
+			fmt.Fprintf(os.Stderr, "Warning: editor '%s' from %s was not found; using %s\n", editor, filepath.Base(notePath), config.Editor)
+		}
+	}
+
+	if ext := strings.ToLower(filepath.Ext(notePath)); ext != "" {
+		if editor := config.Editors[ext]; editor != "" {
+			return editor
+		}
+	}
+
+	rel, err := filepath.Rel(config.NotesDir, filepath.Dir(notePath))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return config.Editor
+	}
+	for dir := filepath.ToSlash(rel); dir != "."; dir = path.Dir(dir) {
+		if editor := config.Editors[dir+"/"]; editor != "" {
+			return editor
+		}
+	}
+	return config.Editor
+}
+
+// editorArgs returns the arguments that open path in editor with the cursor
+// on line. Editors without a known way to jump to a line just get the path,
+// so an unfamiliar one never sees an argument it might take for a filename.
+func editorArgs(editor, path string, line int) []string {
+	if line <= 0 {
+		return []string{path}
+	}
+	switch strings.TrimSuffix(filepath.Base(editor), ".exe") {
+	case "vi", "vim", "nvim", "gvim", "view", "nano", "emacs", "emacsclient", "kak", "mg", "joe", "ne", "jed":
+		return []string{fmt.Sprintf("+%d", line), path}
+	case "code", "code-insiders", "codium":
+		return []string{"-g", fmt.Sprintf("%s:%d", path, line)}
+	case "subl", "zed", "hx", "helix", "micro":
+		return []string{fmt.Sprintf("%s:%d", path, line)}
+	}
+	return []string{path}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// emojiShortcode matches ":rocket:"-style shortcodes
+var emojiShortcode = regexp.MustCompile(`:([a-z0-9_+\-]+):`)
+
+// emojiShortcodes are the GitHub-style shortcodes expanded when emoji is
+// enabled; unknown shortcodes are left as typed
+var emojiShortcodes = map[string]string{
+	"+1": "👍", "-1": "👎", "100": "💯", "alarm_clock": "⏰", "apple": "🍎", "arrow_down": "⬇️",
+	"arrow_left": "⬅️", "arrow_right": "➡️", "arrow_up": "⬆️", "beer": "🍺", "bell": "🔔",
+	"bird": "🐦", "books": "📚", "boom": "💥", "bug": "🐛", "bulb": "💡", "calendar": "📆",
+	"cat": "🐱", "chart_with_upwards_trend": "📈", "chart_with_downwards_trend": "📉",
+	"clap": "👏", "clipboard": "📋", "cloud": "☁️", "coffee": "☕", "computer": "💻",
+	"confused": "😕", "construction": "🚧", "cry": "😢", "dart": "🎯", "dog": "🐶",
+	"email": "📧", "exclamation": "❗", "eyes": "👀", "fire": "🔥", "flag": "🚩",
+	"gear": "⚙️", "gift": "🎁", "grin": "😁", "heart": "❤️", "heavy_check_mark": "✔️",
+	"hourglass": "⌛", "house": "🏠", "hugs": "🤗", "idea": "💡", "info": "ℹ️", "key": "🔑",
+	"laughing": "😆", "link": "🔗", "lock": "🔒", "mag": "🔍", "memo": "📝", "money": "💰",
+	"moon": "🌙", "muscle": "💪", "no_entry": "⛔", "ok": "🆗", "ok_hand": "👌",
+	"package": "📦", "paperclip": "📎", "party": "🥳", "pencil": "✏️", "phone": "📞",
+	"pin": "📌", "pray": "🙏", "pushpin": "📌", "question": "❓", "rainbow": "🌈",
+	"recycle": "♻️", "rocket": "🚀", "rotating_light": "🚨", "scream": "😱", "seedling": "🌱",
+	"shrug": "🤷", "skull": "💀", "sleeping": "😴", "smile": "😄", "smiley": "😃",
+	"snowflake": "❄️", "sparkles": "✨", "star": "⭐", "stop_sign": "🛑", "sunny": "☀️",
+	"sweat_smile": "😅", "tada": "🎉", "thinking": "🤔", "thumbsdown": "👎", "thumbsup": "👍",
+	"trophy": "🏆", "umbrella": "☂️", "unlock": "🔓", "warning": "⚠️", "wave": "👋",
+	"white_check_mark": "✅", "wink": "😉", "wrench": "🔧", "x": "❌", "zap": "⚡",
+}
+
+// expandEmoji replaces known shortcodes with their emoji when emoji is
+// enabled, leaving code untouched
+func expandEmoji(config Config, content string) string {
+	if !config.boolOption("emoji") {
+		return content
+	}
+	lines := splitLines(content)
+	code := codeLines(lines)
+	for i := range lines {
+		if code[i] {
+			continue
+		}
+		lines[i] = outsideInlineCode(lines[i], func(text string) string {
+			return emojiShortcode.ReplaceAllStringFunc(text, func(shortcode string) string {
+				if emoji, ok := emojiShortcodes[strings.Trim(shortcode, ":")]; ok {
+					return emoji
+				}
+				return shortcode
+			})
+		})
+	}
+	if !strings.HasSuffix(content, "\n") {
+		return strings.TrimSuffix(joinLines(lines), "\n")
+	}
+	return joinLines(lines)
+}
+
+// runCompleteEmoji prints the shortcodes starting with prefix and their
+// emoji, for shell and editor completion while appending
+func runCompleteEmoji(prefix string) error {
+	for _, name := range emojiCandidates(prefix) {
+		fmt.Printf(":%s:\t%s\n", name, emojiShortcodes[name])
+	}
+	return nil
+}
+
+func emojiCandidates(prefix string) []string {
+	prefix = strings.TrimPrefix(prefix, ":")
+	var names []string
+	for name := range emojiShortcodes {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,86 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Exit codes
+const (
+	ExitOK          = 0
+	ExitFailure     = 1   // the command failed
+	ExitUsage       = 2   // note was run with bad flags or arguments
+	ExitInterrupted = 130 // stopped with Ctrl-C, as shells report SIGINT
+)
+
+// errInterrupted is wrapped by errors from commands stopped with Ctrl-C
+var errInterrupted = errors.New("interrupted")
+
+// exitError carries the exit code for an error that should not exit with
+// ExitFailure
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// usageErrorf reports a mistake in how note was run
+func usageErrorf(format string, args ...any) error {
+	return &exitError{code: ExitUsage, err: fmt.Errorf(format, args...)}
+}
+
+// exitCode returns the exit code for err
+func exitCode(err error) int {
+	var exit *exitError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &exit):
+		return exit.code
+	case errors.Is(err, errInterrupted), errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	}
+	return ExitFailure
+}
+
+// jsonOutput is set by --json: errors are reported on stderr as a JSON
+// object, {"error": "...", "code": 1}, and -l/-a/-s print JSON, for scripts
+// and editor plugins
+var jsonOutput bool
+
+// reportError prints err for the user, or as JSON with --json, and returns
+// the exit code for it
+func reportError(err error) int {
+	code := exitCode(err)
+	if jsonOutput {
+		json.NewEncoder(os.Stderr).Encode(struct {
+			Error string `json:"error"`
+			Code  int    `json:"code"`
+		}{err.Error(), code})
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	return code
+}
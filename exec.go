@@ -0,0 +1,227 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OutputFenceLang marks the block --exec --write puts beneath a code block;
+// it is replaced rather than duplicated when the block is run again
+const OutputFenceLang = "output"
+
+// blockInterpreters maps fence languages to the command that runs them
+var blockInterpreters = map[string][]string{
+	"sh":      {"sh", "-c"},
+	"shell":   {"sh", "-c"},
+	"bash":    {"bash", "-c"},
+	"zsh":     {"zsh", "-c"},
+	"python":  {"python3", "-c"},
+	"python3": {"python3", "-c"},
+	"py":      {"python3", "-c"},
+}
+
+// codeBlock is a fenced code block in a note
+type codeBlock struct {
+	Lang       string
+	Start, End int // lines of the opening and closing fences
+}
+
+// runExec runs a fenced code block from a note, numbering blocks from 1:
+//
+//	note --exec runbook                 list the blocks
+//	note --exec runbook --block 2       run block 2
+//	note --exec runbook --block 2 --write
+//	                                    run it and write the output beneath it
+func runExec(config Config, flags *ParsedFlags, args []string) error {
+	var name string
+	number, write := 0, false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--block":
+			if i+1 >= len(args) {
+				return usageErrorf("--block requires a block number")
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid block number '%s'", args[i])
+			}
+			number = n
+		case "--write":
+			write = true
+		default:
+			name = strings.TrimSpace(name + " " + args[i])
+		}
+	}
+
+	notePath, err := existingNotePath(config, name)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+	lines := splitLines(string(content))
+	blocks := codeBlocks(lines)
+
+	if number == 0 {
+		if len(blocks) == 0 {
+			fmt.Printf("%s has no code blocks\n", filepath.Base(notePath))
+		}
+		for i, block := range blocks {
+			first := ""
+			if block.Start+1 < block.End {
+				first = strings.TrimSpace(lines[block.Start+1])
+			}
+			fmt.Printf("%3d  %-8s %s\n", i+1, block.Lang, first)
+		}
+		return nil
+	}
+	if number > len(blocks) {
+		return fmt.Errorf("%s has %d code block(s)", filepath.Base(notePath), len(blocks))
+	}
+
+	block := blocks[number-1]
+	interpreter, ok := blockInterpreters[block.Lang]
+	if !ok {
+		return fmt.Errorf("don't know how to run '%s' blocks (use sh, bash, zsh or python)", block.Lang)
+	}
+
+	code := strings.Join(lines[block.Start+1:block.End], "\n")
+	var output bytes.Buffer
+	cmd := exec.Command(interpreter[0], append(interpreter[1:], code)...)
+	cmd.Dir = config.NotesDir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &output)
+	runErr := cmd.Run()
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return fmt.Errorf("error running block %d: %w", number, runErr)
+	}
+
+	if write {
+		result := output.String()
+		if exitErr != nil {
+			result = strings.TrimRight(result, "\n") + "\n[" + exitErr.Error() + "]"
+		}
+		if err := writeBlockOutput(config, flags, notePath, number, result); err != nil {
+			return err
+		}
+	}
+	if exitErr != nil {
+		return fmt.Errorf("block %d failed: %v", number, exitErr)
+	}
+	return nil
+}
+
+// writeBlockOutput puts output in an output block beneath code block number,
+// replacing the one left by an earlier run
+func writeBlockOutput(config Config, flags *ParsedFlags, notePath string, number int, output string) error {
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	return withNotesLock(config.NotesDir, func() error {
+		// Re-read in case the note changed while the block was running
+		content, err := os.ReadFile(notePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+		}
+		lines := splitLines(string(content))
+		blocks := codeBlocks(lines)
+		if number > len(blocks) {
+			return fmt.Errorf("block %d disappeared from %s while it ran", number, filepath.Base(notePath))
+		}
+		updated := insertBlockOutput(lines, blocks, number-1, output)
+		if err := writeFileAtomic(notePath, []byte(joinLines(updated)), notePerm(notePath)); err != nil {
+			return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+		}
+		postSave(config, notePath)
+		return nil
+	})
+}
+
+// insertBlockOutput returns lines with output written beneath blocks[index],
+// separated by a blank line. An output block already there is replaced.
+func insertBlockOutput(lines []string, blocks []codeBlock, index int, output string) []string {
+	body := strings.TrimRight(output, "\n")
+	fence := fenceFor(body)
+	outputLines := []string{"", fence + OutputFenceLang}
+	if body != "" {
+		outputLines = append(outputLines, strings.Split(body, "\n")...)
+	}
+	outputLines = append(outputLines, fence)
+
+	from := min(blocks[index].End+1, len(lines))
+	to := from
+	if index+1 < len(blocks) && blocks[index+1].Lang == OutputFenceLang {
+		// Only replace an output block that directly follows, after blank lines
+		next := blocks[index+1]
+		between := lines[from:next.Start]
+		if strings.TrimSpace(strings.Join(between, "")) == "" {
+			to = next.End + 1
+		}
+	}
+
+	updated := append([]string{}, lines[:from]...)
+	updated = append(updated, outputLines...)
+	return append(updated, lines[to:]...)
+}
+
+// codeBlocks finds the fenced code blocks in lines. A block is closed by a
+// fence at least as long as the one that opened it; an unclosed block runs
+// to the end of the note.
+func codeBlocks(lines []string) []codeBlock {
+	var blocks []codeBlock
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(trimmed, "```") {
+			continue
+		}
+		fence := trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, "`"))]
+		lang := strings.ToLower(strings.TrimSpace(strings.TrimLeft(trimmed, "`")))
+		if fields := strings.Fields(lang); len(fields) > 0 {
+			lang = fields[0]
+		}
+
+		block := codeBlock{Lang: lang, Start: i, End: len(lines)}
+		for j := i + 1; j < len(lines); j++ {
+			closing := strings.TrimSpace(lines[j])
+			if strings.HasPrefix(closing, fence) && strings.Trim(closing, "`") == "" {
+				block.End = j
+				break
+			}
+		}
+		blocks = append(blocks, block)
+		i = block.End
+	}
+	return blocks
+}
@@ -0,0 +1,261 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTTL is how long a --tmp note lives unless --ttl or tmp_ttl is set
+const DefaultTTL = "7d"
+
+// Formats of the expires: frontmatter value; a date expires at its start
+var expiryFormats = []string{"2006-01-02 15:04", "2006-01-02"}
+
+// parseTTL parses a lifetime such as 12h, 7d, 2w or 6m, counting a month
+// as 30 days and a year (1y) as 365
+func parseTTL(spec string) (time.Duration, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	day := 24 * time.Hour
+	units := map[byte]time.Duration{'h': time.Hour, 'd': day, 'w': 7 * day, 'm': 30 * day, 'y': 365 * day}
+	if len(spec) < 2 {
+		return 0, fmt.Errorf("invalid ttl '%s' (use e.g. 12h, 7d, 2w or 6m)", spec)
+	}
+	unit, ok := units[spec[len(spec)-1]]
+	n, err := strconv.Atoi(spec[:len(spec)-1])
+	if !ok || err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid ttl '%s' (use e.g. 12h, 7d, 2w or 6m)", spec)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// parseExpiry parses an expires: value
+func parseExpiry(value string) (time.Time, error) {
+	for _, format := range expiryFormats {
+		if expiry, err := time.ParseInLocation(format, value, time.Local); err == nil {
+			return expiry, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid expires date '%s' (use YYYY-MM-DD)", value)
+}
+
+// formatExpiry writes the expiry for a note living ttl from now, to the day
+// unless the ttl is shorter than one
+func formatExpiry(now time.Time, ttl time.Duration) string {
+	expiry := now.Add(ttl)
+	if ttl < 24*time.Hour {
+		return expiry.Format(expiryFormats[0])
+	}
+	return expiry.Format(expiryFormats[1])
+}
+
+// runTmp opens a scratch note that expires, creating it with an expires:
+// frontmatter date first:
+//
+//	note --tmp <name> [--ttl 7d]
+func runTmp(config Config, flags *ParsedFlags, args []string) error {
+	spec := config.option("tmp_ttl")
+	if spec == "" {
+		spec = DefaultTTL
+	}
+	var words []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--ttl" {
+			if i+1 >= len(args) {
+				return usageErrorf("--ttl requires a lifetime, e.g. 7d")
+			}
+			i++
+			spec = args[i]
+			continue
+		}
+		words = append(words, args[i])
+	}
+	name := strings.Join(words, " ")
+	if name == "" {
+		return usageErrorf("usage: note --tmp <name> [--ttl 7d]")
+	}
+	ttl, err := parseTTL(spec)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	now := time.Now()
+	notePath := filepath.Join(config.NotesDir, datedNoteFilename(name, now))
+	if !pathExists(notePath) {
+		body := setFrontmatterValue("", "expires", formatExpiry(now, ttl))
+		if flags.Context {
+			body = withOrigin(body)
+		}
+		if err := createNoteFile(notePath, []byte(body)); err != nil {
+			return err
+		}
+	}
+
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+	return editNote(config, notePath)
+}
+
+// expiredNotes returns the notes outside the archive whose expires: date
+// has passed
+func expiredNotes(config Config, now time.Time) []string {
+	archiveDir := getArchiveDir(config.NotesDir)
+	var expired []string
+	walkNotes(config.NotesDir, func(path string, info os.FileInfo) error {
+		if strings.HasPrefix(path, archiveDir+string(os.PathSeparator)) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		value := parseFrontmatter(string(content))["expires"]
+		if value == "" {
+			return nil
+		}
+		expiry, err := parseExpiry(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", journalName(config.NotesDir, path), err)
+			return nil
+		}
+		if !now.Before(expiry) {
+			expired = append(expired, path)
+		}
+		return nil
+	})
+	return expired
+}
+
+// runExpire archives, or with --delete (or expire_action=delete) moves to the
+// trash, every note whose expires: date has passed. It never prompts and prints
+// nothing when no note has expired, so it is safe to run from cron.
+//
+//	note --expire [--delete] [--dry-run]
+func runExpire(config Config, flags *ParsedFlags, args []string) error {
+	remove := config.option("expire_action") == "delete"
+	dryRun := false
+	for _, arg := range args {
+		switch arg {
+		case "--delete":
+			remove = true
+		case "--dry-run":
+			dryRun = true
+		default:
+			return usageErrorf("usage: note --expire [--delete] [--dry-run]")
+		}
+	}
+
+	expired := expiredNotes(config, time.Now())
+	if len(expired) == 0 {
+		return nil
+	}
+
+	if dryRun {
+		verb := "archive"
+		if remove {
+			verb = "delete"
+		}
+		for _, path := range expired {
+			fmt.Printf("Would %s %s\n", verb, journalName(config.NotesDir, path))
+		}
+		return nil
+	}
+	var err error
+	if remove {
+		err = deleteExpired(config, flags, expired)
+	} else {
+		err = archiveExpired(config, expired)
+	}
+	autoCommit(config, fmt.Sprintf("Expire %d note(s)", len(expired)))
+	return err
+}
+
+// deleteExpired moves expired notes to the trash like --delete; locked ones
+// need --force
+func deleteExpired(config Config, flags *ParsedFlags, expired []string) error {
+	var names []string
+	for _, path := range expired {
+		name := journalName(config.NotesDir, path)
+		if isNoteLocked(path) && !flags.Force {
+			fmt.Fprintf(os.Stderr, "Warning: %s is locked; use --force to delete it\n", name)
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	if err := trashNotes(config, names, time.Now(), flags.Force); err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Printf("Moved %s to %s\n", name, TrashDir)
+	}
+	return nil
+}
+
+// archiveExpired moves expired notes into the archive under the notes
+// lock, journaled like -d so an interrupted run can be finished or undone
+// with --repair
+func archiveExpired(config Config, expired []string) error {
+	return withNotesLock(config.NotesDir, func() error {
+		archiveDir := getArchiveDir(config.NotesDir)
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			return fmt.Errorf("error creating archive directory: %w", err)
+		}
+
+		var steps []journalStep
+		for _, path := range expired {
+			to := filepath.Join(archiveDir, filepath.Base(path))
+			if pathExists(to) {
+				fmt.Fprintf(os.Stderr, "Warning: not archiving %s: %s already exists\n", journalName(config.NotesDir, path), journalName(config.NotesDir, to))
+				continue
+			}
+			steps = append(steps, journalStep{From: path, To: to})
+		}
+		if len(steps) == 0 {
+			return nil
+		}
+		if err := beginJournal(config.NotesDir, "expire", steps); err != nil {
+			return err
+		}
+
+		failed := false
+		for _, step := range steps {
+			if err := moveNote(step.From, step.To); err != nil {
+				fmt.Fprintf(os.Stderr, "Error archiving %s: %v\n", journalName(config.NotesDir, step.From), err)
+				failed = true
+				continue
+			}
+			fmt.Printf("Archived %s\n", journalName(config.NotesDir, step.From))
+		}
+		if failed {
+			return fmt.Errorf("some expired notes could not be archived; run 'note --repair' to retry or undo")
+		}
+		finishJournal(config.NotesDir)
+		return nil
+	})
+}
@@ -0,0 +1,490 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"note/pkg/notes"
+)
+
+// exportedNote is a note being written out by --export
+type exportedNote struct {
+	Name    string // filename in the notes directory
+	Title   string
+	Date    time.Time
+	Content string
+}
+
+// runExport renders the notes matching a pattern for reading outside note:
+//
+//	note --export html [pattern] --out ./site
+//	note --export pdf [pattern] --out ./pdfs
+//
+// html writes a page per note and an index.html listing them, newest first.
+// Links between exported notes, as [[wiki-links]] or relative .md links,
+// point at the exported pages. pdf writes a PDF per note with --print's
+// renderer or, when pdf_command is set (e.g. wkhtmltopdf {in} {out}),
+// converts the HTML pages with that tool, which keeps the links working.
+func runExport(config Config, args []string) error {
+	var outDir string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--out" {
+			if i+1 >= len(args) {
+				return usageErrorf("--out requires a directory")
+			}
+			i++
+			outDir = args[i]
+			continue
+		}
+		rest = append(rest, args[i])
+	}
+	if len(rest) == 0 || (rest[0] != "html" && rest[0] != "pdf") || outDir == "" {
+		return usageErrorf("usage: note --export html|pdf [pattern] --out <dir>")
+	}
+	format, pattern := rest[0], strings.Join(rest[1:], " ")
+
+	names := findMatchingNotes(config.NotesDir, pattern, false)
+	if len(names) == 0 {
+		return fmt.Errorf("no notes match '%s'", pattern)
+	}
+	var exported []exportedNote
+	for _, name := range names {
+		notePath := filepath.Join(config.NotesDir, name)
+		content, err := readNoteWithCaptures(config, notePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", name, err)
+		}
+		info, err := os.Stat(notePath)
+		if err != nil {
+			return err
+		}
+		title := noteTitle(string(content))
+		if title == "" {
+			title = strings.TrimSuffix(name, ".md")
+		}
+		exported = append(exported, exportedNote{Name: name, Title: title, Date: noteDate(name, info.ModTime()), Content: expandEmoji(config, string(content))})
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %w", outDir, err)
+	}
+
+	switch command := strings.Fields(config.option("pdf_command")); {
+	case format == "html":
+		if err := writeExportPages(exported, outDir, ".html"); err != nil {
+			return err
+		}
+	case len(command) > 0:
+		if err := exportPDFWith(command, exported, outDir); err != nil {
+			return err
+		}
+	default:
+		if err := exportPDF(exported, outDir); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Exported %d note(s) to %s\n", len(exported), outDir)
+	return nil
+}
+
+// writeExportPages writes an HTML page per note and the index to dir, with
+// links between them ending in ext (the extension of the final files)
+func writeExportPages(exported []exportedNote, dir, ext string) error {
+	href := exportHref(exported, ext)
+	for _, note := range exported {
+		body := markdownHTML(note.Content, href)
+		page := exportPage(note.Title, fmt.Sprintf("<nav><a href=\"index%s\">Index</a></nav>\n<article>\n%s</article>\n", ext, body))
+		if err := writeExportFile(dir, exportStem(note.Name)+".html", page); err != nil {
+			return err
+		}
+	}
+
+	byDate := append([]exportedNote{}, exported...)
+	sort.SliceStable(byDate, func(i, j int) bool { return byDate[i].Date.After(byDate[j].Date) })
+	var index strings.Builder
+	index.WriteString("<h1>Notes</h1>\n<ul>\n")
+	for _, note := range byDate {
+		fmt.Fprintf(&index, "<li><a href=\"%s\">%s</a> <time>%s</time></li>\n",
+			html.EscapeString(href(note.Name)), html.EscapeString(note.Title), note.Date.Format("2006-01-02"))
+	}
+	index.WriteString("</ul>\n")
+	return writeExportFile(dir, "index.html", exportPage("Notes", index.String()))
+}
+
+// exportPDF writes each note to dir as a PDF laid out like --print's
+func exportPDF(exported []exportedNote, dir string) error {
+	for _, note := range exported {
+		var pdf bytes.Buffer
+		pages := printPages(note.Title, note.Date, note.Name, renderForPrint(note.Content))
+		if err := writePrintPDF(&pdf, pages); err != nil {
+			return err
+		}
+		if err := writeExportFile(dir, exportStem(note.Name)+".pdf", pdf.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportPDFWith converts the notes' HTML pages, and the index, to PDFs in
+// dir with an external tool. {in} and {out} in the command are replaced
+// with the HTML and PDF paths, which are otherwise passed in that order.
+func exportPDFWith(command []string, exported []exportedNote, dir string) error {
+	tempDir, err := os.MkdirTemp("", "note-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	if err := writeExportPages(exported, tempDir, ".pdf"); err != nil {
+		return err
+	}
+
+	stems := []string{"index"}
+	for _, note := range exported {
+		stems = append(stems, exportStem(note.Name))
+	}
+	for _, stem := range stems {
+		in, out := filepath.Join(tempDir, stem+".html"), filepath.Join(dir, stem+".pdf")
+		args := append([]string{}, command[1:]...)
+		placed := false
+		for i, arg := range args {
+			if strings.Contains(arg, "{in}") || strings.Contains(arg, "{out}") {
+				args[i] = strings.NewReplacer("{in}", in, "{out}", out).Replace(arg)
+				placed = true
+			}
+		}
+		if !placed {
+			args = append(args, in, out)
+		}
+		cmd := exec.Command(command[0], args...)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s failed on %s: %w", command[0], stem, err)
+		}
+	}
+	return nil
+}
+
+// exportStem is the name of a note's exported files, without an extension
+func exportStem(name string) string {
+	return strings.TrimSuffix(name, ".md")
+}
+
+func writeExportFile(dir, name, content string) error {
+	outPath := filepath.Join(dir, name)
+	if err := writeFileAtomic(outPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", outPath, err)
+	}
+	return nil
+}
+
+// exportPage wraps body in a standalone HTML page
+func exportPage(title, body string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%s</title>
+<style>
+body { max-width: 46em; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.5; }
+pre { background: #f5f5f5; padding: 0.75em; overflow-x: auto; }
+code { font-size: 0.9em; }
+blockquote { margin-left: 0; padding-left: 1em; border-left: 3px solid #ccc; color: #555; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.5em; }
+li:has(> input[type=checkbox]) { list-style: none; }
+time, .missing { color: #888; }
+</style>
+</head><body>
+%s</body></html>
+`, html.EscapeString(title), body)
+}
+
+// exportHref returns a function mapping a link target, a note's filename
+// or a wiki-link's note name, to the page exported for it with extension
+// ext, or to "" when that note isn't being exported. A wiki-link naming a
+// dated note without its date, as in [[standup]], points at the newest.
+func exportHref(exported []exportedNote, ext string) func(string) string {
+	exact := make(map[string]string)
+	dated := make(map[string]string)
+	for _, note := range exported {
+		page := (&url.URL{Path: exportStem(note.Name) + ext}).String()
+		exact[strings.ToLower(exportStem(note.Name))] = page
+		if undated := dateStamp.ReplaceAllString(note.Name, ".md"); undated != note.Name {
+			// Names sort by date, so the newest note is seen last
+			dated[strings.ToLower(exportStem(undated))] = page
+		}
+	}
+	return func(target string) string {
+		key := strings.ToLower(strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(target, "./"), ".md"), " ", "_"))
+		if page, ok := exact[key]; ok {
+			return page
+		}
+		return dated[key]
+	}
+}
+
+var (
+	exportListItem  = regexp.MustCompile(`^(\s*)([-*+]|(\d+)[.)])\s+(.*)$`)
+	exportRule      = regexp.MustCompile(`^(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	exportTableRule = regexp.MustCompile(`^\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?$`)
+)
+
+// exportList is a list open while rendering HTML
+type exportList struct {
+	indent int
+	tag    string
+}
+
+// markdownHTML renders a note as HTML: headings, with the anchors links to
+// them use, paragraphs, nested lists and checklists, quotes, fenced code,
+// tables and rules, and the inline markup of inlineHTML. Raw HTML in the
+// note is escaped rather than passed through.
+func markdownHTML(content string, href func(string) string) string {
+	lines := splitLines(content)
+	anchors := make(map[int]string)
+	for _, heading := range notes.Headings(lines) {
+		anchors[heading.Line] = heading.Anchor
+	}
+	fences := make(map[int]codeBlock)
+	for _, block := range codeBlocks(lines) {
+		fences[block.Start] = block
+	}
+
+	var b strings.Builder
+	var paragraph []string
+	var lists []exportList
+	inItem := false // paragraph is a list item's own text, written without <p>
+	flush := func() {
+		if len(paragraph) > 0 {
+			text := inlineHTML(strings.Join(paragraph, "\n"), href)
+			if inItem {
+				b.WriteString(text + "\n")
+			} else {
+				fmt.Fprintf(&b, "<p>%s</p>\n", text)
+			}
+		}
+		paragraph, inItem = nil, false
+	}
+	closeLists := func(indent int) {
+		for len(lists) > 0 && lists[len(lists)-1].indent >= indent {
+			fmt.Fprintf(&b, "</li></%s>\n", lists[len(lists)-1].tag)
+			lists = lists[:len(lists)-1]
+		}
+	}
+
+	for i := frontmatterEnd(lines); i < len(lines); i++ {
+		line := strings.ReplaceAll(lines[i], "\t", "    ")
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if block, ok := fences[i]; ok {
+			flush()
+			closeLists(0)
+			class := ""
+			if block.Lang != "" {
+				class = fmt.Sprintf(" class=\"language-%s\"", html.EscapeString(block.Lang))
+			}
+			code := ""
+			if end := min(block.End, len(lines)); i+1 < end {
+				code = strings.Join(lines[i+1:end], "\n") + "\n"
+			}
+			fmt.Fprintf(&b, "<pre><code%s>%s</code></pre>\n", class, html.EscapeString(code))
+			i = block.End
+			continue
+		}
+
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		if level := headingLevel(line); level > 0 {
+			flush()
+			closeLists(0)
+			text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+			fmt.Fprintf(&b, "<h%d id=\"%s\">%s</h%d>\n", level, html.EscapeString(anchors[i]), inlineHTML(text, href), level)
+			continue
+		}
+		if exportRule.MatchString(trimmed) {
+			flush()
+			closeLists(0)
+			b.WriteString("<hr>\n")
+			continue
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			flush()
+			closeLists(0)
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				text := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(text, " "))
+			}
+			i--
+			fmt.Fprintf(&b, "<blockquote>\n%s</blockquote>\n", markdownHTML(joinLines(quoted), href))
+			continue
+		}
+		if strings.Contains(line, "|") && i+1 < len(lines) && strings.Contains(lines[i+1], "-") && exportTableRule.MatchString(strings.TrimSpace(lines[i+1])) {
+			flush()
+			closeLists(0)
+			b.WriteString("<table>\n<thead>\n" + tableRowHTML(line, "th", href) + "</thead>\n<tbody>\n")
+			for i += 2; i < len(lines) && strings.Contains(lines[i], "|"); i++ {
+				b.WriteString(tableRowHTML(lines[i], "td", href))
+			}
+			i--
+			b.WriteString("</tbody>\n</table>\n")
+			continue
+		}
+		if match := exportListItem.FindStringSubmatch(line); match != nil {
+			flush()
+			closeLists(indent + 1)
+			tag := "ul"
+			if match[3] != "" {
+				tag = "ol"
+			}
+			switch top := len(lists) - 1; {
+			case top >= 0 && lists[top].indent == indent && lists[top].tag == tag:
+				b.WriteString("</li>\n<li>")
+			default:
+				if top >= 0 && lists[top].indent == indent {
+					closeLists(indent)
+				}
+				start := ""
+				if number := strings.TrimLeft(match[3], "0"); tag == "ol" && number != "1" && number != "" {
+					start = fmt.Sprintf(" start=\"%s\"", number)
+				}
+				fmt.Fprintf(&b, "<%s%s>\n<li>", tag, start)
+				lists = append(lists, exportList{indent: indent, tag: tag})
+			}
+			text := match[4]
+			if box := strings.ToLower(text); strings.HasPrefix(box, "[ ] ") || strings.HasPrefix(box, "[x] ") {
+				checked := ""
+				if box[1] == 'x' {
+					checked = " checked"
+				}
+				b.WriteString("<input type=\"checkbox\" disabled" + checked + "> ")
+				text = text[4:]
+			}
+			paragraph, inItem = []string{text}, true
+			continue
+		}
+
+		// A line that isn't indented after a blank line ends any lists
+		if len(paragraph) == 0 && indent == 0 {
+			closeLists(0)
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+	flush()
+	closeLists(0)
+	return b.String()
+}
+
+// tableRowHTML renders one row of a markdown table with cells of kind tag
+func tableRowHTML(line, tag string, href func(string) string) string {
+	row := strings.TrimSpace(line)
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	var b strings.Builder
+	b.WriteString("<tr>")
+	for _, cell := range strings.Split(row, "|") {
+		fmt.Fprintf(&b, "<%s>%s</%s>", tag, inlineHTML(strings.TrimSpace(cell), href), tag)
+	}
+	b.WriteString("</tr>\n")
+	return b.String()
+}
+
+var (
+	exportWikiLink = regexp.MustCompile(`\[\[([^\]|#]+)(?:#([^\]|]*))?(?:\|([^\]]+))?\]\]`)
+	exportImage    = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	exportLink     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	exportURL      = regexp.MustCompile(`(^|[\s(])(https?://[^\s<]*[^\s<.,;:!?)])`)
+	exportBold     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	exportItalic   = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	exportStrike   = regexp.MustCompile(`~~([^~]+)~~`)
+)
+
+// inlineHTML renders the inline markup of markdown text: code spans,
+// [[wiki-links]], images, links, bare URLs, bold, italics and
+// strikethrough. Links to notes go through href; wiki-links to notes that
+// aren't exported are left as plain text.
+func inlineHTML(text string, href func(string) string) string {
+	parts := strings.Split(text, "`")
+	if len(parts)%2 == 0 {
+		// An unmatched backtick is just a backtick
+		parts[len(parts)-2] += "`" + parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+	}
+	for i, part := range parts {
+		if i%2 == 1 {
+			parts[i] = "<code>" + html.EscapeString(part) + "</code>"
+			continue
+		}
+		part = html.EscapeString(part)
+		part = exportWikiLink.ReplaceAllStringFunc(part, func(link string) string {
+			match := exportWikiLink.FindStringSubmatch(link)
+			target, fragment, label := strings.TrimSpace(match[1]), match[2], match[3]
+			if label == "" {
+				label = target
+			}
+			page := href(html.UnescapeString(target))
+			if page == "" {
+				return "<span class=\"missing\">" + label + "</span>"
+			}
+			if fragment != "" {
+				page += "#" + notes.Anchor(html.UnescapeString(fragment))
+			}
+			return fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(page), label)
+		})
+		part = exportImage.ReplaceAllString(part, `<img src="$2" alt="$1">`)
+		part = exportLink.ReplaceAllStringFunc(part, func(link string) string {
+			match := exportLink.FindStringSubmatch(link)
+			return fmt.Sprintf("<a href=\"%s\">%s</a>", exportLinkTarget(match[2], href), match[1])
+		})
+		part = exportURL.ReplaceAllString(part, `$1<a href="$2">$2</a>`)
+		part = exportBold.ReplaceAllString(part, "<strong>$1$2</strong>")
+		part = exportItalic.ReplaceAllString(part, "<em>$1</em>")
+		part = exportStrike.ReplaceAllString(part, "<del>$1</del>")
+		parts[i] = part
+	}
+	return strings.Join(parts, "")
+}
+
+// exportLinkTarget points an escaped link destination at the exported page
+// when it is a relative link to an exported note, keeping any #fragment
+func exportLinkTarget(target string, href func(string) string) string {
+	file, fragment, _ := strings.Cut(html.UnescapeString(target), "#")
+	if strings.Contains(file, "://") || !strings.HasSuffix(file, ".md") {
+		return target
+	}
+	page := href(file)
+	if page == "" {
+		return target
+	}
+	if fragment != "" {
+		page += "#" + fragment
+	}
+	return html.EscapeString(page)
+}
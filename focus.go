@@ -0,0 +1,166 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFocusMinutes is how long a --focus session runs without --minutes
+const DefaultFocusMinutes = 25
+
+// FocusHeading is the section of the journal entry focus sessions are
+// logged under, unless --under names another
+const FocusHeading = "## Focus"
+
+// runFocus runs a focus (pomodoro) timer for a task, notifies when it is
+// up and logs the session with its start and stop times in today's journal
+// entry. Ctrl-C ends the session early; it is still logged.
+//
+//	note --focus <task> [--minutes 25]
+func runFocus(config Config, flags *ParsedFlags, args []string) error {
+	minutes := DefaultFocusMinutes
+	var words []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--minutes" {
+			if i+1 >= len(args) {
+				return usageErrorf("--minutes requires a number")
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 1 {
+				return usageErrorf("invalid --minutes '%s'", args[i])
+			}
+			minutes = n
+			continue
+		}
+		words = append(words, args[i])
+	}
+	task := strings.Join(words, " ")
+	if task == "" {
+		return usageErrorf("usage: note --focus <task> [--minutes 25]")
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	return focusSession(ctx, config, flags, task, time.Duration(minutes)*time.Minute, os.Stdout)
+}
+
+// focusSession times one session of length d, showing the time left on a
+// terminal, and logs it once it is up or ctx is cancelled
+func focusSession(ctx context.Context, config Config, flags *ParsedFlags, task string, d time.Duration, out io.Writer) error {
+	start := time.Now()
+	fmt.Fprintf(out, "Focusing on %s for %s until %s (Ctrl-C to stop early)\n", task, formatDuration(d), start.Add(d).Format("15:04"))
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	showCountdown := isOutputToTerminal()
+	completed := false
+	for !completed {
+		select {
+		case <-timer.C:
+			completed = true
+		case <-ctx.Done():
+			if showCountdown {
+				fmt.Fprintln(out)
+			}
+			return logFocusSession(config, flags, task, start, time.Now(), false)
+		case <-ticker.C:
+			if showCountdown {
+				left := time.Until(start.Add(d)).Round(time.Second)
+				fmt.Fprintf(out, "\r%02d:%02d left ", int(left.Minutes()), int(left.Seconds())%60)
+			}
+		}
+	}
+	if showCountdown {
+		fmt.Fprint(out, "\r\a")
+	}
+	notify(config, "Focus session done", task)
+	return logFocusSession(config, flags, task, start, time.Now(), true)
+}
+
+// logFocusSession appends a session to the journal entry for the day it
+// started, under FocusHeading
+func logFocusSession(config Config, flags *ParsedFlags, task string, start, end time.Time, completed bool) error {
+	notePath, err := journalEntry(config, start)
+	if err != nil {
+		return err
+	}
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	heading := FocusHeading
+	if flags.Under != "" {
+		heading = flags.Under
+	}
+	if err := appendToNote(config, notePath, focusLine(task, start, end, completed), appendOptions{Under: heading}); err != nil {
+		return err
+	}
+	postSave(config, notePath)
+	fmt.Printf("Logged %s of focus on %s to %s\n", formatDuration(end.Sub(start)), task, noteRelPath(config, notePath))
+	return nil
+}
+
+// focusLine describes a session for the journal, e.g.
+// "- 09:00–09:25 writing spec (25m)" or, cut short, "(stopped after 12m)"
+func focusLine(task string, start, end time.Time, completed bool) string {
+	minutes := int(end.Sub(start).Round(time.Minute).Minutes())
+	length := fmt.Sprintf("%dm", minutes)
+	if !completed {
+		length = "stopped after " + length
+	}
+	return fmt.Sprintf("- %s–%s %s (%s)\n", start.Format("15:04"), end.Format("15:04"), task, length)
+}
+
+// notify shows a desktop notification with the notifier command, which
+// gets the title and message as arguments, or else with osascript on macOS
+// and notify-send elsewhere when it is installed. Failing to notify is only
+// worth a warning.
+func notify(config Config, title, message string) {
+	command := strings.Fields(config.option("notifier"))
+	var args []string
+	switch {
+	case len(command) > 0:
+		args = append(command[1:], title, message)
+	case runtime.GOOS == "darwin":
+		command = []string{"osascript"}
+		args = []string{"-e", fmt.Sprintf("display notification %q with title %q", message, title)}
+	default:
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return
+		}
+		command = []string{"notify-send"}
+		args = []string{title, message}
+	}
+	if err := exec.Command(command[0], args...).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not show a notification with %s: %v\n", command[0], err)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// postSave runs after a note has been written by the editor or an append.
+// Failures are reported but never lose the saved note.
+func postSave(config Config, notePath string) {
+	if _, err := os.Stat(notePath); err != nil {
+		// Editor exited without saving
+		return
+	}
+	if isEncryptedNote(notePath) {
+		// Formatting would rewrite the ciphertext
+		autoCommit(config, "Update "+noteRelPath(config, notePath))
+		return
+	}
+
+	if formatter := strings.Fields(config.option("formatter")); len(formatter) > 0 {
+		cmd := exec.Command(formatter[0], append(formatter[1:], notePath)...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: formatter %s failed: %v\n", formatter[0], err)
+		}
+	} else if config.boolOption("format") {
+		if err := formatNoteFile(notePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not format note: %v\n", err)
+		}
+	}
+	autoCommit(config, "Update "+noteRelPath(config, notePath))
+}
+
+// formatNoteFile rewrites a note with formatMarkdown, leaving it untouched
+// when nothing changes
+func formatNoteFile(notePath string) error {
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return err
+	}
+	formatted := formatMarkdown(string(content))
+	if formatted == string(content) {
+		return nil
+	}
+	return writeFileAtomic(notePath, []byte(formatted), notePerm(notePath))
+}
+
+var (
+	// Reference link definitions like "[docs]: https://example.com"
+	referenceDefinition = regexp.MustCompile(`^\s{0,3}\[([^\]^][^\]]*)\]:\s+\S`)
+	// Bullets using * or + instead of -
+	altListMarker = regexp.MustCompile(`^(\s*)[*+](\s+)`)
+)
+
+// formatMarkdown normalizes markdown: trailing whitespace is removed (hard
+// line breaks keep two spaces), headings get blank lines around them, bullets
+// use "-", runs of blank lines collapse, and reference link definitions are
+// de-duplicated and gathered at the end. Fenced code blocks are left alone.
+func formatMarkdown(content string) string {
+	lines := splitLines(content)
+	var out []string
+	var references []string
+	seenReferences := make(map[string]bool)
+	inFence := false
+
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			out = append(out, strings.TrimRight(line, " \t"))
+			continue
+		}
+		if inFence {
+			out = append(out, line)
+			continue
+		}
+
+		// Keep markdown hard line breaks, drop any other trailing whitespace
+		trimmed := strings.TrimRight(line, " \t")
+		if strings.HasSuffix(line, "  ") && trimmed != "" && headingLevel(trimmed) == 0 {
+			trimmed += "  "
+		}
+		line = trimmed
+
+		if referenceDefinition.MatchString(line) {
+			line = strings.TrimSpace(line)
+			if !seenReferences[line] {
+				seenReferences[line] = true
+				references = append(references, line)
+			}
+			continue
+		}
+
+		line = altListMarker.ReplaceAllString(line, "${1}-${2}")
+
+		if headingLevel(line) > 0 {
+			// Blank line before the heading (unless it starts the note)
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			out = append(out, line, "")
+			continue
+		}
+
+		// Collapse multiple blank lines
+		if line == "" && (len(out) == 0 || out[len(out)-1] == "") {
+			continue
+		}
+		out = append(out, line)
+	}
+
+	// Trim blank lines at the end of the note
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+
+	if len(references) > 0 {
+		sort.Strings(references)
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		out = append(out, references...)
+	}
+
+	return joinLines(out)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"strings"
+)
+
+// setFrontmatterValue returns content with key set to value in its
+// frontmatter, creating the frontmatter block if the note has none
+func setFrontmatterValue(content, key, value string) string {
+	lines := splitLines(content)
+	end := frontmatterEnd(lines)
+	entry := key + ": " + value
+
+	if end == 0 {
+		return joinLines(append([]string{"---", entry, "---"}, lines...))
+	}
+
+	for i := 1; i < end-1; i++ {
+		if k, _, ok := strings.Cut(lines[i], ":"); ok && strings.TrimSpace(k) == key {
+			lines[i] = entry
+			return joinLines(lines)
+		}
+	}
+	return joinLines(insertLines(lines, end-1, []string{entry}))
+}
+
+// removeFrontmatterValue returns content without key in its frontmatter,
+// dropping the block entirely if it ends up empty
+func removeFrontmatterValue(content, key string) string {
+	lines := splitLines(content)
+	end := frontmatterEnd(lines)
+	if end == 0 {
+		return content
+	}
+
+	var kept []string
+	for i := 1; i < end-1; i++ {
+		if k, _, ok := strings.Cut(lines[i], ":"); ok && strings.TrimSpace(k) == key {
+			continue
+		}
+		kept = append(kept, lines[i])
+	}
+
+	body := lines[end:]
+	if len(kept) == 0 {
+		return joinLines(body)
+	}
+	result := append([]string{"---"}, kept...)
+	result = append(result, "---")
+	return joinLines(append(result, body...))
+}
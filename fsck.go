@@ -0,0 +1,301 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// fsckIssue is one problem found in the notes directory. fix repairs it,
+// or is nil when it needs a person to decide.
+type fsckIssue struct {
+	Kind   string
+	Path   string // relative to the notes directory
+	Detail string
+	fix    func() error
+}
+
+// datedSuffix matches the -YYYYMMDD stamp of dated note filenames
+var datedSuffix = regexp.MustCompile(`^(.+)-\d{8}\.md$`)
+
+// runFsck checks the notes directory for damage and inconsistencies,
+// repairing what it safely can with --fix:
+//
+//	note --fsck [--fix]
+func runFsck(config Config, args []string) error {
+	fix := false
+	for _, arg := range args {
+		if arg != "--fix" {
+			return usageErrorf("usage: note --fsck [--fix]")
+		}
+		fix = true
+	}
+
+	issues := checkNotesDir(config)
+	if len(issues) == 0 {
+		fmt.Printf("No problems found in %s\n", config.NotesDir)
+		return nil
+	}
+
+	fixable, fixed := 0, 0
+	for _, issue := range issues {
+		status := ""
+		if issue.fix != nil {
+			fixable++
+			if fix {
+				if err := issue.fix(); err != nil {
+					status = fmt.Sprintf(" (fix failed: %v)", err)
+				} else {
+					status = " (fixed)"
+					fixed++
+				}
+			}
+		}
+		fmt.Printf("%-16s %s: %s%s\n", issue.Kind, issue.Path, issue.Detail, status)
+	}
+
+	fmt.Printf("\n%d problem(s) found", len(issues))
+	switch {
+	case fix:
+		fmt.Printf(", %d fixed\n", fixed)
+	case fixable > 0:
+		fmt.Printf("; run 'note --fsck --fix' to repair %d of them\n", fixable)
+	default:
+		fmt.Println()
+	}
+	return nil
+}
+
+// checkNotesDir runs every check and returns the problems in a stable order
+func checkNotesDir(config Config) []fsckIssue {
+	notesDir := config.NotesDir
+	var issues []fsckIssue
+	issues = append(issues, checkNoteFiles(notesDir)...)
+	issues = append(issues, checkDatedDuplicates(config)...)
+	issues = append(issues, checkArchiveDirs(notesDir)...)
+	issues = append(issues, checkStrayLocks(notesDir)...)
+	issues = append(issues, checkIndexDrift(notesDir)...)
+	return issues
+}
+
+// checkNoteFiles finds empty notes, notes that are not valid UTF-8 and
+// notes whose read-only mode disagrees with their locked flag
+func checkNoteFiles(notesDir string) []fsckIssue {
+	var issues []fsckIssue
+	for _, note := range findMatchingNotes(notesDir, "", true) {
+		path := filepath.Join(notesDir, note)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.Size() == 0 {
+			issues = append(issues, fsckIssue{Kind: "empty note", Path: note, Detail: "zero bytes; --fix removes it",
+				fix: func() error { return os.Remove(path) }})
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if !utf8.Valid(content) {
+			issues = append(issues, fsckIssue{Kind: "invalid UTF-8", Path: note, Detail: "--fix reads the bad bytes as Latin-1",
+				fix: func() error { return writeFileAtomic(path, repairUTF8(content), info.Mode().Perm()) }})
+		}
+
+		locked := parseFrontmatter(string(content))["locked"] == "true"
+		readOnly := info.Mode().Perm()&0200 == 0
+		if locked && !readOnly {
+			issues = append(issues, fsckIssue{Kind: "lock mismatch", Path: note, Detail: "locked but writable; --fix makes it read-only",
+				fix: func() error { return os.Chmod(path, info.Mode().Perm()&^0222) }})
+		} else if readOnly && !locked {
+			issues = append(issues, fsckIssue{Kind: "lock mismatch", Path: note, Detail: "read-only but not locked; --fix makes it writable",
+				fix: func() error { return os.Chmod(path, info.Mode().Perm()|0200) }})
+		}
+	}
+	return issues
+}
+
+// repairUTF8 reinterprets each byte that is not part of a valid UTF-8
+// sequence as Latin-1, the usual source of such bytes in text files
+func repairUTF8(content []byte) []byte {
+	var b strings.Builder
+	for len(content) > 0 {
+		r, size := utf8.DecodeRune(content)
+		if r == utf8.RuneError && size == 1 {
+			r = rune(content[0])
+		}
+		b.WriteRune(r)
+		content = content[size:]
+	}
+	return []byte(b.String())
+}
+
+// checkDatedDuplicates finds names with several dated notes, which happens
+// when a note without a matching title is reopened on another day. Recurring
+// notes from the [schedule] section are expected to have many.
+func checkDatedDuplicates(config Config) []fsckIssue {
+	byName := make(map[string][]string)
+	for _, note := range findMatchingNotes(config.NotesDir, "", false) {
+		if match := datedSuffix.FindStringSubmatch(note); match != nil {
+			name := strings.ToLower(match[1])
+			byName[name] = append(byName[name], note)
+		}
+	}
+
+	scheduled := make(map[string]bool)
+	for name := range config.Schedule {
+		scheduled[strings.ToLower(strings.ReplaceAll(name, " ", "_"))] = true
+	}
+
+	var issues []fsckIssue
+	for _, name := range sortedKeys(byName) {
+		notes := byName[name]
+		if len(notes) < 2 || scheduled[name] {
+			continue
+		}
+		issues = append(issues, fsckIssue{Kind: "dated duplicate", Path: name,
+			Detail: fmt.Sprintf("%d notes (%s); merge them by hand", len(notes), strings.Join(notes, ", "))})
+	}
+	return issues
+}
+
+// checkArchiveDirs finds archive directories cased differently from the
+// one note uses, whose notes -a never shows; --fix moves them across
+func checkArchiveDirs(notesDir string) []fsckIssue {
+	archiveDir := getArchiveDir(notesDir)
+	entries, err := os.ReadDir(notesDir)
+	if err != nil {
+		return nil
+	}
+	var issues []fsckIssue
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.EqualFold(entry.Name(), "archive") || entry.Name() == filepath.Base(archiveDir) {
+			continue
+		}
+		dir := filepath.Join(notesDir, entry.Name())
+		issues = append(issues, fsckIssue{Kind: "archive casing", Path: entry.Name() + "/",
+			Detail: fmt.Sprintf("note archives to %s/; --fix moves its notes there", filepath.Base(archiveDir)),
+			fix:    func() error { return mergeDir(dir, archiveDir) }})
+	}
+	return issues
+}
+
+// mergeDir moves everything in src into dst, refusing to replace files,
+// and removes src once it is empty
+func mergeDir(src, dst string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		target := filepath.Join(dst, entry.Name())
+		if pathExists(target) {
+			return fmt.Errorf("%s exists in both", entry.Name())
+		}
+		if err := os.Rename(filepath.Join(src, entry.Name()), target); err != nil {
+			return err
+		}
+	}
+	return os.Remove(src)
+}
+
+// checkStrayLocks finds state lock files whose state file is gone. The
+// journal's lock and the notes directory's lock are kept between
+// operations, so they are never stray.
+func checkStrayLocks(notesDir string) []fsckIssue {
+	locks, _ := filepath.Glob(filepath.Join(notesDir, "*.lock"))
+	var issues []fsckIssue
+	for _, lock := range locks {
+		state := strings.TrimSuffix(lock, ".lock")
+		if filepath.Base(state) == JournalFile || filepath.Base(lock) == NotesLockFile || pathExists(state) {
+			continue
+		}
+		issues = append(issues, fsckIssue{Kind: "stray lock", Path: filepath.Base(lock),
+			Detail: "no matching state file; --fix removes it",
+			fix:    func() error { return os.Remove(lock) }})
+	}
+	return issues
+}
+
+// checkIndexDrift compares the metadata and title caches with the notes on
+// disk; --fix rebuilds them as --reindex does
+func checkIndexDrift(notesDir string) []fsckIssue {
+	onDisk := make(map[string]int64)
+	for _, note := range findMatchingNotes(notesDir, "", false) {
+		if info, err := os.Stat(filepath.Join(notesDir, note)); err == nil {
+			onDisk[note] = info.ModTime().UnixNano()
+		}
+	}
+
+	rebuild := func() error {
+		for _, cache := range []string{MetadataFile, TitleIndexFile} {
+			if err := os.Remove(filepath.Join(notesDir, cache)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		loadMetadata(notesDir)
+		loadTitleIndex(notesDir)
+		return nil
+	}
+
+	var issues []fsckIssue
+	check := func(cache string, cached map[string]int64) {
+		if !pathExists(filepath.Join(notesDir, cache)) {
+			return
+		}
+		missing, stale, gone := 0, 0, 0
+		for note, modTime := range onDisk {
+			if cachedTime, ok := cached[note]; !ok {
+				missing++
+			} else if cachedTime != modTime {
+				stale++
+			}
+		}
+		for note := range cached {
+			if _, ok := onDisk[note]; !ok {
+				gone++
+			}
+		}
+		if missing+stale+gone > 0 {
+			issues = append(issues, fsckIssue{Kind: "index drift", Path: cache,
+				Detail: fmt.Sprintf("%d missing, %d stale, %d for deleted notes; --fix rebuilds it", missing, stale, gone),
+				fix:    rebuild})
+		}
+	}
+
+	metadata := make(map[string]int64)
+	for note, meta := range readMetadataStore(filepath.Join(notesDir, MetadataFile)) {
+		metadata[note] = meta.ModTime
+	}
+	check(MetadataFile, metadata)
+	titles := make(map[string]int64)
+	for note, entry := range readTitleIndex(filepath.Join(notesDir, TitleIndexFile)) {
+		titles[note] = entry.ModTime
+	}
+	check(TitleIndexFile, titles)
+	return issues
+}
@@ -0,0 +1,165 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pickerCandidates returns note names for a picker, most recently modified
+// first, with archived notes prefixed by their archive directory
+func pickerCandidates(config Config, pattern string, includeArchived bool) []string {
+	type candidate struct {
+		name    string
+		modTime int64
+	}
+	var candidates []candidate
+
+	dirs := []string{config.NotesDir}
+	if includeArchived {
+		dirs = append(dirs, getArchiveDir(config.NotesDir))
+	}
+	for _, dir := range dirs {
+		prefix := ""
+		if dir != config.NotesDir {
+			prefix = filepath.Base(dir) + "/"
+		}
+		for _, note := range findMatchingNotes(dir, pattern, false) {
+			var modTime int64
+			if info, err := os.Stat(filepath.Join(dir, note)); err == nil {
+				modTime = info.ModTime().UnixNano()
+			}
+			candidates = append(candidates, candidate{prefix + note, modTime})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].modTime > candidates[j].modTime
+	})
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		names[i] = c.name
+	}
+	return names
+}
+
+// runFzfCandidates prints picker candidates one per line, for piping into
+// fzf with `--preview 'note --cat {}'`
+func runFzfCandidates(config Config, pattern string, includeArchived bool) error {
+	for _, name := range pickerCandidates(config, pattern, includeArchived) {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+// runCat prints a note's contents; used as the fzf preview command
+func runCat(config Config, noteName string) error {
+	notePath, err := existingNotePath(config, noteName)
+	if err != nil {
+		return err
+	}
+	content, err := readNoteWithCaptures(config, notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+	os.Stdout.Write([]byte(expandEmoji(config, string(content))))
+	return nil
+}
+
+// runPick runs fzf over the notes with a live preview and opens the selection
+func runPick(config Config, pattern string, includeArchived bool, force bool) error {
+	if _, err := exec.LookPath("fzf"); err != nil {
+		return fmt.Errorf("fzf not found in PATH; install fzf or use 'note --fzf' to feed another picker")
+	}
+	candidates := pickerCandidates(config, pattern, includeArchived)
+	if len(candidates) == 0 {
+		fmt.Println("No notes to pick from")
+		return nil
+	}
+	selection, err := fzfSelect(candidates, "")
+	if err != nil || selection == "" {
+		return err
+	}
+	return openOrCreateNote(config, selection, force, false)
+}
+
+// runFind picks a note with the fuzzy finder and opens it; without a
+// terminal it prints the candidates like --fzf
+func runFind(config Config, flags *ParsedFlags, pattern string) error {
+	candidates := pickerCandidates(config, pattern, flags.Archive)
+	if !isInputFromTerminal() || !isOutputToTerminal() {
+		for _, name := range candidates {
+			fmt.Println(name)
+		}
+		return nil
+	}
+	if len(candidates) == 0 {
+		fmt.Println("No notes to pick from")
+		return nil
+	}
+	selection, err := findNote(config, candidates, "", "quit")
+	if err != nil || selection == "" {
+		return err
+	}
+	return openOrCreateNote(config, selection, flags.Force, false)
+}
+
+// findNote lets the user pick one of candidates, starting from query, with
+// fzf when it is installed (unless finder=builtin) and otherwise with the
+// built-in finder; cancel names what cancelling leads to. It returns ""
+// when the pick is cancelled.
+func findNote(config Config, candidates []string, query, cancel string) (string, error) {
+	if config.option("finder") != "builtin" {
+		if _, err := exec.LookPath("fzf"); err == nil {
+			return fzfSelect(candidates, query)
+		}
+	}
+	b := &browser{config: config, pick: true, candidates: candidates, filter: query, cancel: cancel}
+	return b.run()
+}
+
+// fzfSelect runs fzf over candidates with a preview of each note and
+// returns the selection, or "" when it is cancelled
+func fzfSelect(candidates []string, query string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("could not determine note command path: %w", err)
+	}
+	preview := shellQuote(exe)
+	for _, arg := range globalFlagArgs() {
+		preview += " " + shellQuote(arg)
+	}
+	args := []string{"--preview", preview + " --cat {}"}
+	if query != "" {
+		args = append(args, "--query", query)
+	}
+	cmd := exec.Command("fzf", args...)
+	cmd.Stdin = strings.NewReader(strings.Join(candidates, "\n") + "\n")
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		// fzf exits non-zero when the selection is cancelled
+		return "", nil
+	}
+	return strings.TrimSpace(string(output)), nil
+}
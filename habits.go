@@ -0,0 +1,227 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultHabitsNote is the ledger --habit keeps unless habits_note is set
+const DefaultHabitsNote = "habits.md"
+
+// habitEntry matches a ledger line, "- 2025-06-01 exercise"
+var habitEntry = regexp.MustCompile(`^- (\d{4}-\d{2}-\d{2}) (.+)$`)
+
+// runHabit tracks habits in a ledger note, one "- YYYY-MM-DD habit" line
+// per day a habit was done, so the data stays an ordinary note:
+//
+//	note --habit done <habit> [--on yesterday]
+//	note --habit report [--month 2025-05]
+func runHabit(config Config, flags *ParsedFlags, args []string) error {
+	usage := usageErrorf("usage: note --habit done <habit> [--on <day>] | note --habit report [--month YYYY-MM]")
+	if len(args) == 0 {
+		return usage
+	}
+	notePath := habitsNotePath(config)
+	now := time.Now()
+
+	switch args[0] {
+	case "done":
+		var words []string
+		day := ""
+		for i := 1; i < len(args); i++ {
+			if args[i] == "--on" && i+1 < len(args) {
+				i++
+				day = args[i]
+				continue
+			}
+			words = append(words, args[i])
+		}
+		habit := strings.ToLower(strings.Join(words, " "))
+		if habit == "" {
+			return usage
+		}
+		date, err := parseJournalDate(day, now)
+		if err != nil {
+			return usageErrorf("%v", err)
+		}
+		return markHabit(config, flags, notePath, habit, date)
+	case "report":
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+		if len(args) == 3 && args[1] == "--month" {
+			parsed, err := time.ParseInLocation("2006-01", args[2], time.Local)
+			if err != nil {
+				return usageErrorf("invalid --month '%s' (use YYYY-MM)", args[2])
+			}
+			month = parsed
+		} else if len(args) != 1 {
+			return usage
+		}
+		content, err := os.ReadFile(notePath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		habits := parseHabits(string(content))
+		if len(habits) == 0 {
+			fmt.Println("No habits tracked yet; start with 'note --habit done <habit>'")
+			return nil
+		}
+		printHabitReport(os.Stdout, habits, month, now)
+		return nil
+	}
+	return usage
+}
+
+// habitsNotePath returns the path of the habit ledger
+func habitsNotePath(config Config) string {
+	name := config.option("habits_note")
+	if name == "" {
+		name = DefaultHabitsNote
+	}
+	if !strings.HasSuffix(name, ".md") {
+		name += ".md"
+	}
+	return filepath.Join(config.NotesDir, name)
+}
+
+// markHabit records habit as done on day, once
+func markHabit(config Config, flags *ParsedFlags, notePath, habit string, day time.Time) error {
+	return withNotesLock(config.NotesDir, func() error {
+		content, err := os.ReadFile(notePath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if parseHabits(string(content))[habit][day.Format("2006-01-02")] {
+			fmt.Printf("%s is already done for %s\n", habit, day.Format("2006-01-02"))
+			return nil
+		}
+		if len(content) == 0 {
+			content = []byte("# Habits\n\n")
+		}
+
+		wasLocked, err := checkNoteWritable(notePath, flags.Force)
+		if err != nil {
+			return err
+		}
+		defer restoreLock(notePath, wasLocked)
+		updated := insertText(string(content), fmt.Sprintf("- %s %s\n", day.Format("2006-01-02"), habit), appendOptions{})
+		if err := writeFileAtomic(notePath, []byte(updated), notePerm(notePath)); err != nil {
+			return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+		}
+		postSave(config, notePath)
+
+		streak, _ := habitStreaks(parseHabits(updated)[habit], day)
+		fmt.Printf("Marked %s done for %s (%d day streak)\n", habit, day.Format("2006-01-02"), streak)
+		return nil
+	})
+}
+
+// parseHabits reads the ledger into the days (YYYY-MM-DD) each habit was
+// done on; other lines in the note are ignored
+func parseHabits(content string) map[string]map[string]bool {
+	habits := make(map[string]map[string]bool)
+	for _, line := range splitLines(content) {
+		match := habitEntry.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", match[1]); err != nil {
+			continue
+		}
+		habit := strings.ToLower(strings.TrimSpace(match[2]))
+		if habits[habit] == nil {
+			habits[habit] = make(map[string]bool)
+		}
+		habits[habit][match[1]] = true
+	}
+	return habits
+}
+
+// habitStreaks returns the current streak, the run of days done ending on
+// today (or yesterday, as today may not be done yet), and the longest ever
+func habitStreaks(days map[string]bool, today time.Time) (current, best int) {
+	var dates []string
+	for day := range days {
+		dates = append(dates, day)
+	}
+	sort.Strings(dates)
+	run := 0
+	var previous time.Time
+	for _, day := range dates {
+		date, _ := time.ParseInLocation("2006-01-02", day, time.Local)
+		if run > 0 && date.Equal(previous.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		best = max(best, run)
+		previous = date
+	}
+
+	day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)
+	if !days[day.Format("2006-01-02")] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day.Format("2006-01-02")] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return current, best
+}
+
+// printHabitReport prints a month's grid, one row per habit with ■ for the
+// days it was done and · for the days it wasn't, followed by its streaks.
+// Days after today are left blank.
+func printHabitReport(w io.Writer, habits map[string]map[string]bool, month, now time.Time) {
+	names := sortedKeys(habits)
+	width := 0
+	for _, name := range names {
+		width = max(width, len([]rune(name)))
+	}
+	daysInMonth := time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, time.Local).Day()
+
+	var ruler strings.Builder
+	for day := 1; day <= daysInMonth; day++ {
+		ruler.WriteString(fmt.Sprint(day % 10))
+	}
+	fmt.Fprintf(w, "%s\n\n", month.Format("January 2006"))
+	fmt.Fprintf(w, "%s  %s\n", padRight("", width), ruler.String())
+	for _, name := range names {
+		var grid strings.Builder
+		for day := 1; day <= daysInMonth; day++ {
+			date := time.Date(month.Year(), month.Month(), day, 0, 0, 0, 0, time.Local)
+			switch {
+			case date.After(now):
+				grid.WriteString(" ")
+			case habits[name][date.Format("2006-01-02")]:
+				grid.WriteString("■")
+			default:
+				grid.WriteString("·")
+			}
+		}
+		current, best := habitStreaks(habits[name], now)
+		fmt.Fprintf(w, "%s  %s  streak %d, best %d\n", padRight(name, width), grid.String(), current, best)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryFile records when notes were opened, one "unixtime<TAB>note" line
+// per access, and feeds frecency ranking
+const HistoryFile = ".note_history"
+
+// HistoryCompactSize is the size past which the history is trimmed to its
+// last HistoryKeepLines entries; older accesses barely affect frecency
+const (
+	HistoryCompactSize = 256 << 10
+	HistoryKeepLines   = 2000
+)
+
+// recordAccess appends an access entry for a note. History is best effort:
+// failing to record it never gets in the way of editing.
+func recordAccess(notesDir, notePath string) {
+	relPath, err := filepath.Rel(notesDir, notePath)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return
+	}
+	historyPath := filepath.Join(notesDir, HistoryFile)
+	withStateLock(historyPath, func() error {
+		file, err := os.OpenFile(historyPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(file, "%d\t%s\n", time.Now().Unix(), relPath)
+		info, err := file.Stat()
+		file.Close()
+		if err == nil && info.Size() > HistoryCompactSize {
+			return compactHistory(historyPath, HistoryKeepLines)
+		}
+		return nil
+	})
+}
+
+// compactHistory rewrites the history with only its last keep entries. The
+// caller holds the history's state lock.
+func compactHistory(historyPath string, keep int) error {
+	content, err := os.ReadFile(historyPath)
+	if err != nil {
+		return err
+	}
+	lines := splitLines(string(content))
+	if len(lines) <= keep {
+		return nil
+	}
+	return writeFileAtomic(historyPath, []byte(joinLines(lines[len(lines)-keep:])), 0644)
+}
+
+// loadAccessHistory returns the recorded access times for each note
+func loadAccessHistory(notesDir string) map[string][]time.Time {
+	history := make(map[string][]time.Time)
+	file, err := os.Open(filepath.Join(notesDir, HistoryFile))
+	if err != nil {
+		return history
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		stamp, note, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil {
+			continue
+		}
+		history[note] = append(history[note], time.Unix(seconds, 0))
+	}
+	return history
+}
+
+// frecencyScore combines how often and how recently a note was accessed:
+// each access counts for more the more recent it is
+func frecencyScore(accesses []time.Time, now time.Time) int {
+	score := 0
+	for _, access := range accesses {
+		switch age := now.Sub(access); {
+		case age < 4*24*time.Hour:
+			score += 100
+		case age < 14*24*time.Hour:
+			score += 70
+		case age < 31*24*time.Hour:
+			score += 50
+		case age < 90*24*time.Hour:
+			score += 30
+		default:
+			score += 10
+		}
+	}
+	return score
+}
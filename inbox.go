@@ -0,0 +1,242 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultInbox is the note quick captures land in unless inbox is set
+const DefaultInbox = "inbox.md"
+
+// inboxItem is one refileable entry in the inbox: a top-level bullet with
+// its indented continuation lines, or a section under a level 2+ heading
+type inboxItem struct {
+	Start, End int // line range, End exclusive
+}
+
+// inboxPath returns the path of the inbox note
+func inboxPath(config Config) string {
+	name := config.option("inbox")
+	if name == "" {
+		name = DefaultInbox
+	}
+	if !strings.HasSuffix(name, ".md") {
+		name += ".md"
+	}
+	return filepath.Join(config.NotesDir, name)
+}
+
+// runInbox captures text as a bullet in the inbox, or opens the inbox when
+// there is nothing to capture
+func runInbox(config Config, args []string) error {
+	text := strings.Join(args, " ")
+	if text == "" && !isInputFromTerminal() {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("error reading stdin: %w", err)
+		}
+		text = strings.TrimSpace(string(data))
+	}
+
+	notePath := inboxPath(config)
+	if text == "" {
+		return editNote(config, notePath)
+	}
+
+	wasLocked, err := checkNoteWritable(notePath, false)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	if _, err := appendOrLog(config, notePath, inboxBullet(unfurlText(config, text)), appendOptions{}); err != nil {
+		return err
+	}
+	fmt.Printf("Captured to %s\n", filepath.Base(notePath))
+	return nil
+}
+
+// inboxBullet turns captured text into a list item, indenting any further
+// lines under it
+func inboxBullet(text string) string {
+	lines := splitLines(strings.TrimSpace(text) + "\n")
+	for i := range lines {
+		if i == 0 {
+			lines[i] = "- " + strings.TrimPrefix(lines[i], "- ")
+		} else if lines[i] != "" {
+			lines[i] = "  " + lines[i]
+		}
+	}
+	return joinLines(lines)
+}
+
+// runRefile walks through the inbox item by item, moving each one to the
+// note named at the prompt
+func runRefile(config Config) error {
+	return refileInbox(config, inboxPath(config), os.Stdin, os.Stdout)
+}
+
+// refileInbox prompts on out for a destination for every inbox item, reading
+// answers from in: a note name moves the item there, "d" deletes it, an
+// empty answer keeps it and "q" stops. The inbox is rewritten once at the
+// end so quitting early never loses items.
+func refileInbox(config Config, notePath string, in io.Reader, out io.Writer) error {
+	content, err := os.ReadFile(notePath)
+	if os.IsNotExist(err) {
+		fmt.Fprintln(out, "Inbox is empty")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+	wasLocked, err := checkNoteWritable(notePath, false)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	lines := splitLines(string(content))
+	items := inboxItems(lines)
+	if len(items) == 0 {
+		fmt.Fprintln(out, "Inbox is empty")
+		return nil
+	}
+
+	reader := bufio.NewReader(in)
+	remove := make(map[int]bool)
+	moved, deleted := 0, 0
+	for _, item := range items {
+		fmt.Fprintf(out, "\n%s\n", strings.Join(lines[item.Start:item.End], "\n"))
+		fmt.Fprint(out, "Refile to (note name, d = delete, Enter = keep, q = quit): ")
+		answer, err := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer == "q" || (answer == "" && err != nil) {
+			break
+		}
+
+		switch answer {
+		case "":
+			continue
+		case "d":
+			deleted++
+		default:
+			destination := resolveNotePath(config, answer)
+			if destination == notePath {
+				fmt.Fprintln(out, "That is the inbox; keeping the item")
+				continue
+			}
+			if err := refileItem(config, destination, lines[item.Start:item.End]); err != nil {
+				fmt.Fprintf(out, "Error: %v; keeping the item\n", err)
+				continue
+			}
+			fmt.Fprintf(out, "Moved to %s\n", filepath.Base(destination))
+			moved++
+		}
+		for i := item.Start; i < item.End; i++ {
+			remove[i] = true
+		}
+	}
+
+	if len(remove) == 0 {
+		return nil
+	}
+	var kept []string
+	for i, line := range lines {
+		if !remove[i] {
+			kept = append(kept, line)
+		}
+	}
+	// Captures may have been appended while the items were refiled; they
+	// are kept, and anything else changed leaves the inbox alone
+	err = withNotesLock(config.NotesDir, func() error {
+		current, err := os.ReadFile(notePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+		}
+		added, ok := strings.CutPrefix(string(current), string(content))
+		if !ok {
+			return fmt.Errorf("%s changed while refiling; refiled items are still in it", filepath.Base(notePath))
+		}
+		if err := writeFileAtomic(notePath, []byte(joinLines(kept)+added), notePerm(notePath)); err != nil {
+			return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\nRefiled %d item(s), deleted %d\n", moved, deleted)
+	return nil
+}
+
+// refileItem appends an inbox item to the destination note
+func refileItem(config Config, destination string, itemLines []string) error {
+	wasLocked, err := checkNoteWritable(destination, false)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(destination, wasLocked)
+
+	if err := appendToNote(config, destination, joinLines(itemLines), appendOptions{}); err != nil {
+		return err
+	}
+	postSave(config, destination)
+	return nil
+}
+
+// inboxItems splits the inbox into refileable items. Lines that are neither
+// bullets nor inside a section, such as the title, are left alone.
+func inboxItems(lines []string) []inboxItem {
+	var items []inboxItem
+	for i := frontmatterEnd(lines); i < len(lines); {
+		line := lines[i]
+		switch {
+		case headingLevel(line) >= 2:
+			end := sectionEnd(lines, i)
+			items = append(items, inboxItem{Start: i, End: end})
+			i = end
+		case isBulletLine(line):
+			end := i + 1
+			for end < len(lines) && (strings.HasPrefix(lines[end], " ") || strings.HasPrefix(lines[end], "\t")) {
+				end++
+			}
+			items = append(items, inboxItem{Start: i, End: end})
+			i = end
+		default:
+			i++
+		}
+	}
+	return items
+}
+
+// isBulletLine reports whether line starts a top-level list item
+func isBulletLine(line string) bool {
+	for _, marker := range []string{"- ", "* ", "+ "} {
+		if strings.HasPrefix(line, marker) {
+			return true
+		}
+	}
+	number := strings.TrimLeft(line, "0123456789")
+	return len(number) < len(line) && strings.HasPrefix(number, ". ")
+}
@@ -0,0 +1,210 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// IndexNote is the note --index generates in a notebook, listing the
+// notes beside it
+const IndexNote = "_index.md"
+
+// indexMarker starts a generated index note, recording how it is grouped
+// so regenerating it keeps the grouping
+var indexMarker = regexp.MustCompile(`<!-- generated by note --index --by (month|tag)`)
+
+// indexedNote is one note listed in an index note
+type indexedNote struct {
+	Name  string
+	Title string
+	Day   string // YYYY-MM-DD of the note's date
+	Tags  []string
+}
+
+// runIndex writes or refreshes a notebook's _index.md:
+//
+//	note --index [notebook] [--by month|tag]
+//
+// The notes in the notebook (the notes directory by default) are linked
+// by title, grouped by month, newest first, or by tag. The file is only
+// rewritten when the listing changes, so it is safe to run from a hook.
+func runIndex(config Config, args []string) error {
+	var by string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--by" {
+			if i+1 >= len(args) || (args[i+1] != "month" && args[i+1] != "tag") {
+				return usageErrorf("--by takes month or tag")
+			}
+			i++
+			by = args[i]
+			continue
+		}
+		rest = append(rest, args[i])
+	}
+	if len(rest) > 1 {
+		return usageErrorf("usage: note --index [notebook] [--by month|tag]")
+	}
+	notebook := ""
+	if len(rest) == 1 {
+		notebook = strings.Trim(filepath.ToSlash(rest[0]), "/")
+	}
+
+	indexPath, changed, err := writeNotebookIndex(config, notebook, by)
+	if err != nil {
+		return err
+	}
+	if changed {
+		fmt.Printf("Updated %s\n", noteRelPath(config, indexPath))
+	} else {
+		fmt.Printf("%s is up to date\n", noteRelPath(config, indexPath))
+	}
+	return nil
+}
+
+// writeNotebookIndex regenerates the index note of notebook, a folder
+// relative to the notes directory ("" for the notes directory itself),
+// grouped by by or, if that is empty, as the existing index was. It
+// returns the index's path and whether the file changed.
+func writeNotebookIndex(config Config, notebook, by string) (string, bool, error) {
+	if notebook != "" && strings.HasPrefix(path.Clean(notebook), ".") {
+		// Hidden folders are not notebooks, and .. leaves the notes directory
+		return "", false, usageErrorf("invalid notebook '%s'", notebook)
+	}
+	dir := filepath.Join(config.NotesDir, filepath.FromSlash(notebook))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", false, fmt.Errorf("notebook '%s' not found", notebook)
+	}
+
+	indexPath := filepath.Join(dir, IndexNote)
+	existing, err := os.ReadFile(indexPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", false, fmt.Errorf("error reading %s: %w", IndexNote, err)
+	}
+	if by == "" {
+		by = "month"
+		if match := indexMarker.FindSubmatch(existing); match != nil {
+			by = string(match[1])
+		}
+	}
+
+	var listed []indexedNote
+	for _, name := range findMatchingNotes(dir, "", false) {
+		if name == IndexNote {
+			continue
+		}
+		notePath := filepath.Join(dir, name)
+		content, err := os.ReadFile(notePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Warning: skipping %s: %v\n", name, err)
+			continue
+		}
+		info, err := os.Stat(notePath)
+		if err != nil {
+			continue
+		}
+		meta := extractMetadata(string(content))
+		title := meta.Title
+		if title == "" {
+			title = strings.TrimSuffix(name, ".md")
+		}
+		listed = append(listed, indexedNote{Name: name, Title: title, Day: noteDate(name, info.ModTime()).Format("2006-01-02"), Tags: meta.Tags})
+	}
+
+	heading := notebook
+	if heading == "" {
+		heading = "Notes"
+	}
+	content := renderIndex(heading, listed, by)
+	if string(existing) == content {
+		return indexPath, false, nil
+	}
+	if err := writeFileAtomic(indexPath, []byte(content), notePerm(indexPath)); err != nil {
+		return "", false, fmt.Errorf("error writing %s: %w", IndexNote, err)
+	}
+	autoCommit(config, "Update "+noteRelPath(config, indexPath))
+	return indexPath, true, nil
+}
+
+// renderIndex lists notes under a heading per month, newest first, or per
+// tag, alphabetically with untagged notes last. Notes within a group are
+// newest first by day, then by name.
+func renderIndex(heading string, listed []indexedNote, by string) string {
+	sort.SliceStable(listed, func(i, j int) bool {
+		if listed[i].Day != listed[j].Day {
+			return listed[i].Day > listed[j].Day
+		}
+		return listed[i].Name < listed[j].Name
+	})
+
+	groups := make(map[string][]indexedNote)
+	for _, note := range listed {
+		keys := []string{note.Day[:7]}
+		if by == "tag" {
+			keys = note.Tags
+			if len(keys) == 0 {
+				keys = []string{""}
+			}
+		}
+		for _, key := range keys {
+			groups[key] = append(groups[key], note)
+		}
+	}
+	order := sortedKeys(groups)
+	if by == "month" {
+		sort.Sort(sort.Reverse(sort.StringSlice(order)))
+	} else if len(order) > 0 && order[0] == "" {
+		order = append(order[1:], "")
+	}
+
+	lines := []string{
+		"# Index: " + heading,
+		"",
+		fmt.Sprintf("<!-- generated by note --index --by %s; edits are overwritten -->", by),
+	}
+	for _, key := range order {
+		title := key
+		switch {
+		case key == "":
+			title = "Untagged"
+		case by == "tag":
+			title = "#" + key
+		}
+		lines = append(lines, "", "## "+title, "")
+		for _, note := range groups[key] {
+			lines = append(lines, fmt.Sprintf("- [%s](%s)", note.Title, indexLinkTarget(note.Name)))
+		}
+	}
+	return joinLines(lines)
+}
+
+// indexLinkTarget is a markdown link destination for a note's filename,
+// in angle brackets when it contains spaces
+func indexLinkTarget(name string) string {
+	if strings.ContainsAny(name, " ()") {
+		return "<" + name + ">"
+	}
+	return name
+}
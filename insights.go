@@ -0,0 +1,202 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultInsightsDays is how far back --insights looks unless --days is given
+const DefaultInsightsDays = 90
+
+// InsightsMonths is how many months of note length the report shows
+const InsightsMonths = 6
+
+// dateStamp matches the -YYYYMMDD stamp of a dated note filename
+var dateStamp = regexp.MustCompile(`-(\d{8})\.md$`)
+
+// noteCount is how many times a note was opened for editing
+type noteCount struct {
+	Note  string
+	Count int
+}
+
+// monthLength is the average length of the notes written in a month
+type monthLength struct {
+	Month    time.Time
+	Notes    int
+	AvgWords int
+}
+
+// insights is the usage report behind --insights. It is computed from the
+// access history and the notes themselves, and only ever printed.
+type insights struct {
+	Since    time.Time
+	Sessions int
+	Hours    [24]int
+	TopNotes []noteCount
+	Lengths  []monthLength
+}
+
+// runInsights prints a usage report for the notes directory:
+//
+//	note --insights [--days N]
+//
+// Everything is read from local files and printed; nothing is sent anywhere.
+func runInsights(config Config, args []string) error {
+	days := DefaultInsightsDays
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--days" {
+			return usageErrorf("usage: note --insights [--days N]")
+		}
+		if i+1 >= len(args) {
+			return usageErrorf("--days requires a number of days")
+		}
+		i++
+		n, err := strconv.Atoi(args[i])
+		if err != nil || n < 1 {
+			return usageErrorf("invalid number of days '%s'", args[i])
+		}
+		days = n
+	}
+
+	now := time.Now()
+	report := computeInsights(config.NotesDir, now.AddDate(0, 0, -days), now)
+	printInsights(os.Stdout, report)
+	return nil
+}
+
+// computeInsights gathers editing sessions since the given time from the
+// access history, and the length of notes written in the last InsightsMonths
+func computeInsights(notesDir string, since, now time.Time) insights {
+	report := insights{Since: since}
+
+	counts := make(map[string]int)
+	for note, accesses := range loadAccessHistory(notesDir) {
+		for _, access := range accesses {
+			if access.Before(since) || access.After(now) {
+				continue
+			}
+			report.Sessions++
+			report.Hours[access.Hour()]++
+			counts[note]++
+		}
+	}
+	for note, count := range counts {
+		report.TopNotes = append(report.TopNotes, noteCount{Note: note, Count: count})
+	}
+	sort.Slice(report.TopNotes, func(i, j int) bool {
+		a, b := report.TopNotes[i], report.TopNotes[j]
+		return a.Count > b.Count || (a.Count == b.Count && a.Note < b.Note)
+	})
+	if len(report.TopNotes) > 10 {
+		report.TopNotes = report.TopNotes[:10]
+	}
+
+	firstMonth := time.Date(now.Year(), now.Month()-InsightsMonths+1, 1, 0, 0, 0, 0, now.Location())
+	words := make(map[time.Time][]int)
+	walkNotes(notesDir, func(path string, info os.FileInfo) error {
+		written := noteDate(filepath.Base(path), info.ModTime())
+		month := time.Date(written.Year(), written.Month(), 1, 0, 0, 0, 0, now.Location())
+		if month.Before(firstMonth) || month.After(now) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err == nil {
+			words[month] = append(words[month], len(strings.Fields(string(content))))
+		}
+		return nil
+	})
+	for month := firstMonth; !month.After(now); month = month.AddDate(0, 1, 0) {
+		counts := words[month]
+		length := monthLength{Month: month, Notes: len(counts)}
+		if len(counts) > 0 {
+			total := 0
+			for _, n := range counts {
+				total += n
+			}
+			length.AvgWords = total / len(counts)
+		}
+		report.Lengths = append(report.Lengths, length)
+	}
+	return report
+}
+
+// noteDate returns when a note was written: the date stamp in its filename,
+// or its modification time for notes without one
+func noteDate(name string, modTime time.Time) time.Time {
+	if match := dateStamp.FindStringSubmatch(name); match != nil {
+		if date, err := time.ParseInLocation("20060102", match[1], time.Local); err == nil {
+			return date
+		}
+	}
+	return modTime
+}
+
+// printInsights writes the report as text with simple bar charts
+func printInsights(w io.Writer, report insights) {
+	fmt.Fprintf(w, "Usage since %s (computed locally; nothing leaves this machine)\n\n", report.Since.Format("2006-01-02"))
+	if report.Sessions == 0 {
+		fmt.Fprintln(w, "No editing sessions recorded in this period.")
+	} else {
+		fmt.Fprintf(w, "Busiest hours (%d editing sessions):\n", report.Sessions)
+		busiest := 0
+		for _, count := range report.Hours {
+			busiest = max(busiest, count)
+		}
+		for hour, count := range report.Hours {
+			if count > 0 {
+				fmt.Fprintf(w, "  %02d:00  %-20s %d\n", hour, insightsBar(count, busiest), count)
+			}
+		}
+
+		fmt.Fprintln(w, "\nMost edited notes:")
+		for _, note := range report.TopNotes {
+			fmt.Fprintf(w, "  %4d  %s\n", note.Count, note.Note)
+		}
+	}
+
+	fmt.Fprintln(w, "\nAverage note length by month:")
+	longest := 0
+	for _, length := range report.Lengths {
+		longest = max(longest, length.AvgWords)
+	}
+	for _, length := range report.Lengths {
+		if length.Notes == 0 {
+			fmt.Fprintf(w, "  %s  %-20s no notes\n", length.Month.Format("2006-01"), "")
+			continue
+		}
+		fmt.Fprintf(w, "  %s  %-20s %d words (%d notes)\n", length.Month.Format("2006-01"), insightsBar(length.AvgWords, longest), length.AvgWords, length.Notes)
+	}
+}
+
+// insightsBar draws value as a bar up to 20 characters long, scaled to most
+func insightsBar(value, most int) string {
+	if most == 0 {
+		return ""
+	}
+	return strings.Repeat("█", max(1, value*20/most))
+}
@@ -0,0 +1,235 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JournalFile records a multi-file operation in the notes directory while it
+// runs, so one that is interrupted part way can be finished or undone with
+// --repair. It is removed once the operation completes.
+const JournalFile = ".note_journal"
+
+// journalStep is one planned move of a note
+type journalStep struct {
+	From, To string
+}
+
+// opJournal is the intent written before a multi-file operation starts:
+//
+//	archive<TAB>started
+//	move<TAB>from<TAB>to
+//
+// Progress is not logged; whether a step has run is read off the files
+// themselves, so a crash between a move and its bookkeeping cannot happen.
+type opJournal struct {
+	Operation string
+	Started   time.Time
+	Steps     []journalStep
+}
+
+// Step states as seen on disk
+const (
+	StepPending  = "pending"
+	StepDone     = "done"
+	StepConflict = "conflict" // both or neither of From and To exist
+)
+
+// state reports whether the step has run
+func (s journalStep) state() string {
+	fromExists, toExists := pathExists(s.From), pathExists(s.To)
+	switch {
+	case fromExists && !toExists:
+		return StepPending
+	case !fromExists && toExists:
+		return StepDone
+	}
+	return StepConflict
+}
+
+// pathExists reports whether path exists, without following a symlinked note
+func pathExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// journalName shows a journaled path relative to the notes directory
+func journalName(notesDir, path string) string {
+	if rel, err := filepath.Rel(notesDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+func journalPath(notesDir string) string {
+	return filepath.Join(notesDir, JournalFile)
+}
+
+// beginJournal records the steps of an operation before any of them run. It
+// refuses to start while an earlier operation is still waiting for repair.
+func beginJournal(notesDir, operation string, steps []journalStep) error {
+	path := journalPath(notesDir)
+	return withStateLock(path, func() error {
+		if pending, err := loadJournal(notesDir); err != nil {
+			return err
+		} else if pending != nil {
+			return fmt.Errorf("an interrupted %s is waiting; run 'note --repair' first", pending.Operation)
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s\t%d\n", operation, time.Now().Unix())
+		for _, step := range steps {
+			fmt.Fprintf(&b, "move\t%s\t%s\n", step.From, step.To)
+		}
+		if err := writeFileAtomic(path, []byte(b.String()), 0644); err != nil {
+			return fmt.Errorf("error writing journal: %w", err)
+		}
+		return nil
+	})
+}
+
+// finishJournal removes the journal once every step has run
+func finishJournal(notesDir string) {
+	os.Remove(journalPath(notesDir))
+}
+
+// loadJournal returns the pending journal, or nil when there is none
+func loadJournal(notesDir string) (*opJournal, error) {
+	file, err := os.Open(journalPath(notesDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading journal: %w", err)
+	}
+	defer file.Close()
+
+	journal := &opJournal{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.Split(scanner.Text(), "\t")
+		switch {
+		case journal.Operation == "" && len(parts) == 2:
+			started, _ := strconv.ParseInt(parts[1], 10, 64)
+			journal.Operation, journal.Started = parts[0], time.Unix(started, 0)
+		case len(parts) == 3 && parts[0] == "move":
+			journal.Steps = append(journal.Steps, journalStep{From: parts[1], To: parts[2]})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading journal: %w", err)
+	}
+	if journal.Operation == "" {
+		journal.Operation = "operation"
+	}
+	return journal, nil
+}
+
+// runRepair shows, finishes or undoes an interrupted multi-file operation:
+//
+//	note --repair
+//	note --repair resume
+//	note --repair rollback
+func runRepair(config Config, args []string) error {
+	journal, err := loadJournal(config.NotesDir)
+	if err != nil {
+		return err
+	}
+	if journal == nil {
+		fmt.Println("Nothing to repair")
+		return nil
+	}
+
+	if len(args) == 0 {
+		done := 0
+		for _, step := range journal.Steps {
+			if step.state() == StepDone {
+				done++
+			}
+		}
+		fmt.Printf("Interrupted %s from %s: %d of %d steps done\n", journal.Operation, journal.Started.Format("2006-01-02 15:04"), done, len(journal.Steps))
+		for _, step := range journal.Steps {
+			fmt.Printf("  %-8s  %s -> %s\n", step.state(), journalName(config.NotesDir, step.From), journalName(config.NotesDir, step.To))
+		}
+		fmt.Println("\nRun 'note --repair resume' to finish it or 'note --repair rollback' to undo it")
+		return nil
+	}
+
+	var rollback bool
+	switch args[0] {
+	case "resume":
+	case "rollback":
+		rollback = true
+	default:
+		return fmt.Errorf("unknown repair command '%s' (use resume or rollback)", args[0])
+	}
+	moved, err := repairJournal(config.NotesDir, journal, rollback)
+	if err != nil {
+		return err
+	}
+	verb := "Finished"
+	if rollback {
+		verb = "Rolled back"
+	}
+	fmt.Printf("%s the interrupted %s (%d note(s) moved)\n", verb, journal.Operation, moved)
+	return nil
+}
+
+// repairJournal runs the pending steps of journal, or undoes the finished
+// ones in reverse order when rollback is set, and removes the journal once
+// that succeeds. Steps in conflict are reported and keep the journal.
+func repairJournal(notesDir string, journal *opJournal, rollback bool) (int, error) {
+	moved, failed := 0, 0
+	for i := range journal.Steps {
+		step := journal.Steps[i]
+		from, to, want := step.From, step.To, StepPending
+		if rollback {
+			step = journal.Steps[len(journal.Steps)-1-i]
+			from, to, want = step.To, step.From, StepDone
+		}
+
+		switch step.state() {
+		case want:
+			err := os.MkdirAll(filepath.Dir(to), 0755)
+			if err == nil {
+				err = moveNote(from, to)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error moving %s: %v\n", from, err)
+				failed++
+				continue
+			}
+			moved++
+		case StepConflict:
+			fmt.Fprintf(os.Stderr, "Warning: %s and %s both or neither exist; resolve by hand\n", step.From, step.To)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return moved, fmt.Errorf("%d step(s) could not be repaired; the journal is kept", failed)
+	}
+	finishJournal(notesDir)
+	return moved, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"note/pkg/notes"
+)
+
+// jsonNote is one note in --json output
+type jsonNote struct {
+	Name     string              `json:"name"` // relative to the notes directory
+	Path     string              `json:"path"`
+	Modified time.Time           `json:"modified"`
+	Size     int64               `json:"size"`
+	Title    string              `json:"title,omitempty"`
+	Matches  []notes.SearchMatch `json:"matches,omitempty"`
+}
+
+// printJSON prints list (-l/-a) or search (-s) results as a JSON array for
+// scripts
+func printJSON(config Config, flags *ParsedFlags, pattern string) error {
+	items, err := jsonResults(config, flags, pattern)
+	if err != nil {
+		return err
+	}
+	return writeJSON(os.Stdout, items)
+}
+
+// jsonResults returns the notes -l/-a list, or those -s finds along with
+// every matching line rather than the first few
+func jsonResults(config Config, flags *ParsedFlags, pattern string) ([]jsonNote, error) {
+	items := []jsonNote{}
+	if flags.Search == "" {
+		names, err := sortNotes(config, collectNotes(config, pattern, flags.Archive), flags.Sort, flags.Reverse)
+		if err != nil {
+			return nil, err
+		}
+		for _, note := range names {
+			items = append(items, newJSONNote(config, note))
+		}
+		return items, nil
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	results, err := searchDirs(ctx, config, searchRoots(config, flags.Archive), flags.SearchTerms)
+	if err != nil {
+		return nil, fmt.Errorf("search %w", errInterrupted)
+	}
+	for _, result := range results {
+		item := newJSONNote(config, result.Path)
+		item.Title = result.Title
+		item.Matches = result.Matches
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// newJSONNote describes the note at name, relative to the notes directory
+func newJSONNote(config Config, name string) jsonNote {
+	item := jsonNote{Name: name, Path: filepath.Join(config.NotesDir, name)}
+	if info, err := os.Stat(item.Path); err == nil {
+		item.Modified = info.ModTime()
+		item.Size = info.Size()
+	}
+	return item
+}
+
+// writeJSON writes v as indented JSON followed by a newline
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// launcherItem is one note surfaced to a desktop launcher
+type launcherItem struct {
+	Title    string // note name without .md
+	Subtitle string // path relative to the notes dir, or the first match
+	Path     string // absolute path, passed back to the launcher's action
+}
+
+// printFormatted prints list (-l/-a) or search (-s) results in a launcher
+// format selected with --format
+func printFormatted(config Config, flags *ParsedFlags, pattern string) error {
+	var items []launcherItem
+	if flags.Search != "" {
+		ctx, stop := interruptible()
+		defer stop()
+		results, err := findSearchResults(ctx, config, flags.Search, flags.Archive)
+		if err != nil {
+			return fmt.Errorf("search %w", errInterrupted)
+		}
+		for _, result := range results {
+			items = append(items, launcherItem{
+				Title:    strings.TrimSuffix(filepath.Base(result.Path), ".md"),
+				Subtitle: fmt.Sprintf("%d: %s", result.Matches[0].Line, strings.TrimSpace(result.Matches[0].Text)),
+				Path:     filepath.Join(config.NotesDir, result.Path),
+			})
+		}
+	} else {
+		for _, note := range collectNotes(config, pattern, flags.Archive) {
+			items = append(items, launcherItem{
+				Title:    strings.TrimSuffix(filepath.Base(note), ".md"),
+				Subtitle: note,
+				Path:     filepath.Join(config.NotesDir, note),
+			})
+		}
+	}
+	return writeLauncherItems(os.Stdout, items, flags.Format)
+}
+
+// writeLauncherItems writes items in the exact format a launcher expects
+func writeLauncherItems(w io.Writer, items []launcherItem, format string) error {
+	switch format {
+	case "alfred":
+		return writeAlfredItems(w, items)
+	case "rofi":
+		// Rofi script mode: display text, then the path as hidden row info
+		for _, item := range items {
+			fmt.Fprintf(w, "%s\x00info\x1f%s\n", item.Title, item.Path)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format '%s' (use alfred or rofi)", format)
+}
+
+// alfredItem follows Alfred's Script Filter JSON format
+type alfredItem struct {
+	UID          string `json:"uid"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	Subtitle     string `json:"subtitle"`
+	Arg          string `json:"arg"`
+	Autocomplete string `json:"autocomplete"`
+}
+
+func writeAlfredItems(w io.Writer, items []launcherItem) error {
+	output := struct {
+		Items []alfredItem `json:"items"`
+	}{Items: []alfredItem{}}
+
+	for _, item := range items {
+		output.Items = append(output.Items, alfredItem{
+			UID:          item.Path,
+			Type:         "file",
+			Title:        item.Title,
+			Subtitle:     item.Subtitle,
+			Arg:          item.Path,
+			Autocomplete: item.Title,
+		})
+	}
+	return json.NewEncoder(w).Encode(output)
+}
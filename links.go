@@ -0,0 +1,92 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// linkCandidate is a possible [[wiki-link]] target
+type linkCandidate struct {
+	Target  string
+	score   int
+	modTime time.Time
+}
+
+// runCompleteLinks prints [[wiki-link]] targets starting with prefix, best
+// candidates first, for editor plugins completing links while typing
+func runCompleteLinks(config Config, prefix string) error {
+	for _, candidate := range linkCandidates(config.NotesDir, prefix, time.Now()) {
+		fmt.Println(candidate.Target)
+	}
+	return nil
+}
+
+// linkCandidates returns note names (without .md) and titles matching
+// prefix, ranked by frecency and then by modification time. Prefix matches
+// rank ahead of matches elsewhere in the name.
+func linkCandidates(notesDir, prefix string, now time.Time) []linkCandidate {
+	history := loadAccessHistory(notesDir)
+	titles := loadTitleIndex(notesDir)
+	lowerPrefix := strings.ToLower(prefix)
+
+	var prefixMatches, otherMatches []linkCandidate
+	seen := make(map[string]bool)
+	for _, note := range findMatchingNotes(notesDir, "", false) {
+		var modTime time.Time
+		if info, err := os.Stat(filepath.Join(notesDir, note)); err == nil {
+			modTime = info.ModTime()
+		}
+		score := frecencyScore(history[note], now)
+
+		for _, target := range []string{strings.TrimSuffix(note, ".md"), titles[note]} {
+			if target == "" || seen[target] {
+				continue
+			}
+			lowerTarget := strings.ToLower(target)
+			candidate := linkCandidate{Target: target, score: score, modTime: modTime}
+			if strings.HasPrefix(lowerTarget, lowerPrefix) {
+				prefixMatches = append(prefixMatches, candidate)
+				seen[target] = true
+			} else if strings.Contains(lowerTarget, lowerPrefix) {
+				otherMatches = append(otherMatches, candidate)
+				seen[target] = true
+			}
+		}
+	}
+
+	rank := func(candidates []linkCandidate) {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].score != candidates[j].score {
+				return candidates[i].score > candidates[j].score
+			}
+			if !candidates[i].modTime.Equal(candidates[j].modTime) {
+				return candidates[i].modTime.After(candidates[j].modTime)
+			}
+			return candidates[i].Target < candidates[j].Target
+		})
+	}
+	rank(prefixMatches)
+	rank(otherMatches)
+	return append(prefixMatches, otherMatches...)
+}
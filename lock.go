@@ -0,0 +1,147 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// runLock marks a note as finalized: it gets a "locked: true" frontmatter
+// flag and is made read-only
+func runLock(config Config, noteName string) error {
+	notePath, err := existingNotePath(config, noteName)
+	if err != nil {
+		return err
+	}
+	if isEncryptedNote(notePath) {
+		return encryptedNoteError(notePath)
+	}
+	if isNoteLocked(notePath) {
+		fmt.Printf("%s is already locked\n", filepath.Base(notePath))
+		return nil
+	}
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+	updated := setFrontmatterValue(string(content), "locked", "true")
+	if err := writeFileAtomic(notePath, []byte(updated), notePerm(notePath)&^0222); err != nil {
+		return fmt.Errorf("error locking %s: %w", filepath.Base(notePath), err)
+	}
+	fmt.Printf("Locked %s\n", filepath.Base(notePath))
+	return nil
+}
+
+// runUnlock reverses runLock
+func runUnlock(config Config, noteName string) error {
+	notePath, err := existingNotePath(config, noteName)
+	if err != nil {
+		return err
+	}
+	if isEncryptedNote(notePath) {
+		return encryptedNoteError(notePath)
+	}
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+	updated := removeFrontmatterValue(string(content), "locked")
+	if err := writeFileAtomic(notePath, []byte(updated), notePerm(notePath)|0200); err != nil {
+		return fmt.Errorf("error unlocking %s: %w", filepath.Base(notePath), err)
+	}
+	fmt.Printf("Unlocked %s\n", filepath.Base(notePath))
+	return nil
+}
+
+// isNoteLocked reports whether a note carries the locked frontmatter flag
+func isNoteLocked(notePath string) bool {
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return false
+	}
+	return parseFrontmatter(string(content))["locked"] == "true"
+}
+
+// checkNoteWritable refuses to modify an encrypted note, which would be
+// left as plaintext mixed into ciphertext, and a locked note unless forced.
+// With force, a read-only locked note is made writable again so the edit
+// can be saved; callers should pass the result to restoreLock afterwards.
+func checkNoteWritable(notePath string, force bool) (wasLocked bool, err error) {
+	if isEncryptedNote(notePath) {
+		return false, encryptedNoteError(notePath)
+	}
+	if !isNoteLocked(notePath) {
+		return false, nil
+	}
+	if !force {
+		return true, fmt.Errorf("%s is locked; use --force to modify it anyway, or --unlock it", filepath.Base(notePath))
+	}
+	fmt.Fprintf(os.Stderr, "⚠ Warning: %s is locked, modifying it because of --force\n", filepath.Base(notePath))
+	if err := os.Chmod(notePath, notePerm(notePath)|0200); err != nil {
+		return true, fmt.Errorf("error making %s writable: %w", filepath.Base(notePath), err)
+	}
+	return true, nil
+}
+
+// checkNoteEditable is checkNoteWritable for a note about to be opened in
+// the editor, which decrypts and encrypts encrypted notes itself
+func checkNoteEditable(notePath string, force bool) (wasLocked bool, err error) {
+	if isEncryptedNote(notePath) {
+		return false, nil
+	}
+	return checkNoteWritable(notePath, force)
+}
+
+// encryptedNoteError explains that an encrypted note can only be changed
+// in the editor
+func encryptedNoteError(notePath string) error {
+	return fmt.Errorf("%s is encrypted; open it with note to change it", filepath.Base(notePath))
+}
+
+// restoreLock makes a forced-open locked note read-only again
+func restoreLock(notePath string, wasLocked bool) {
+	if wasLocked && isNoteLocked(notePath) {
+		os.Chmod(notePath, notePerm(notePath)&^0222)
+	}
+}
+
+// notePerm returns the permissions to write the note at notePath back
+// with: its own, so a note kept private stays private, or 0644 for a note
+// that doesn't exist yet
+func notePerm(notePath string) os.FileMode {
+	if info, err := os.Stat(notePath); err == nil {
+		return info.Mode().Perm()
+	}
+	return 0644
+}
+
+// existingNotePath resolves a note name and fails if the note doesn't exist
+func existingNotePath(config Config, noteName string) (string, error) {
+	if noteName == "" {
+		return "", fmt.Errorf("a note name is required")
+	}
+	notePath := resolveNotePath(config, noteName)
+	if _, err := os.Stat(notePath); err != nil {
+		return "", fmt.Errorf("note '%s' not found", noteName)
+	}
+	return notePath, nil
+}
@@ -19,6 +19,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -27,11 +28,23 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"note/pkg/notes"
 )
 
-type Config struct {
-	Editor   string
-	NotesDir string
+// Config is the parsed ~/.note. It is the library's config with the CLI's
+// own accessors, which decrypt values stored with --encrypt-config.
+type Config notes.Config
+
+// option returns the value of an optional config setting, or "" if unset.
+// Values stored with --encrypt-config are decrypted transparently.
+func (c Config) option(key string) string {
+	return decryptOption(key, c.Options[key])
+}
+
+// boolOption reports whether an optional config setting is switched on
+func (c Config) boolOption(key string) bool {
+	return isTrue(c.option(key))
 }
 
 var (
@@ -42,10 +55,22 @@ var (
 
 // ANSI color codes for terminal highlighting
 const (
-	ColorRed   = "\033[31m"
-	ColorReset = "\033[0m"
+	ColorRed     = "\033[31m"
+	ColorGreen   = "\033[32m"
+	ColorYellow  = "\033[33m"
+	ColorBlue    = "\033[34m"
+	ColorMagenta = "\033[35m"
+	ColorCyan    = "\033[36m"
+	ColorReset   = "\033[0m"
+
+	// ColorFilenameMatch marks the part of a filename that matched, so it
+	// stands apart from matches in the note's content
+	ColorFilenameMatch = "\033[1;4m"
 )
 
+// HighlightPalette gives each search term its own color, in order
+var HighlightPalette = []string{ColorRed, ColorGreen, ColorYellow, ColorBlue, ColorMagenta, ColorCyan}
+
 // isOutputToTerminal checks if stdout is a terminal (not piped)
 func isOutputToTerminal() bool {
 	fileInfo, err := os.Stdout.Stat()
@@ -55,89 +80,192 @@ func isOutputToTerminal() bool {
 	return (fileInfo.Mode() & os.ModeCharDevice) != 0
 }
 
+// isInputFromTerminal checks if stdin is a terminal (not piped or redirected)
+func isInputFromTerminal() bool {
+	fileInfo, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return (fileInfo.Mode() & os.ModeCharDevice) != 0
+}
+
 // highlightTerm highlights the search term in the text with red color
 func highlightTerm(text, term string) string {
-	if term == "" || !isOutputToTerminal() {
+	return highlightTerms(text, []string{term})
+}
+
+// highlightTerms highlights each term in the text in its own color from
+// HighlightPalette
+func highlightTerms(text string, terms []string) string {
+	if !isOutputToTerminal() {
 		return text
 	}
+	return colorizeTerms(text, terms, HighlightPalette)
+}
 
-	// Case-insensitive highlighting
+// colorizeTerms wraps every case-insensitive occurrence of each term in
+// the color at the term's position in palette, cycling through the palette
+// when there are more terms than colors. Where matches overlap, the term
+// given first wins.
+func colorizeTerms(text string, terms []string, palette []string) string {
 	lowerText := strings.ToLower(text)
-	lowerTerm := strings.ToLower(term)
-
-	// Find all occurrences and highlight them
-	result := text
-	startPos := 0
-	for {
-		pos := strings.Index(lowerText[startPos:], lowerTerm)
-		if pos == -1 {
-			break
+	if len(lowerText) != len(text) {
+		// Lowercasing changed byte offsets, so matches can't be mapped back
+		return text
+	}
+	// colorAt holds the palette index (plus one) covering each byte
+	colorAt := make([]int, len(text))
+	for i, term := range terms {
+		lowerTerm := strings.ToLower(term)
+		if lowerTerm == "" {
+			continue
 		}
-
-		actualPos := startPos + pos
-
-		// Bounds checking to prevent panic
-		if actualPos+len(term) > len(result) {
-			break
+		for start := 0; start < len(lowerText); {
+			pos := strings.Index(lowerText[start:], lowerTerm)
+			if pos == -1 {
+				break
+			}
+			pos += start
+			free := true
+			for j := pos; j < pos+len(term); j++ {
+				if colorAt[j] != 0 {
+					free = false
+					break
+				}
+			}
+			if free {
+				for j := pos; j < pos+len(term); j++ {
+					colorAt[j] = i%len(palette) + 1
+				}
+			}
+			start = pos + len(term)
 		}
+	}
 
-		// Preserve original case in the highlight
-		originalTerm := result[actualPos : actualPos+len(term)]
-		highlighted := ColorRed + originalTerm + ColorReset
-
-		result = result[:actualPos] + highlighted + result[actualPos+len(term):]
+	var b strings.Builder
+	current := 0
+	for i := 0; i < len(text); i++ {
+		if colorAt[i] != current {
+			if current != 0 {
+				b.WriteString(ColorReset)
+			}
+			if colorAt[i] != 0 {
+				b.WriteString(palette[colorAt[i]-1])
+			}
+			current = colorAt[i]
+		}
+		b.WriteByte(text[i])
+	}
+	if current != 0 {
+		b.WriteString(ColorReset)
+	}
+	return b.String()
+}
 
-		// Adjust positions accounting for added color codes
-		colorCodeLength := len(ColorRed) + len(ColorReset)
-		startPos = actualPos + len(term) + colorCodeLength
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		os.Exit(reportError(err))
+	}
+}
 
-		// Update lowerText to match result changes
-		lowerText = strings.ToLower(result)
+// run carries out one invocation of note. Errors come back here rather than
+// exiting where they happen, so deferred cleanup such as relocking notes
+// always runs, and main reports them the same way with a consistent exit code.
+func run(osArgs []string) error {
+	cliArgs, err := extractGlobalFlags(osArgs)
+	if err != nil {
+		return err
 	}
 
-	return result
-}
+	// Importing a config bundle has to work on a machine with no ~/.note yet
+	if args := cliArgs; len(args) > 1 && (args[0] == "--config" || args[0] == "--configure") {
+		return runConfigBundle(args[1:])
+	}
 
-func main() {
-	config, firstTimeSetup := loadOrCreateConfig()
+	config, firstTimeSetup, err := loadOrCreateConfig()
+	if err != nil {
+		return err
+	}
 
 	// If first-time setup was just completed, exit gracefully
 	if firstTimeSetup {
-		return
+		return nil
 	}
 
 	// Parse custom flags with Unix-like behavior
-	flags, args := parseFlags(os.Args[1:])
+	flags, args, err := parseFlags(applyDefaults(config.Defaults, cliArgs))
+	if err != nil {
+		return err
+	}
 
 	// Handle version number
 	if flags.Version {
-		printVersion()
-		return
+		printVersion(config)
+		return nil
 	}
 
 	// Handle help
 	if flags.Help {
 		printHelp()
-		return
+		return nil
 	}
 
 	// Handle config
 	if flags.Config {
-		runSetup()
-		// Explicitly exit after config to prevent any further execution
-		os.Exit(0)
+		_, err := runSetup()
+		return err
 	}
 
 	// Handle autocomplete setup
 	if flags.Autocomplete {
 		RunAutocompleteSetup()
-		return
+		return nil
 	}
 
 	// Handle alias setup
 	if flags.Alias {
 		RunAliasSetup()
-		return
+		return nil
+	}
+
+	// Handle commands given as long flags
+	if flags.Command != "" {
+		return runCommand(config, flags, args)
+	}
+
+	// Handle quick capture
+	if flags.Capture {
+		return runCapture(config, flags, args)
+	}
+
+	// Handle the daily journal
+	if flags.Journal {
+		return runJournal(config, flags, args)
+	}
+
+	// Handle the interactive browser
+	if flags.Interactive {
+		return runBrowse(config, flags, strings.Join(args, " "))
+	}
+
+	// Handle the fuzzy finder
+	if flags.Find {
+		return runFind(config, flags, strings.Join(args, " "))
+	}
+
+	// Handle tags, alone or narrowing a search
+	if flags.Tags {
+		return runTags(config, flags, args)
+	}
+
+	// Handle JSON output for listing and search
+	if jsonOutput && (flags.List || flags.Archive || flags.Search != "") {
+		return printJSON(config, flags, strings.Join(args, " "))
+	}
+
+	// Handle launcher output formats for listing and search
+	if flags.Format != "" && (flags.List || flags.Archive || flags.Search != "") {
+		return printFormatted(config, flags, strings.Join(args, " "))
 	}
 
 	// Handle combined archive + list or search
@@ -146,14 +274,12 @@ func main() {
 		if len(args) > 0 {
 			pattern = strings.Join(args, " ")
 		}
-		listNotes(config, pattern, true)
-		return
+		return listNotes(config, flags, pattern, true)
 	}
 
 	// Handle combined archive + search
 	if flags.Archive && flags.Search != "" {
-		searchNotes(config, flags.Search, true)
-		return
+		return searchNotes(config, flags.SearchTerms, true)
 	}
 
 	// Handle listing
@@ -162,8 +288,7 @@ func main() {
 		if len(args) > 0 {
 			pattern = strings.Join(args, " ")
 		}
-		listNotes(config, pattern, false)
-		return
+		return listNotes(config, flags, pattern, false)
 	}
 
 	// Handle archive listing only
@@ -172,115 +297,347 @@ func main() {
 		if len(args) > 0 {
 			pattern = strings.Join(args, " ")
 		}
-		listNotes(config, pattern, true)
-		return
+		return listNotes(config, flags, pattern, true)
 	}
 
 	// Handle full-text search
 	if flags.Search != "" {
-		searchNotes(config, flags.Search, false)
-		return
+		return searchNotes(config, flags.SearchTerms, false)
 	}
 
-	// Handle archive/delete
+	// Handle archive/delete; moving notes holds the notes lock
+	if flags.Notebook != "" {
+		return withNotesLock(config.NotesDir, func() error { return archiveNotebook(config, flags, flags.Notebook) })
+	}
 	if flags.Delete != "" {
-		archiveNotes(config, flags.Delete)
-		return
+		return withNotesLock(config.NotesDir, func() error { return archiveNotes(config, flags.Delete) })
 	}
 
 	// Handle note creation/opening
 	if len(args) == 0 {
 		// No arguments, just run note without args (could open today's journal or show help)
 		printHelp()
-		return
+		return nil
 	}
 
 	// Join all arguments to handle spaces in note names
 	noteName := strings.Join(args, " ")
-	openOrCreateNote(config, noteName)
+	return openOrCreateNote(config, noteName, flags.Force, flags.Context)
+}
+
+// runCommand dispatches commands selected by long flags such as --new
+func runCommand(config Config, flags *ParsedFlags, args []string) error {
+	switch flags.Command {
+	case "new":
+		return runNewNote(config, flags, args)
+	case "append":
+		return runAppend(config, flags, args)
+	case "spell":
+		return runSpell(config, strings.Join(args, " "))
+	case "fzf":
+		return runFzfCandidates(config, strings.Join(args, " "), flags.Archive)
+	case "pick":
+		return runPick(config, strings.Join(args, " "), flags.Archive, flags.Force)
+	case "cat":
+		return runCat(config, strings.Join(args, " "))
+	case "rpc":
+		return runRPC(config)
+	case "complete-emoji":
+		return runCompleteEmoji(strings.Join(args, ""))
+	case "complete-links":
+		return runCompleteLinks(config, strings.Join(args, " "))
+	case "complete-tags":
+		return runCompleteTags(config, strings.Join(args, ""))
+	case "summarize":
+		return runSummarize(config, strings.Join(args, " "))
+	case "ask":
+		return runAsk(config, flags, strings.Join(args, " "))
+	case "diagrams":
+		return runDiagrams(config, args)
+	case "export":
+		return runExport(config, args)
+	case "open-asset":
+		return runOpenAsset(config, args)
+	case "refs":
+		return withNotesLock(config.NotesDir, func() error { return runRefs(config, flags, args) })
+	case "board":
+		return runBoard(config, args)
+	case "outline":
+		return runOutline(config, flags, strings.Join(args, " "))
+	case "clock":
+		return runClock(config, flags, args)
+	case "focus":
+		return runFocus(config, flags, args)
+	case "habit":
+		return runHabit(config, flags, args)
+	case "read":
+		return runRead(config, flags, args)
+	case "quote":
+		return runQuote(config, flags, args)
+	case "quotes":
+		return runQuotes(config, args)
+	case "compact":
+		return runCompact(config, args)
+	case "checklist":
+		return runChecklist(config, flags, args)
+	case "exec":
+		return runExec(config, flags, args)
+	case "run":
+		return runCapturedCommand(config, flags, args)
+	case "ocr":
+		return runOCR(config, flags, args)
+	case "paste-image":
+		return runPasteImage(config, flags, args)
+	case "transcribe":
+		return runTranscribe(config, flags, args)
+	case "meeting":
+		return runMeeting(config, flags, args)
+	case "project":
+		return runProject(config, args)
+	case "index":
+		return runIndex(config, args)
+	case "person":
+		return runPerson(config, flags, strings.Join(args, " "))
+	case "mentions":
+		return runMentions(config, flags, strings.Join(args, " "))
+	case "scheduled":
+		return runScheduled(config)
+	case "next":
+		return runNext(config, flags, strings.Join(args, " "))
+	case "snippet":
+		return runSnippet(config, flags, args)
+	case "inbox":
+		return runInbox(config, args)
+	case "refile":
+		return runRefile(config)
+	case "bridge":
+		return runBridge(config, args)
+	case "serve":
+		return runServe(config, args)
+	case "serve-token":
+		return runServeToken(args)
+	case "share-link":
+		return runShareLink(config, args)
+	case "encrypt-config":
+		return runEncryptConfig(config, args)
+	case "encrypt":
+		return runEncrypt(config, args)
+	case "archive":
+		return runArchive(config, args)
+	case "restore":
+		return withNotesLock(config.NotesDir, func() error { return runRestore(config, args) })
+	case "delete":
+		return runDelete(config, flags, args)
+	case "purge":
+		return runPurge(config, args)
+	case "empty-trash":
+		return runEmptyTrash(config, args)
+	case "backup":
+		return runBackup(config, args)
+	case "recover":
+		return runRecover(config, strings.Join(args, " "))
+	case "repair":
+		return runRepair(config, args)
+	case "fsck":
+		return runFsck(config, args)
+	case "mark":
+		return runMark(config, args)
+	case "resume":
+		return runResume(config, flags, strings.Join(args, " "))
+	case "print":
+		return runPrint(config, args)
+	case "history":
+		return runHistory(config, strings.Join(args, " "))
+	case "revert":
+		return withNotesLock(config.NotesDir, func() error { return runRevert(config, flags, args) })
+	case "annotate":
+		return runAnnotate(config, args)
+	case "diff":
+		return runDiff(config, args)
+	case "autolink":
+		return runAutolink(config, flags, args)
+	case "changes":
+		return runChanges(config, args)
+	case "report":
+		return runReport(config, flags, args)
+	case "meta":
+		return withNotesLock(config.NotesDir, func() error { return runMeta(config, flags, args) })
+	case "mv":
+		return withNotesLock(config.NotesDir, func() error { return runMove(config, flags, args) })
+	case "agenda":
+		return runAgenda(config, flags, args)
+	case "split":
+		return withNotesLock(config.NotesDir, func() error { return runSplit(config, flags, args) })
+	case "tmp":
+		return runTmp(config, flags, args)
+	case "scratch":
+		return runScratch(config, flags, args)
+	case "ref":
+		return runRef(config, flags, args)
+	case "expire":
+		return runExpire(config, flags, args)
+	case "insights":
+		return runInsights(config, args)
+	case "reindex":
+		return runReindex(config)
+	case "pop":
+		return runPop(config, args)
+	case "alias-note":
+		return runAliasNote(config, args)
+	case "lock":
+		return withNotesLock(config.NotesDir, func() error { return runLock(config, strings.Join(args, " ")) })
+	case "unlock":
+		return withNotesLock(config.NotesDir, func() error { return runUnlock(config, strings.Join(args, " ")) })
+	}
+	return fmt.Errorf("unknown command --%s", flags.Command)
+}
+
+// Overrides from --config-file and --notes-dir. They are read before the
+// config is loaded, so tests and experiments can point note at temporary
+// directories instead of ~/.note and the configured notes.
+var (
+	configFileOverride string
+	notesDirOverride   string
+)
+
+// extractGlobalFlags removes --config-file and --notes-dir and their values
+// from args, recording them as overrides, and --json, which switches errors
+// and listings to JSON
+func extractGlobalFlags(args []string) ([]string, error) {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		var target *string
+		switch args[i] {
+		case "--json":
+			jsonOutput = true
+			continue
+		case "--config-file":
+			target = &configFileOverride
+		case "--notes-dir":
+			target = &notesDirOverride
+		default:
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, usageErrorf("%s requires a path", args[i])
+		}
+		i++
+		path, err := filepath.Abs(expandPath(args[i]))
+		if err != nil {
+			return nil, err
+		}
+		*target = path
+	}
+	return rest, nil
+}
+
+// globalFlagArgs returns the overrides in effect as arguments, for passing
+// on when note runs itself (e.g. as the fzf preview)
+func globalFlagArgs() []string {
+	var args []string
+	if configFileOverride != "" {
+		args = append(args, "--config-file", configFileOverride)
+	}
+	if notesDirOverride != "" {
+		args = append(args, "--notes-dir", notesDirOverride)
+	}
+	return args
 }
 
-func loadOrCreateConfig() (Config, bool) {
+// configFilePath returns the config file: --config-file if given, or
+// ~/.note
+func configFilePath() (string, error) {
+	if configFileOverride != "" {
+		return configFileOverride, nil
+	}
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home directory: %v\n", err)
-		os.Exit(1)
+		return "", fmt.Errorf("error getting home directory: %w", err)
 	}
+	return filepath.Join(homeDir, ".note"), nil
+}
 
-	configPath := filepath.Join(homeDir, ".note")
+func loadOrCreateConfig() (Config, bool, error) {
+	configPath, err := configFilePath()
+	if err != nil {
+		return Config{}, false, err
+	}
 
 	// Check if config exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		// --notes-dir is enough to run without any config, e.g. in tests
+		if notesDirOverride != "" {
+			config, err := overrideNotesDir(Config{Editor: fallbackEditor()})
+			return config, false, err
+		}
 		// First run, create config
-		return runSetup(), true
+		config, err := runSetup()
+		return config, true, err
 	}
 
 	// Load existing config
 	file, err := os.Open(configPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening config: %v\n", err)
-		os.Exit(1)
+		return Config{}, false, fmt.Errorf("error opening config: %w", err)
 	}
 	defer file.Close()
 
-	config := Config{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		switch key {
-		case "editor":
-			config.Editor = value
-		case "notesdir":
-			config.NotesDir = expandPath(value)
-		}
-	}
+	config := parseConfig(file)
 
 	if config.Editor == "" || config.NotesDir == "" {
 		fmt.Println("Invalid config file. Running setup...")
-		return runSetup(), false
+		config, err := runSetup()
+		return config, false, err
 	}
 
-	return config, false
+	config, err = overrideNotesDir(config)
+	return config, false, err
+}
+
+// overrideNotesDir applies --notes-dir, creating the directory if needed
+func overrideNotesDir(config Config) (Config, error) {
+	if notesDirOverride == "" {
+		return config, nil
+	}
+	if err := os.MkdirAll(notesDirOverride, 0755); err != nil {
+		return config, fmt.Errorf("error creating notes directory: %w", err)
+	}
+	config.NotesDir = notesDirOverride
+	return config, nil
 }
 
-func runSetup() Config {
+// fallbackEditor is $EDITOR, or vim
+func fallbackEditor() string {
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "vim"
+}
+
+// parseConfig reads ~/.note: key=value settings, optionally followed by a
+// [defaults] section of flags applied to every invocation and a [schedule]
+// section of recurring notes
+func parseConfig(r io.Reader) Config {
+	return Config(notes.ParseConfig(r))
+}
+
+func runSetup() (Config, error) {
 	reader := bufio.NewReader(os.Stdin)
 	config := Config{}
 
 	// Get current values if they exist
-	homeDir, _ := os.UserHomeDir()
-	configPath := filepath.Join(homeDir, ".note")
+	configPath, _ := configFilePath()
 	if file, err := os.Open(configPath); err == nil {
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := scanner.Text()
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				switch strings.TrimSpace(parts[0]) {
-				case "editor":
-					config.Editor = strings.TrimSpace(parts[1])
-				case "notesdir":
-					config.NotesDir = expandPath(strings.TrimSpace(parts[1]))
-				}
-			}
-		}
+		// Settings setup doesn't prompt for are kept as they are
+		config = parseConfig(file)
 		file.Close()
 	}
 
 	// Ask for editor
 	defaultEditor := config.Editor
 	if defaultEditor == "" {
-		defaultEditor = os.Getenv("EDITOR")
-		if defaultEditor == "" {
-			defaultEditor = "vim"
-		}
+		defaultEditor = fallbackEditor()
 	}
 
 	for {
@@ -356,15 +713,13 @@ func runSetup() Config {
 
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(config.NotesDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating notes directory: %v\n", err)
-		os.Exit(1)
+		return config, fmt.Errorf("error creating notes directory: %w", err)
 	}
 
 	// Create Archive directory
 	archiveDir := getArchiveDir(config.NotesDir)
 	if err := os.MkdirAll(archiveDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating archive directory: %v\n", err)
-		os.Exit(1)
+		return config, fmt.Errorf("error creating archive directory: %w", err)
 	}
 
 	// Ask about command line completion
@@ -374,33 +729,72 @@ func runSetup() Config {
 	setupAliases(reader)
 
 	// Save config
-	saveConfig(config)
-	return config
+	return config, saveConfig(config)
 }
 
-func saveConfig(config Config) {
-	homeDir, err := os.UserHomeDir()
+func saveConfig(config Config) error {
+	configPath, err := configFilePath()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home directory: %v\n", err)
-		os.Exit(1)
+		return err
 	}
+	homeDir, _ := os.UserHomeDir()
 
-	configPath := filepath.Join(homeDir, ".note")
-	file, err := os.Create(configPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating config file: %v\n", err)
-		os.Exit(1)
+	// Write through a symlinked ~/.note (e.g. from a dotfiles repo) rather
+	// than replacing the link
+	if target, err := filepath.EvalSymlinks(configPath); err == nil {
+		configPath = target
 	}
-	defer file.Close()
+	var file strings.Builder
 
 	// Convert absolute path back to ~ notation for config file
 	notesDir := config.NotesDir
-	if strings.HasPrefix(notesDir, homeDir) {
+	if homeDir != "" && strings.HasPrefix(notesDir, homeDir) {
 		notesDir = "~" + strings.TrimPrefix(notesDir, homeDir)
 	}
 
-	fmt.Fprintf(file, "editor=%s\n", config.Editor)
-	fmt.Fprintf(file, "notesdir=%s\n", notesDir)
+	fmt.Fprintf(&file, "editor=%s\n", config.Editor)
+	fmt.Fprintf(&file, "notesdir=%s\n", notesDir)
+
+	// Write any additional settings in a stable order
+	keys := make([]string, 0, len(config.Options))
+	for key := range config.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&file, "%s=%s\n", key, config.Options[key])
+	}
+
+	writeConfigSection(&file, "defaults", config.Defaults)
+	writeConfigSection(&file, "schedule", config.Schedule)
+	writeConfigSection(&file, "editors", config.Editors)
+	writeConfigSection(&file, "visibility", config.Visibility)
+
+	// Written atomically so a crash or a second note process never sees a
+	// half-written config
+	err = withStateLock(configPath, func() error {
+		return writeFileAtomic(configPath, []byte(file.String()), 0644)
+	})
+	if err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	return nil
+}
+
+// writeConfigSection writes a [name] section of ~/.note in a stable order
+func writeConfigSection(w io.Writer, name string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n[%s]\n", name)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s=%s\n", key, values[key])
+	}
 }
 
 func setupAliases(reader *bufio.Reader) {
@@ -566,125 +960,231 @@ func setupFishAliases() {
 	fmt.Printf("  Restart your shell to activate aliases\n")
 }
 
-func expandPath(path string) string {
-	// Handle tilde expansion first
-	if strings.HasPrefix(path, "~/") {
-		homeDir, _ := os.UserHomeDir()
-		path = filepath.Join(homeDir, path[2:])
+func openOrCreateNote(config Config, noteName string, force, captureContext bool) error {
+	notePath, line, err := resolveDeepLink(config, noteName)
+	if err != nil {
+		return err
+	}
+	if line > 0 {
+		wasLocked, err := checkNoteEditable(notePath, force)
+		if err != nil {
+			return err
+		}
+		defer restoreLock(notePath, wasLocked)
+		return editNoteAt(config, notePath, line)
 	}
 
-	// Resolve symbolic links to get the actual path
-	resolvedPath, err := filepath.EvalSymlinks(path)
+	// Locked notes are finalized and need --force to edit
+	wasLocked, err := checkNoteEditable(notePath, force)
 	if err != nil {
-		// If we can't resolve symlinks, return the original path
-		// This handles cases where the path doesn't exist yet or other errors
-		return path
+		return err
 	}
+	defer restoreLock(notePath, wasLocked)
 
-	return resolvedPath
+	// Check for similar notes (for tab completion hint) before creating a new one
+	if _, err := os.Stat(notePath); err != nil && !strings.HasSuffix(noteName, ".md") {
+		matches := findMatchingNotes(config.NotesDir, noteName, false)
+		if len(matches) > 1 && config.boolOption("fuzzy_open") && isInputFromTerminal() && isOutputToTerminal() {
+			picked, err := findNote(config, pickerCandidates(config, noteName, false), "", "new note")
+			if err != nil {
+				return err
+			}
+			if picked != "" {
+				return openOrCreateNote(config, picked, force, captureContext)
+			}
+		} else if len(matches) > 0 && len(matches) <= 5 {
+			fmt.Println("Similar notes found:")
+			for _, match := range matches {
+				fmt.Printf("  %s\n", match)
+			}
+			fmt.Println()
+		}
+	}
+
+	if captureContext && !pathExists(notePath) {
+		if err := createNoteFile(notePath, []byte(withOrigin(""))); err != nil {
+			return err
+		}
+	}
+	return editNote(config, notePath)
 }
 
-func openOrCreateNote(config Config, noteName string) {
-	// Check if it's a specific file with .md extension
-	if strings.HasSuffix(noteName, ".md") {
-		// Open specific file
-		notePath := filepath.Join(config.NotesDir, noteName)
-		openInEditor(config.Editor, notePath)
-		return
+// runRef opens or creates a single-file note such as a cheatsheet, which
+// keeps one undated file however many days it is edited on
+func runRef(config Config, flags *ParsedFlags, args []string) error {
+	name := strings.TrimSuffix(strings.Join(args, " "), ".md")
+	if name == "" {
+		return usageErrorf("usage: note --ref <name>")
+	}
+	store := noteStore(config)
+	store.Sticky = []string{name}
+	notePath := store.Resolve(name)
+	rel, err := filepath.Rel(config.NotesDir, notePath)
+	if err != nil {
+		return err
 	}
+	return openOrCreateNote(config, rel, flags.Force, flags.Context)
+}
 
-	// Check if there's an exact match for noteName.md (existing file)
-	// This handles cases like 'roloText-Meeting-Notes-20240426' which should open 'roloText-Meeting-Notes-20240426.md'
-	exactFileName := noteName + ".md"
-	exactPath := filepath.Join(config.NotesDir, exactFileName)
-	if _, err := os.Stat(exactPath); err == nil {
-		// Exact file exists, open it
-		openInEditor(config.Editor, exactPath)
-		return
+// resolveDeepLink resolves a note name that may end in "#heading", as in
+// "meeting#Decisions", returning the note and the heading's line (from 1).
+// The line is 0 for plain names, and for names containing # that are notes
+// in their own right.
+func resolveDeepLink(config Config, noteName string) (string, int, error) {
+	notePath := resolveNotePath(config, noteName)
+	hash := strings.LastIndex(noteName, "#")
+	if hash <= 0 || pathExists(notePath) {
+		return notePath, 0, nil
+	}
+	name, fragment := noteName[:hash], noteName[hash+1:]
+	linked := resolveNotePath(config, name)
+	if !pathExists(linked) {
+		return notePath, 0, nil
+	}
+	content, err := os.ReadFile(linked)
+	if err != nil {
+		return "", 0, fmt.Errorf("error reading %s: %w", filepath.Base(linked), err)
 	}
+	heading, ok := notes.FindHeading(splitLines(string(content)), fragment)
+	if !ok {
+		return "", 0, fmt.Errorf("no heading '%s' in %s", fragment, filepath.Base(linked))
+	}
+	return linked, heading.Line + 1, nil
+}
 
-	// Generate today's date for new file
-	today := time.Now().Format("20060102")
-	// Replace spaces with underscores for filename
-	cleanNoteName := strings.ReplaceAll(noteName, " ", "_")
-	filename := fmt.Sprintf("%s-%s.md", cleanNoteName, today)
-	notePath := filepath.Join(config.NotesDir, filename)
+// runNewNote creates a dated note. When stdin is piped or redirected (or the
+// last argument is "-"), the note body is read from stdin verbatim and the
+// editor is skipped; otherwise the new note is opened in the editor.
+func runNewNote(config Config, flags *ParsedFlags, args []string) error {
+	fromStdin := !isInputFromTerminal()
+	if len(args) > 0 && args[len(args)-1] == "-" {
+		fromStdin = true
+		args = args[:len(args)-1]
+	}
 
-	// Check if note already exists for today
-	if _, err := os.Stat(notePath); err == nil {
-		// Note exists, open it
-		openInEditor(config.Editor, notePath)
-		return
+	noteName := strings.Join(args, " ")
+	if noteName == "" {
+		return usageErrorf("--new requires a note name")
 	}
+	filename := datedNoteFilename(noteName, time.Now())
+	notePath := filepath.Join(config.NotesDir, filename)
 
-	// Check for similar notes (for tab completion hint)
-	matches := findMatchingNotes(config.NotesDir, noteName, false)
-	if len(matches) > 0 && len(matches) <= 5 {
-		fmt.Println("Similar notes found:")
-		for _, match := range matches {
-			fmt.Printf("  %s\n", match)
+	if !fromStdin {
+		if flags.Context && !pathExists(notePath) {
+			if err := createNoteFile(notePath, []byte(withOrigin(""))); err != nil {
+				return err
+			}
 		}
-		fmt.Println()
+		return editNote(config, notePath)
+	}
+
+	body, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("error reading stdin: %w", err)
+	}
+	if flags.Context {
+		body = []byte(withOrigin(string(body)))
+	}
+	if err := createNoteFile(notePath, body); err != nil {
+		return err
+	}
+	postSave(config, notePath)
+	fmt.Printf("Created %s\n", filename)
+	return nil
+}
+
+// createNoteFile writes a brand new note, refusing to overwrite an existing one
+func createNoteFile(notePath string, body []byte) error {
+	file, err := os.OpenFile(notePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return fmt.Errorf("%s already exists", filepath.Base(notePath))
+	}
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", filepath.Base(notePath), err)
 	}
+	if _, err := file.Write(body); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+	}
+	return file.Close()
+}
 
-	// Create new note with today's date
-	openInEditor(config.Editor, notePath)
+// editNote opens a note in the configured editor, going through a local
+// temp copy when tempedit is enabled for slow or remote notes directories
+func editNote(config Config, notePath string) error {
+	return editNoteAt(config, notePath, 0)
 }
 
-func openInEditor(editor, filepath string) {
-	cmd := exec.Command(editor, filepath)
+// editNoteAt is editNote with the cursor on a line (from 1) for editors
+// that support it; 0 opens the note as usual
+func editNoteAt(config Config, notePath string, line int) error {
+	if isEncryptedNote(notePath) {
+		return editEncryptedNote(config, notePath, line)
+	}
+	// The editor works on the note file, so logged captures are folded in first
+	if _, err := compactNote(config, notePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	openBefore, _ := openChecklistItems(notePath)
+	editor := editorFor(config, notePath)
+	edit := openInEditor
+	if config.boolOption("tempedit") {
+		edit = editViaTempFile
+	}
+	if err := edit(editor, notePath, line); err != nil {
+		return err
+	}
+	recordAccess(config.NotesDir, notePath)
+	postSave(config, notePath)
+	if open, total := openChecklistItems(notePath); openBefore > 0 && open == 0 && total > 0 {
+		runChecklistHook(config, notePath)
+	}
+	return nil
+}
+
+func openInEditor(editor, filepath string, line int) error {
+	cmd := exec.Command(editor, editorArgs(editor, filepath, line)...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
+	// A crashed or killed editor may have left the note half written, so
+	// stop before formatting or recording it
 	if err := cmd.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening editor: %v\n", err)
-		os.Exit(1)
+		return editorExitError(err, filepath)
 	}
+	return nil
 }
 
-// getArchiveDir returns the path to the archive directory, checking for both "Archive" and "archive"
-func getArchiveDir(notesDir string) string {
-	// Check for "Archive" first (preferred)
-	archiveDir := filepath.Join(notesDir, "Archive")
-	if _, err := os.Stat(archiveDir); err == nil {
-		return archiveDir
-	}
-
-	// Check for "archive" (lowercase)
-	archiveDir = filepath.Join(notesDir, "archive")
-	if _, err := os.Stat(archiveDir); err == nil {
-		return archiveDir
-	}
-
-	// Default to "Archive" if neither exists (for new creation)
-	return filepath.Join(notesDir, "Archive")
+func listNotes(config Config, flags *ParsedFlags, pattern string, includeArchived bool) error {
+	return printNoteList(config, flags, collectNotes(config, pattern, includeArchived), pattern)
 }
 
-func listNotes(config Config, pattern string, includeArchived bool) {
-	dirs := []string{config.NotesDir}
-	var archiveDirName string
-	if includeArchived {
-		archiveDir := getArchiveDir(config.NotesDir)
-		dirs = append(dirs, archiveDir)
-		archiveDirName = filepath.Base(archiveDir)
+// printNoteList prints note names one per line, highlighting pattern, or as
+// a table when columns are configured, in the order --sort asks for
+func printNoteList(config Config, flags *ParsedFlags, allNotes []string, pattern string) error {
+	if flags.Sort != "" || flags.Reverse {
+		sorted, err := sortNotes(config, allNotes, flags.Sort, flags.Reverse)
+		if err != nil {
+			return err
+		}
+		allNotes = sorted
 	}
 
-	var allNotes []string
-	for _, dir := range dirs {
-		notes := findMatchingNotes(dir, pattern, false)
-		if includeArchived && dir != config.NotesDir {
-			// Prefix archived notes for clarity
-			for i, note := range notes {
-				notes[i] = archiveDirName + "/" + note
-			}
+	// Columns from --columns or the columns setting turn the list into a table
+	spec := flags.Columns
+	if spec == "" {
+		spec = config.option("columns")
+	}
+	if spec != "" {
+		columns, err := parseColumns(spec)
+		if err != nil {
+			return err
 		}
-		allNotes = append(allNotes, notes...)
+		printNoteColumns(config, allNotes, columns)
+		return nil
 	}
 
-	// Sort by modification time (newest first) or alphabetically
-	sort.Strings(allNotes)
-
 	for _, note := range allNotes {
 		// Apply highlighting if pattern is provided and output is to terminal
 		if pattern != "" {
@@ -693,157 +1193,227 @@ func listNotes(config Config, pattern string, includeArchived bool) {
 			fmt.Println(note)
 		}
 	}
+	return nil
 }
 
-func findMatchingNotes(dir, pattern string, includeSubdirs bool) []string {
-	var notes []string
-
-	// Walk the directory
-	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-
-		// Skip Archive directory unless we want subdirs
-		if !includeSubdirs && info.IsDir() && path != dir {
-			return filepath.SkipDir
-		}
-
-		// Only look for .md files
-		if !strings.HasSuffix(info.Name(), ".md") {
-			return nil
-		}
+// collectNotes returns the sorted note names listNotes prints, with archived
+// notes prefixed by their archive directory name
+func collectNotes(config Config, pattern string, includeArchived bool) []string {
+	var names []string
+	for _, note := range noteStore(config).List(notes.Query{Pattern: pattern, Archived: includeArchived, Encrypted: true}) {
+		names = append(names, note.Name)
+	}
+	return names
+}
 
-		// Skip if in Archive subdirectory (unless we want subdirs)
-		relPath, _ := filepath.Rel(dir, path)
-		if !includeSubdirs && strings.Contains(relPath, string(os.PathSeparator)) {
-			return nil
+// selectNotes returns the paths of the notes a command should act on: the
+// note the name resolves to if it exists, otherwise every note matching it
+// as a pattern
+func selectNotes(config Config, nameOrPattern string) []string {
+	if nameOrPattern != "" {
+		notePath := resolveNotePath(config, nameOrPattern)
+		if _, err := os.Stat(notePath); err == nil {
+			return []string{notePath}
 		}
+	}
 
-		// Match pattern (case-insensitive)
-		// Support both glob patterns and substring matching
-		if pattern == "" {
-			notes = append(notes, info.Name())
-		} else {
-			// First try glob pattern matching
-			matched, err := filepath.Match(strings.ToLower(pattern), strings.ToLower(info.Name()))
-			if err == nil && matched {
-				notes = append(notes, info.Name())
-			} else if strings.Contains(strings.ToLower(info.Name()), strings.ToLower(pattern)) {
-				// Fall back to substring matching if not a valid glob or no match
-				notes = append(notes, info.Name())
-			}
-		}
+	var paths []string
+	for _, note := range findMatchingNotes(config.NotesDir, nameOrPattern, false) {
+		paths = append(paths, filepath.Join(config.NotesDir, note))
+	}
+	return paths
+}
 
-		return nil
-	})
+// MaxMatchesShown limits how many matching lines are printed per note
+const MaxMatchesShown = 3
 
-	return notes
+// findSearchResults returns every note containing searchTerm (case-insensitive)
+// along with all of its matching lines
+func findSearchResults(ctx context.Context, config Config, searchTerm string, includeArchived bool) ([]SearchResult, error) {
+	return searchDirs(ctx, config, searchRoots(config, includeArchived), []string{searchTerm})
 }
 
-func searchNotes(config Config, searchTerm string, includeArchived bool) {
+// searchRoots returns the directories searched with or without -a
+func searchRoots(config Config, includeArchived bool) []string {
 	dirs := []string{config.NotesDir}
 	if includeArchived {
 		archiveDir := getArchiveDir(config.NotesDir)
 		dirs = append(dirs, archiveDir)
 	}
+	return dirs
+}
 
-	fmt.Printf("Searching for '%s'...\n\n", searchTerm)
-
-	for _, dir := range dirs {
-		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
-			}
+// searchDirs searches the notes under each of dirs for lines containing any
+// of terms; result paths stay relative to the notes directory. Encrypted
+// notes are searched too with encrypt_search=true. It stops between notes
+// once ctx is cancelled and returns the context's error.
+func searchDirs(ctx context.Context, config Config, dirs []string, terms []string) ([]SearchResult, error) {
+	store := noteStore(config)
+	if config.boolOption("encrypt_search") && canDecrypt(config) {
+		store.Decrypt = func(path string) ([]byte, error) { return decryptNote(config, path) }
+	}
+	return store.Search(ctx, notes.Query{Terms: terms, Dirs: dirs})
+}
 
-			// Skip directories except Archive
-			if info.IsDir() {
-				return nil
-			}
+// maxFileSize returns the maxfilesize setting in bytes, e.g. 10485760, 512K
+// or 10M, falling back to DefaultMaxFileSize when unset or invalid
+func (c Config) maxFileSize() int64 {
+	return notes.ParseFileSize(c.option("maxfilesize"))
+}
 
-			// Only search .md files
-			if !strings.HasSuffix(info.Name(), ".md") {
-				return nil
-			}
+// searchNotes prints the notes matching any of terms; Ctrl-C stops the
+// search cleanly
+func searchNotes(config Config, terms []string, includeArchived bool) error {
+	ctx, stop := interruptible()
+	defer stop()
 
-			// Read file and search
-			file, err := os.Open(path)
-			if err != nil {
-				return nil
-			}
-			defer file.Close()
-
-			scanner := bufio.NewScanner(file)
-			lineNum := 0
-			found := false
-			var matches []string
-
-			for scanner.Scan() {
-				lineNum++
-				line := scanner.Text()
-				if strings.Contains(strings.ToLower(line), strings.ToLower(searchTerm)) {
-					if !found {
-						relPath, _ := filepath.Rel(config.NotesDir, path)
-						fmt.Printf("%s:\n", relPath)
-						found = true
-					}
-					matches = append(matches, fmt.Sprintf("  %d: %s", lineNum, line))
-					// Limit matches per file
-					if len(matches) >= 3 {
-						matches = append(matches, "  ...")
-						break
-					}
-				}
-			}
+	fmt.Printf("Searching for '%s'...\n\n", strings.Join(terms, "' or '"))
+	results, err := searchDirs(ctx, config, searchRoots(config, includeArchived), terms)
+	if err != nil {
+		return fmt.Errorf("search %w", errInterrupted)
+	}
+	printSearchResults(results, terms)
+	return nil
+}
 
-			if found {
-				for _, match := range matches {
-					fmt.Println(match)
-				}
-				fmt.Println()
+// printSearchResults prints each matching note with its first few matches,
+// each term highlighted in its own color and matches in the filename marked
+// separately
+func printSearchResults(results []SearchResult, terms []string) {
+	for _, result := range results {
+		path := result.Path
+		if isOutputToTerminal() {
+			path = colorizeTerms(path, terms, []string{ColorFilenameMatch})
+		}
+		fmt.Printf("%s %s:\n", path, searchResultSummary(result.Title, result.ReadingMinutes))
+		for i, match := range result.Matches {
+			// Limit matches per file
+			if i == MaxMatchesShown {
+				fmt.Println("  ...")
+				break
 			}
+			fmt.Printf("  %d: %s\n", match.Line, highlightTerms(match.Text, terms))
+		}
+		fmt.Println()
+	}
+}
 
-			return nil
-		})
+// searchResultSummary describes a matching note for its search header,
+// e.g. "(Weekly Sync, 3 min read)"
+func searchResultSummary(title string, minutes int) string {
+	summary := fmt.Sprintf("%d min read", minutes)
+	if title != "" {
+		summary = title + ", " + summary
 	}
+	return "(" + summary + ")"
 }
 
-func archiveNotes(config Config, pattern string) {
-	notes := findMatchingNotes(config.NotesDir, pattern, false)
+func archiveNotes(config Config, pattern string) error {
+	store := noteStore(config)
+	selected := store.List(notes.Query{Pattern: pattern})
 
-	if len(notes) == 0 {
+	if len(selected) == 0 {
 		fmt.Printf("No notes found matching '%s'\n", pattern)
-		return
+		return nil
 	}
 
-	archiveDir := getArchiveDir(config.NotesDir)
+	archiveDir := store.ArchiveDir()
 	if err := os.MkdirAll(archiveDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating archive directory: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error creating archive directory: %w", err)
+	}
+
+	// Journal the moves first so an interrupted run can be finished or
+	// undone with --repair
+	steps := make([]journalStep, len(selected))
+	for i, note := range selected {
+		steps[i] = journalStep{From: note.Path, To: filepath.Join(archiveDir, note.Name)}
+	}
+	if err := beginJournal(config.NotesDir, "archive", steps); err != nil {
+		return err
 	}
 
 	fmt.Println("Archiving:")
-	for _, note := range notes {
-		fmt.Printf("  %s\n", note)
-		srcPath := filepath.Join(config.NotesDir, note)
-		dstPath := filepath.Join(archiveDir, note)
-
-		// Move file
-		if err := os.Rename(srcPath, dstPath); err != nil {
-			// Try copy and delete if rename fails (cross-device)
-			if err := copyFile(srcPath, dstPath); err != nil {
-				fmt.Fprintf(os.Stderr, "Error archiving %s: %v\n", note, err)
-				continue
-			}
-			os.Remove(srcPath)
+	failed := false
+	for _, note := range selected {
+		fmt.Printf("  %s\n", note.Name)
+
+		// Move file (symlinked notes are moved as links)
+		if err := store.Archive(note.Name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error archiving %s: %v\n", note.Name, err)
+			failed = true
 		}
 	}
+	if failed {
+		return fmt.Errorf("some notes could not be archived; run 'note --repair' to retry or undo the archive")
+	}
+	finishJournal(config.NotesDir)
+	autoCommit(config, fmt.Sprintf("Archive %d note(s)", len(selected)))
+	return nil
+}
+
+// archiveNotebook moves a notebook, with every note and folder in it, into
+// the archive under the same path, and points links to its notes from
+// elsewhere, such as hand-kept index notes, at their new place
+func archiveNotebook(config Config, flags *ParsedFlags, notebook string) error {
+	store := noteStore(config)
+	notebook = filepath.Clean(filepath.FromSlash(strings.Trim(notebook, "/")))
+	from := filepath.Join(config.NotesDir, notebook)
+	archiveDir := store.ArchiveDir()
+	if strings.HasPrefix(notebook, ".") || from == archiveDir || strings.HasPrefix(from, archiveDir+string(filepath.Separator)) {
+		return usageErrorf("invalid notebook '%s'", notebook)
+	}
+	if info, err := os.Stat(from); err != nil || !info.IsDir() {
+		return fmt.Errorf("notebook '%s' not found", notebook)
+	}
+	to := filepath.Join(archiveDir, notebook)
+	if pathExists(to) {
+		return fmt.Errorf("%s already exists", journalName(config.NotesDir, to))
+	}
+
+	// One journaled move takes the whole tree, so it can't end up half
+	// archived
+	if err := beginJournal(config.NotesDir, "archive", []journalStep{{From: from, To: to}}); err != nil {
+		return err
+	}
+	if err := store.ArchiveNotebook(notebook); err != nil {
+		return fmt.Errorf("error archiving %s: %w; run 'note --repair' to retry or undo the archive", notebook, err)
+	}
+	finishJournal(config.NotesDir)
+
+	updated := rewriteLinks(config, flags, func(lines []string, dir string) bool {
+		return relinkNotebookLines(lines, dir, from, to)
+	})
+	fmt.Printf("Archived %s to %s\n", notebook, journalName(config.NotesDir, to))
+	if updated > 0 {
+		fmt.Printf("Updated links in %d note(s)\n", updated)
+	}
+	autoCommit(config, fmt.Sprintf("Archive notebook %s", notebook))
+	return nil
 }
 
 // ParsedFlags represents parsed command line flags
 type ParsedFlags struct {
-	List         bool
-	Search       string
+	List   bool
+	Search string
+	// SearchTerms holds every -s term; Search is the first of them
+	SearchTerms []string
+	// Tags is set by -t, which lists the notes tagged Tag, or every tag
+	// with its count when no tag is given
+	Tags bool
+	Tag  string
+	// Interactive is set by -i, which browses notes full-screen
+	Interactive bool
+	// Find is set by -f, which picks a note with a fuzzy finder and opens it
+	Find bool
+	// Notebook is set by -d --notebook, which archives a whole notebook
+	// instead of matching notes
+	Notebook string
+	// Journal is set by -j, which opens a daily journal entry (or lists
+	// them with -l)
+	Journal bool
+	// Capture is set by -c, which appends timestamped lines to a note
+	// without opening the editor
+	Capture      bool
 	Archive      bool
 	Delete       string
 	Config       bool
@@ -851,10 +1421,167 @@ type ParsedFlags struct {
 	Alias        bool
 	Help         bool
 	Version      bool
+	// Command is set by long flags that act as commands (e.g. --new)
+	Command string
+	Prepend bool
+	Under   string
+	Force   bool
+	Format  string
+	Live    bool
+	Stamp   bool
+	Dated   bool
+	Context bool
+	Columns string
+	Sort    string
+	Reverse bool
+}
+
+// commandFlags maps long flags that run a command to the command name.
+// Commands are flags rather than bare words so they never shadow note names.
+var commandFlags = map[string]string{
+	"--new":            "new",
+	"--append":         "append",
+	"--spell":          "spell",
+	"--lock":           "lock",
+	"--unlock":         "unlock",
+	"--alias-note":     "alias-note",
+	"--pop":            "pop",
+	"--fzf":            "fzf",
+	"--pick":           "pick",
+	"--cat":            "cat",
+	"--rpc":            "rpc",
+	"--complete-links": "complete-links",
+	"--complete-emoji": "complete-emoji",
+	"--complete-tags":  "complete-tags",
+	"--reindex":        "reindex",
+	"--insights":       "insights",
+	"--tmp":            "tmp",
+	"--scratch":        "scratch",
+	"--ref":            "ref",
+	"--mark":           "mark",
+	"--resume":         "resume",
+	"--split":          "split",
+	"--print":          "print",
+	"--share-link":     "share-link",
+	"--expire":         "expire",
+	"--recover":        "recover",
+	"--repair":         "repair",
+	"--fsck":           "fsck",
+	"--archive":        "archive",
+	"--restore":        "restore",
+	"--delete":         "delete",
+	"--purge":          "purge",
+	"--empty-trash":    "empty-trash",
+	"--backup":         "backup",
+	"--encrypt-config": "encrypt-config",
+	"--encrypt":        "encrypt",
+	"--inbox":          "inbox",
+	"--history":        "history",
+	"--revert":         "revert",
+	"--annotate":       "annotate",
+	"--diff":           "diff",
+	"--changes":        "changes",
+	"--report":         "report",
+	"--meta":           "meta",
+	"--mv":             "mv",
+	"--agenda":         "agenda",
+	"--bridge":         "bridge",
+	"--snippet":        "snippet",
+	"--scheduled":      "scheduled",
+	"--meeting":        "meeting",
+	"--project":        "project",
+	"--index":          "index",
+	"--transcribe":     "transcribe",
+	"--ocr":            "ocr",
+	"--paste-image":    "paste-image",
+	"--run":            "run",
+	"--exec":           "exec",
+	"--checklist":      "checklist",
+	"--clock":          "clock",
+	"--focus":          "focus",
+	"--habit":          "habit",
+	"--read":           "read",
+	"--quote":          "quote",
+	"--quotes":         "quotes",
+	"--compact":        "compact",
+	"--board":          "board",
+	"--outline":        "outline",
+	"--refs":           "refs",
+	"--autolink":       "autolink",
+	"--diagrams":       "diagrams",
+	"--export":         "export",
+	"--open-asset":     "open-asset",
+	"--summarize":      "summarize",
+	"--ask":            "ask",
+	"--person":         "person",
+	"--mentions":       "mentions",
+	"--next":           "next",
+	"--refile":         "refile",
+	"--serve":          "serve",
+	"--serve-token":    "serve-token",
+}
+
+// optionFlags lists the long flags that modify other commands and can be
+// given defaults in ~/.note, and whether each one takes a value
+var optionFlags = map[string]bool{
+	"--force":   false,
+	"--prepend": false,
+	"--live":    false,
+	"--stamp":   false,
+	"--dated":   false,
+	"--context": false,
+	"--format":  true,
+	"--under":   true,
+	"--columns": true,
+	"--sort":    true,
+	"--reverse": false,
+}
+
+// applyDefaults returns args preceded by the flags set in the [defaults]
+// config section. A default is left out when its flag is given on the
+// command line, or switched off for one run with --no-<flag>.
+func applyDefaults(defaults map[string]string, args []string) []string {
+	given := make(map[string]bool)
+	var remaining []string
+	for _, arg := range args {
+		if flag := "--" + strings.TrimPrefix(arg, "--no-"); strings.HasPrefix(arg, "--no-") {
+			if _, ok := optionFlags[flag]; ok {
+				given[flag] = true
+				continue
+			}
+		}
+		given[arg] = true
+		remaining = append(remaining, arg)
+	}
+
+	keys := make([]string, 0, len(defaults))
+	for key := range defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result []string
+	for _, key := range keys {
+		flag := "--" + strings.TrimPrefix(key, "--")
+		takesValue, ok := optionFlags[flag]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring unknown default '%s' in ~/.note\n", key)
+			continue
+		}
+		if given[flag] {
+			continue
+		}
+		if takesValue {
+			result = append(result, flag, defaults[key])
+		} else if isTrue(defaults[key]) {
+			result = append(result, flag)
+		}
+	}
+	return append(result, remaining...)
 }
 
 // parseFlags implements Unix-like flag parsing with support for flag chaining
-func parseFlags(args []string) (*ParsedFlags, []string) {
+func parseFlags(args []string) (*ParsedFlags, []string, error) {
 	flags := &ParsedFlags{}
 	var remainingArgs []string
 
@@ -871,6 +1598,56 @@ func parseFlags(args []string) (*ParsedFlags, []string) {
 			flags.Autocomplete = true
 		} else if arg == "--alias" {
 			flags.Alias = true
+		} else if command, ok := commandFlags[arg]; ok && flags.Command == "" {
+			// A later command flag is an option of the first command, as in
+			// note --backup --encrypt
+			flags.Command = command
+		} else if arg == "--force" {
+			flags.Force = true
+		} else if arg == "--prepend" {
+			flags.Prepend = true
+		} else if arg == "--live" {
+			flags.Live = true
+		} else if arg == "--stamp" {
+			flags.Stamp = true
+		} else if arg == "--dated" {
+			flags.Dated = true
+		} else if arg == "--context" {
+			flags.Context = true
+		} else if arg == "--format" {
+			// --format requires a format name
+			if i+1 < len(args) {
+				i++
+				flags.Format = args[i]
+			} else {
+				return nil, nil, usageErrorf("--format requires a format name")
+			}
+		} else if arg == "--columns" {
+			// --columns requires a column list
+			if i+1 < len(args) {
+				i++
+				flags.Columns = args[i]
+			} else {
+				return nil, nil, usageErrorf("--columns requires a column list")
+			}
+		} else if arg == "--reverse" {
+			flags.Reverse = true
+		} else if arg == "--sort" {
+			// --sort requires a key
+			if i+1 < len(args) {
+				i++
+				flags.Sort = args[i]
+			} else {
+				return nil, nil, usageErrorf("--sort requires name, mtime, created or size")
+			}
+		} else if arg == "--under" {
+			// --under requires a heading
+			if i+1 < len(args) {
+				i++
+				flags.Under = args[i]
+			} else {
+				return nil, nil, usageErrorf("--under requires a heading")
+			}
 		} else if strings.HasPrefix(arg, "--") {
 			// Unknown long flag, treat as regular argument
 			remainingArgs = append(remainingArgs, arg)
@@ -888,39 +1665,61 @@ func parseFlags(args []string) (*ParsedFlags, []string) {
 					flags.List = true
 				case 'a':
 					flags.Archive = true
+				case 'i':
+					flags.Interactive = true
+				case 'f':
+					flags.Find = true
+				case 'j':
+					flags.Journal = true
+				case 'c':
+					flags.Capture = true
 				case 's':
 					// -s requires an argument
 					if j == len(flagChars)-1 {
 						// -s is the last flag in the chain, next arg is the search term
 						if i+1 < len(args) {
 							i++
-							flags.Search = args[i]
+							// Repeating -s searches for any of the terms
+							if flags.Search == "" {
+								flags.Search = args[i]
+							}
+							flags.SearchTerms = append(flags.SearchTerms, args[i])
 						} else {
-							fmt.Fprintf(os.Stderr, "Error: -s flag requires a search term\n")
-							os.Exit(1)
+							return nil, nil, usageErrorf("-s flag requires a search term")
 						}
 					} else {
-						fmt.Fprintf(os.Stderr, "Error: -s flag must be the last in a flag chain\n")
-						os.Exit(1)
+						return nil, nil, usageErrorf("-s flag must be the last in a flag chain")
+					}
+				case 't':
+					// -t takes an optional tag
+					if j != len(flagChars)-1 {
+						return nil, nil, usageErrorf("-t flag must be the last in a flag chain")
+					}
+					flags.Tags = true
+					if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+						i++
+						flags.Tag = strings.TrimPrefix(args[i], "#")
 					}
 				case 'd':
 					// -d requires an argument
 					if j == len(flagChars)-1 {
 						// -d is the last flag in the chain, next arg is the pattern
-						if i+1 < len(args) {
+						if i+2 < len(args) && args[i+1] == "--notebook" {
+							i += 2
+							flags.Notebook = args[i]
+						} else if i+1 < len(args) && args[i+1] != "--notebook" {
 							i++
 							flags.Delete = args[i]
+						} else if i+1 < len(args) {
+							return nil, nil, usageErrorf("--notebook requires a notebook")
 						} else {
-							fmt.Fprintf(os.Stderr, "Error: -d flag requires a pattern\n")
-							os.Exit(1)
+							return nil, nil, usageErrorf("-d flag requires a pattern")
 						}
 					} else {
-						fmt.Fprintf(os.Stderr, "Error: -d flag must be the last in a flag chain\n")
-						os.Exit(1)
+						return nil, nil, usageErrorf("-d flag must be the last in a flag chain")
 					}
 				default:
-					fmt.Fprintf(os.Stderr, "Error: unknown flag -%c\n", char)
-					os.Exit(1)
+					return nil, nil, usageErrorf("unknown flag -%c", char)
 				}
 			}
 		} else {
@@ -929,7 +1728,7 @@ func parseFlags(args []string) (*ParsedFlags, []string) {
 		}
 	}
 
-	return flags, remainingArgs
+	return flags, remainingArgs, nil
 }
 
 // RunAliasSetup handles the standalone alias setup flow
@@ -954,49 +1753,290 @@ func RunAliasSetup() {
 	setupAliases(reader)
 }
 
-func copyFile(src, dst string) error {
-	source, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer source.Close()
-
-	destination, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer destination.Close()
-
-	_, err = io.Copy(destination, source)
-	return err
-}
-
-func printVersion() {
-	fmt.Println(Version)
-}
-
 func printHelp() {
 	fmt.Println(`note - A minimalist CLI note-taking tool
 
 USAGE:
   note [name]              Create/open note with automatic dating
   note [name-date.md]      Open specific dated note
+  note "Note Title"        Open the note whose first # heading matches
+  note "name#Heading"      Open a note at a heading (by its text or its anchor,
+                           e.g. meeting#next-steps)
   note [OPTIONS] [args...]
 
 OPTIONS:
 
   -l [pattern]             List notes (optionally matching pattern)
-  -s <term>                Full-text search in notes
+  -s <term>                Full-text search in notes (repeat -s to search for
+                           any of several terms, each highlighted in its own color)
   -d <pattern>             Delete/archive matching notes
+  -d --notebook <folder>   Archive a whole notebook, keeping its structure
+                           under Archive/ and updating links to its notes
   -a [pattern]             Include archived notes in list/search
+  -j [day]                 Open the journal entry for today or another day
+                           (journal/YYYY-MM-DD.md): yesterday, monday, last
+                           friday, 3 days ago, 2025-05-20; -j -l lists entries
+  --agenda [day] [--ics <file|url>]
+                           Add a "## 09:30 Meeting" heading for each of the
+                           day's calendar events to its -j journal entry;
+                           takes an .ics file or http(s)/webcal URL
+  -c [note] [text]         Append text (or stdin) to a note as timestamped
+                           items without opening the editor; the note defaults
+                           to capture_note, then the inbox
+  -i [pattern]             Browse notes full-screen: type to fuzzy-filter,
+                           ↑↓ to select with a preview, Enter to open, Ctrl-A
+                           to archive, Ctrl-R to rename, Ctrl-D to move to
+                           the trash
+  -f [pattern]             Pick a note by fuzzy name with a preview and open
+                           it, using fzf when installed (see finder)
+  -t [tag] [pattern]       List notes tagged tag (in frontmatter tags: or as
+                           an inline #tag; work also matches work/*), or every
+                           tag with its count; -t tag -s term searches only
+                           the tagged notes; -t [tag] --tree shows nested tags
+                           as a hierarchy
   -h                       Show this help message
-  -v                       Print version number of note
+  -v                       Print version, commit, build date and Go version
 
   --help                   Show this help message
   --config, --configure    Run setup/reconfigure
+  --config-file <path>     Use this config file instead of ~/.note
+  --notes-dir <dir>        Use this notes directory instead of the configured
+                           one (works without any config, e.g. in tests)
+  --json                   Print -l/-a/-s results as JSON (name, path, mtime,
+                           size and, for -s, every matching line) and report
+                           errors on stderr as {"error": "...", "code": N}
+  --config export          Write config, templates, snippets, dictionary and
+                           shell integration settings to stdout as a tar
+  --config import [--force] [file]
+                           Set up this machine from an exported tar (stdin if
+                           no file); keeps the local notesdir
   --autocomplete           Setup/update command line autocompletion
   --alias                  Setup/update shell aliases (n, nls, nrm)
-  --version                Print version number of note
+  --new <name> [-]         Create a dated note, reading its body from stdin
+                           when piped (or when '-' is given)
+  --append <name> [text]   Append text (or stdin) to a note without the editor
+    --prepend              Insert at the top instead of the end
+    --under <heading>      Insert under a heading, creating it if missing
+    --stamp                Add the text as a "- [HH:MM]" item, e.g. for minutes
+    --dated                Insert under today's "## YYYY-MM-DD" heading,
+                           creating it if missing (also for -c; see
+                           dated_sections)
+  --fzf [pattern]          Print note names for fzf, newest first (-a to
+                           include archived); preview with 'note --cat {}'
+  --pick [pattern]         Pick a note with fzf (with preview) and open it
+  --cat <name>             Print a note's contents
+  --rpc                    Serve newline-delimited JSON requests on stdin
+                           (list, search, resolve, create, append) for editor plugins
+  --serve [addr]           Serve the --rpc methods over HTTP at /rpc
+                           (default 127.0.0.1:7531); needs an API token
+                           except for notes [visibility] makes public, which
+                           are also shown at /notes/<path>
+  --serve-token create [--read-only|--scope read|append|full]
+  --serve-token list|revoke <id>
+                           Manage the API tokens accepted by --serve
+  --share-link <name> [--ttl 1h] [--qr]
+                           Print an expiring link at which --serve shows the
+                           note without a token (--qr draws it as a QR code
+                           with qrencode); --revoke-all invalidates every link
+  --complete-links <prefix>
+                           Print [[wiki-link]] targets (names and titles),
+                           most frequently and recently opened first
+  --complete-emoji <prefix>
+                           Print :emoji: shortcodes starting with prefix
+  --complete-tags <prefix> Print tags starting with prefix, one level of
+                           nesting at a time (project/ then project/alpha)
+  --inbox [text]           Capture text (or stdin) as a bullet in the inbox
+                           note; opens the inbox when given nothing
+  --refile                 Move inbox items into other notes one by one
+  --bridge slack|matrix --channel <name> [--watch]
+                           Capture new messages from a chat channel (Matrix:
+                           room ID or #alias) into the inbox, once or polling
+  --snippet add <name>     Save a reusable snippet from stdin (or the editor)
+  --snippet insert <name> --into <note> [key=value ...]
+                           Append a snippet, expanding {{date}}, {{time}},
+                           {{note}}, {{user}} and the given variables
+  --snippet list           List saved snippets
+  --meeting <title> [--live]
+                           Start today's meeting note from a template; --live
+                           records typed lines as timestamped minutes
+  --project new <name>     Create a notebook for a project with overview,
+                           decisions, meetings and retro notes (or the ones in
+                           .templates/project/) and an index.md linking them
+  --index [notebook] [--by month|tag]
+                           Write or refresh the notebook's _index.md, linking
+                           its notes grouped by month or tag; only rewritten
+                           when the listing changes, so safe in a hook
+  --transcribe <audio-file> --into <note>
+                           Append a voice memo transcript using the
+                           configured transcriber
+  --ocr <image> --into <note>
+                           Append the text recognized in an image (tesseract by
+                           default) and a link to the image in attachments/
+  --paste-image <name>     Save the clipboard's image in attachments/ and link
+                           it at the end of a note (or --under a heading)
+  --run "<command>" --into <note>
+                           Run a shell command and append the command line and
+                           its output to a note as a timestamped code block
+  --checklist <template>   Start today's copy of the checklist in
+                           .templates/<template>.md
+  --checklist status <note>
+                           Show a checklist's completion and open items
+  --board [tag]            Kanban board of checkbox tasks (Todo/Doing/Done, or
+                           @status(doing)); </> move the selected task
+  --outline <name>         Edit a note's bullet lists in a terminal outliner:
+                           fold, move (J/K), indent (Tab/Shift-Tab), edit,
+                           add and delete items, saved as markdown
+  --clock in <note>        Start tracking time in a note (@clock-in marker),
+                           stopping any running clock
+  --clock out              Stop the running clock (@clock-out marker)
+  --clock report [--week]  Show tracked time per note and tag
+  --focus <task> [--minutes 25]
+                           Run a focus timer, notify when it is up and log the
+                           session's start and stop times under ## Focus in
+                           today's journal entry; Ctrl-C stops it early
+  --habit done <habit> [--on <day>]
+                           Record a habit as done (today by default) in the
+                           habits.md ledger
+  --habit report [--month YYYY-MM]
+                           Show each habit's days this month as a grid, with
+                           its current and best streak
+  --read add <url|title>   Add an unread entry, with today's date, to the
+                           reading.md list (URLs are unfurled to titles)
+  --read [list | next]     Show the unread entries, or just the oldest one
+  --read done <n>          Mark entry n as read, with today's date
+  --quote <text> [--source <source>] [--tag <tags>]
+                           Add a blockquote, attributed to its source and
+                           tagged, to quotes.md
+  --quotes [--random] [--tag <tag>]
+                           Print the collected quotes, or one at random
+  --compact [name]         Fold captures from the capture log into their note
+                           (every note with pending captures if no name)
+  --refs tidy <name> [--reference | --inline]
+                           Renumber footnotes, drop unused link definitions and
+                           optionally convert links to reference or inline style
+  --autolink <name|pattern> [--yes]
+                           Turn mentions of other notes' titles into
+                           [[wiki-links]], asking about each one unless --yes
+  --diagrams <name> --out <dir>
+                           Copy a note to dir with mermaid and dot blocks
+                           rendered to SVG (needs mmdc or graphviz)
+  --export html|pdf [pattern] --out <dir>
+                           Render matching notes to HTML pages with an index,
+                           or to PDFs, keeping links between them
+  --open-asset <name> [N]  List the images and files a note links to and open
+                           one (N, or the only one) with xdg-open/open
+  --exec <name> [--block N [--write]]
+                           List a note's code blocks, or run block N (sh, bash,
+                           zsh or python); --write puts the output beneath it
+  --summarize <name>       Summarize a note with the configured model
+  --ask "<question>"       Answer a question from the best matching notes,
+                           citing them (-a to include archive)
+  --person <name>          Open the page for @name in people/
+  --mentions <name>        List notes that mention @name (-a to include archive)
+  --scheduled              Create the next occurrence of each recurring note
+                           (safe to run from cron)
+  --next <name>            Open the upcoming occurrence of a recurring note
+  --archive ls [pattern]   List archived notes only
+  --archive search <term>  Search archived notes only
+  --restore <pattern> [--keep-both]
+                           Move archived notes back; a note whose name is
+                           taken is skipped, or restored as name-2.md with
+                           --keep-both
+  --delete <pattern> [--yes]
+                           Move matching notes to .Trash/<today>/ after
+                           asking (--yes skips the question)
+  --purge [--days N] [--yes]
+                           Permanently remove notes deleted more than 30 days
+                           (or trash_days) ago
+  --empty-trash [--yes]    Permanently remove everything in the trash
+  --backup [file] [--encrypt | --zip [--password]]
+                           Archive the notes directory (tar.gz by default);
+                           --encrypt uses age, --password a zip password
+  --encrypt-config <key> [value]
+                           Store a setting (e.g. an API token) encrypted with a
+                           master key kept in the OS keyring; reads stdin if no
+                           value is given
+  --recover <name>         List swap, autosave and conflict files left for a
+                           note after an editor crash
+  --encrypt <name>         Replace a note with a copy encrypted with age or gpg
+                           (name.md.age); opening it decrypts it for the editor
+                           and encrypts it again on save
+  --history <name>         Show the commits that changed a note (git=true)
+  --revert <name> <ref>    Restore a note to a commit shown by --history; the
+                           current version is committed first
+  --changes [--since YYYY-MM-DD|2w]
+                           List the notes created, modified, archived and
+                           deleted since a date (default the last week), for
+                           pasting into a status update; archives and
+                           deletions come from the git history (git=true)
+  --report untagged        List notes with neither tags nor links
+  --report stale [--than 6m]
+                           List notes unmodified for a period (default six
+                           months), oldest first
+  --diff <noteA> <noteB>   Show a unified diff between two notes, e.g. minutes
+                           two people took of the same meeting
+  --annotate <name> [--stale 26w]
+                           Show when each line of a note was last changed,
+                           highlighting lines older than --stale
+  --repair [resume|rollback]
+                           Show, finish or undo a bulk archive that was
+                           interrupted part way
+  --fsck [--fix]           Check for empty or non-UTF-8 notes, dated duplicates,
+                           miscased archive folders, stray locks and stale
+                           indexes; --fix repairs what it safely can
+  --reindex                Rebuild the cached note metadata and title index
+  --mark <name> <line>     Bookmark your reading position in a long note;
+                           with just a name shows it, with --clear removes
+                           it, with nothing lists every bookmark
+  --resume <name>          Open a note at its bookmark (vim, nano, emacs, VS
+                           Code, Sublime, Helix and similar editors)
+  --print <name> [--pdf <file>]
+                           Print a note with its title, date and page numbers
+                           on every page (via lp or lpr), or write it as a PDF
+  --split <name> --by-heading
+                           Move each top-level section of a note into its own
+                           note, named after the heading and dated like the
+                           original, leaving links to them behind
+  --tmp <name> [--ttl 7d]  Open a scratch note that expires after the ttl
+                           (12h, 7d, 2w); any note can set expires: YYYY-MM-DD
+  --scratch                Open the one persistent scratch note (never listed)
+  --scratch --flush [name] Move the scratch note's text into scratch-YYYYMMDD.md
+                           (or name-YYYYMMDD.md) and empty it
+  --ref <name>             Open or create name.md, a single undated note for
+                           reference docs, instead of a dated one
+  --expire [--delete] [--dry-run]
+                           Archive (or trash) notes whose expires: date has
+                           passed; silent when none have, so safe for cron
+  --insights [--days N]    Report busiest hours, most edited notes and note
+                           length by month from local history (last 90 days
+                           by default; nothing leaves this machine)
+  --pop <name>             Open a note in a tmux popup or a new terminal window
+  --alias-note <name> <alias>
+                           Give an existing note a second name (symlink)
+  --meta set <key=value>... --pattern <pattern>
+                           Add or update frontmatter keys in every matching
+                           note, creating frontmatter where there is none
+  --meta unset <key>... --pattern <pattern>
+                           Remove frontmatter keys from every matching note
+  --mv <old> <new> [--redate]
+                           Rename a note, keeping its date stamp (or taking
+                           today's with --redate), and rewrite [[links]] and
+                           markdown links to it in other notes
+  --lock <name>            Make a finalized note read-only
+  --unlock <name>          Allow a locked note to be edited again
+  --force                  Edit or append to a locked note anyway
+  --context                Record the host, working directory and git repo
+                           and branch in the frontmatter of a note being
+                           created (by name, --new or --tmp)
+  --spell <name|pattern>   Spell-check notes with aspell or hunspell
+  --version                Print version, commit, build date and Go version
+  --format alfred|rofi     Print -l/-a/-s results for desktop launchers
+  --sort name|mtime|created|size
+                           Order -l/-a listings: mtime shows the most recently
+                           edited notes first, created goes by the date stamp
+  --reverse                Reverse the listing order
+  --columns <list>         Show -l/-a as a table of name, title, date, tags,
+                           words, size and/or notebook, e.g. name,date,tags
 
 FLAG CHAINING:
   Single-character flags can be combined:
@@ -1004,6 +2044,12 @@ FLAG CHAINING:
   -as <term>               Search all notes (including archived)
   -la [pattern]            Same as -al
 
+EXIT STATUS:
+  0    Success
+  1    The command failed
+  2    Bad flags or arguments
+  130  Interrupted with Ctrl-C
+
 EXAMPLES:
   note meeting             Creates meeting-20260109.md
   note project-ideas       Creates project-ideas-20260109.md
@@ -1013,6 +2059,8 @@ EXAMPLES:
   note -as "todo"          Search for "todo" in all notes (including archived)
   note -d old-*            Archive notes starting with "old-"
   note -a                  List all notes including archived
+  make 2>&1 | note --new build-log
+                           Save command output as build-log-20260109.md
 
 ALIASES:
   After running 'note --alias', you can use:
@@ -1023,6 +2071,112 @@ ALIASES:
 CONFIGURATION:
   Settings are stored in ~/.note
   Use 'note --config' or 'note --configure' to reconfigure
+  Lines after a [defaults] header set default flags, e.g. format=rofi,
+  sort=mtime or force=true; flags given on the command line win,
+  --no-<flag> drops one
+  Lines after a [schedule] header define recurring notes, e.g.
+  1:1-with-alex=tuesday, standup=weekdays, review=monthly 1; new instances
+  start from <notesdir>/.templates/<name>.md
+  Lines after an [editors] header pick another editor by extension or
+  notebook, e.g. .org=emacs or drawings/=krita; an editor: key in a note's
+  frontmatter overrides both
+  Lines after a [visibility] header control what --serve exposes per
+  notebook: private (never served), shared (API tokens and share links;
+  the default) or public (also readable without a token), e.g.
+  journal=private, blog=public, *=private for everything not listed
+  tempedit=true            Edit through a local temp copy (for network mounts)
+  spellcheck=<command>     Spell checker that lists misspelled words from stdin
+                           (default: aspell list, or hunspell -l)
+  Words in <notesdir>/.dictionary are never reported as misspelled
+  format=true              Tidy markdown after each save
+  git=true                 Commit the notes directory after every save, archive
+                           and expiry (a repository is created on first use;
+                           without git installed notes are saved as usual)
+  columns=<list>           Default columns for -l, as for --columns
+  transcriber=<command>    Speech-to-text command for --transcribe; gets the
+                           audio file as its last argument, prints the text
+  ocr=<command>            OCR command for --ocr; {} is replaced by the image
+                           (default: tesseract {} -)
+  paste_image=<command>    Prints the clipboard image as PNG for --paste-image
+                           (default: wl-paste, xclip or pngpaste)
+  print_command=<command>  Spooler for --print; gets the PDF on stdin
+                           (default: lp, or lpr)
+  pdf_command=<command>    Converts --export pdf's HTML pages instead of the
+                           built-in renderer, e.g. wkhtmltopdf {in} {out}
+  backup_recipients=<keys> Comma-separated age or ssh public keys (or files of
+                           them) that --backup --encrypt encrypts to instead
+                           of asking for a passphrase
+  encrypt_with=age|gpg     Tool --encrypt uses (default age)
+  encrypt_recipients=<keys>
+                           Comma-separated keys encrypted notes are encrypted
+                           to: age or ssh keys (or files of them), or gpg key
+                           IDs (default: the age identity's key, or your own
+                           gpg key)
+  encrypt_identity=<file>  age identity file that decrypts .md.age notes
+  encrypt_search=true      Let -s decrypt and search encrypted notes when a key
+                           is available without a prompt
+  journal_dir=<dir>        Notebook for -j entries (default journal); new entries
+                           start from <notesdir>/.templates/journal.md
+  agenda_ics=<file|url>    Calendar --agenda reads when --ics isn't given
+  local_only=<notebooks>   Comma-separated notebooks (folders) that never leave
+                           this machine; --backup leaves them out
+  capture_log=true         Log --append and --inbox captures (and API appends)
+                           to one file per device per day under .captures/,
+                           so devices never conflict; merged on read
+  device=<name>            Names this machine's capture log (default: hostname)
+  habits_note=<name>       Ledger --habit keeps (default habits.md)
+  reading_note=<name>      Reading list --read keeps (default reading.md)
+  quotes_note=<name>       Collection --quote keeps (default quotes.md)
+  notifier=<command>       Shows --focus notifications, given a title and a
+                           message (default: osascript on macOS, notify-send)
+  finder=builtin           Make -f use the built-in finder even if fzf is
+                           installed
+  fuzzy_open=true          When a name matches no note but several notes
+                           contain it, pick one with -f's finder (Esc creates
+                           the new note as before)
+  opener=<command>         Opens attachments for --open-asset (default:
+                           xdg-open, or open on macOS)
+  checklist_done=<command> Run with the note's path when an edit ticks off the
+                           last open checklist item
+  llm=<command>            Opt-in model for --summarize/--ask; gets the prompt
+                           on stdin, e.g. ollama run llama3
+  llm_url, llm_model, llm_key
+                           Or an OpenAI-compatible chat completions endpoint
+  inbox=<name>             Note used by --inbox and --refile (default inbox.md)
+  capture_note=<name>      Note -c captures to when none is named (default
+                           the inbox)
+  capture_format=<layout>  Go time layout -c stamps lines with (default
+                           2006-01-02 15:04)
+  dated_sections=<globs>   Comma-separated note names (e.g. worklog, log-*)
+                           that --append and -c always treat as --dated
+  sticky=<prefixes>        Comma-separated name prefixes (e.g. ref-, howto-)
+                           of single-file notes that are never dated
+  slack_token=<token>      Bot token for --bridge slack (channels:history and
+                           channels:read); keep it in the keyring with
+                           --encrypt-config slack_token
+  matrix_url, matrix_token Homeserver and access token for --bridge matrix
+  bridge_interval=<dur>    How often --bridge --watch polls (default 1m)
+  serve=<addr>             Address --serve listens on
+  serve_rate=<n>           Requests per minute allowed per --serve token
+                           (default 60); every request is logged to
+                           ~/.note_audit.log
+  share_url=<url>          Base URL for --share-link when --serve is reached
+                           at another address (e.g. http://laptop.local:7531)
+  tmux=popup|split|window  Where --pop opens notes inside tmux (default popup)
+  terminal=<command>       Terminal used by --pop outside tmux, e.g. kitty -e
+  formatter=<command>      External formatter run on the note after each save
+  emoji=true               Expand :rocket:-style shortcodes in --cat and exports
+  timeout=<duration>       Timeout for network calls (page titles, llm_url,
+                           update check), e.g. 10s or 2m
+  unfurl=true              Turn bare URLs given to --append and --inbox into
+                           [Title](url) links, fetching and caching page titles
+  updatecheck=true         Let --version check for a newer release (once a day)
+  tmp_ttl=<ttl>            Lifetime of --tmp notes (default 7d)
+  expire_action=delete     Make --expire move expired notes to the trash
+                           instead of archiving them
+  trash_days=<days>        How long --purge keeps deleted notes (default 30)
+  maxfilesize=<size>       Skip larger notes when searching, e.g. 512K or 10M
+                           (default 10M); binary files are always skipped
 
 RELEASE:
      Version:    ` + Version + `
@@ -0,0 +1,113 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultMeetingTemplate is used by --meeting unless .templates/meeting.md
+// exists
+const DefaultMeetingTemplate = `# {{title}}
+
+Date: {{datetime}}
+
+## Attendees
+
+## Agenda
+
+## Notes
+
+## Decisions
+
+## Action Items
+`
+
+// MinutesHeading is the section --live and --stamp minutes are added to
+const MinutesHeading = "## Notes"
+
+// runMeeting creates today's note for a meeting from the meeting template
+// and opens it, or with --live records timestamped minutes typed line by
+// line instead of opening the editor
+func runMeeting(config Config, flags *ParsedFlags, args []string) error {
+	title := strings.Join(args, " ")
+	if title == "" {
+		return usageErrorf("usage: note --meeting <title> [--live]")
+	}
+
+	now := time.Now()
+	notePath := filepath.Join(config.NotesDir, datedNoteFilename(title, now))
+	if _, err := os.Stat(notePath); os.IsNotExist(err) {
+		template := DefaultMeetingTemplate
+		if custom, err := os.ReadFile(filepath.Join(config.NotesDir, TemplatesDir, "meeting.md")); err == nil {
+			template = string(custom)
+		}
+		if err := createNoteFile(notePath, []byte(expandTemplate(template, templateVars(title, now)))); err != nil {
+			return err
+		}
+	}
+
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	if !flags.Live {
+		return editNote(config, notePath)
+	}
+
+	fmt.Printf("Recording minutes in %s; one line per entry, Ctrl-D to finish\n", filepath.Base(notePath))
+	count, err := recordMinutes(config, notePath, os.Stdin, time.Now)
+	if err != nil {
+		return err
+	}
+	postSave(config, notePath)
+	fmt.Printf("Added %d entries to %s\n", count, filepath.Base(notePath))
+	return nil
+}
+
+// recordMinutes appends each non-empty line read from in to the minutes
+// section as it is entered, so nothing is lost if the session is cut short
+func recordMinutes(config Config, notePath string, in io.Reader, now func() time.Time) (int, error) {
+	count := 0
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := appendToNote(config, notePath, stampedLine(line, now()), appendOptions{Under: MinutesHeading}); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, scanner.Err()
+}
+
+// stampedLine returns text as a list item prefixed with the time, e.g.
+// "- [10:32] decided to ship"
+func stampedLine(text string, at time.Time) string {
+	return fmt.Sprintf("- [%s] %s", at.Format("15:04"), strings.TrimPrefix(text, "- "))
+}
@@ -0,0 +1,144 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"note/pkg/notes"
+)
+
+// metaChange is one frontmatter edit --meta makes: key set to Value, or
+// removed when Unset
+type metaChange struct {
+	Key, Value string
+	Unset      bool
+}
+
+// runMeta adds, updates or removes frontmatter keys across every note
+// matching a pattern in one pass, creating frontmatter where a note has none:
+//
+//	note --meta set status=done [key=value...] --pattern "sprint-41-*"
+//	note --meta unset status [key...] --pattern "sprint-41-*"
+func runMeta(config Config, flags *ParsedFlags, args []string) error {
+	usage := usageErrorf("usage: note --meta set key=value... --pattern <pattern>, or --meta unset key... --pattern <pattern>")
+	var pattern string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--pattern" && i+1 < len(args) {
+			i++
+			pattern = args[i]
+			continue
+		}
+		rest = append(rest, args[i])
+	}
+	if pattern == "" || len(rest) < 2 {
+		return usage
+	}
+	changes, err := parseMetaChanges(rest[0], rest[1:])
+	if err != nil {
+		return err
+	}
+
+	selected := noteStore(config).List(notes.Query{Pattern: pattern})
+	if len(selected) == 0 {
+		fmt.Printf("No notes found matching '%s'\n", pattern)
+		return nil
+	}
+
+	updated := 0
+	failed := false
+	for _, note := range selected {
+		changed, err := applyMetaChanges(note.Path, changes, flags.Force)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating %s: %v\n", note.Name, err)
+			failed = true
+			continue
+		}
+		if changed {
+			fmt.Printf("  %s\n", note.Name)
+			updated++
+		}
+	}
+	fmt.Printf("Updated %d of %d matching note(s)\n", updated, len(selected))
+	if updated > 0 {
+		autoCommit(config, fmt.Sprintf("Update frontmatter of %d note(s)", updated))
+	}
+	if failed {
+		return fmt.Errorf("some notes could not be updated")
+	}
+	return nil
+}
+
+// parseMetaChanges reads the key=value pairs of --meta set, or the keys of
+// --meta unset
+func parseMetaChanges(action string, args []string) ([]metaChange, error) {
+	var changes []metaChange
+	for _, arg := range args {
+		change := metaChange{Key: arg, Unset: true}
+		switch action {
+		case "set":
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				return nil, usageErrorf("--meta set expects key=value, got '%s'", arg)
+			}
+			change = metaChange{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)}
+		case "unset":
+		default:
+			return nil, usageErrorf("unknown --meta action '%s' (use set or unset)", action)
+		}
+		if change.Key == "" || strings.ContainsAny(change.Key, ": \t") {
+			return nil, usageErrorf("invalid frontmatter key '%s'", change.Key)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// applyMetaChanges edits the frontmatter of the note at notePath, writing
+// it only if something changed, and reports whether it did
+func applyMetaChanges(notePath string, changes []metaChange, force bool) (bool, error) {
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return false, err
+	}
+	updated := string(content)
+	for _, change := range changes {
+		if change.Unset {
+			updated = removeFrontmatterValue(updated, change.Key)
+		} else {
+			updated = setFrontmatterValue(updated, change.Key, change.Value)
+		}
+	}
+	if updated == string(content) {
+		return false, nil
+	}
+
+	wasLocked, err := checkNoteWritable(notePath, force)
+	if err != nil {
+		return false, err
+	}
+	defer restoreLock(notePath, wasLocked)
+	if err := writeFileAtomic(notePath, []byte(updated), notePerm(notePath)); err != nil {
+		return false, fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+	}
+	return true, nil
+}
@@ -0,0 +1,184 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MetadataFile caches per-note metadata (title, tags, links) in the notes
+// directory. The notes themselves stay the source of truth: the store is
+// refreshed incrementally from modification times and can be rebuilt at any
+// time with --reindex. It is plain JSON rather than a database so note keeps
+// building without external dependencies.
+const MetadataFile = ".note_index.json"
+
+// metadataVersion is bumped whenever noteMetadata changes shape, forcing a
+// rebuild of stores written by older versions
+const metadataVersion = 1
+
+// noteMetadata is what the store knows about a single note
+type noteMetadata struct {
+	ModTime int64    `json:"mtime"`
+	Size    int64    `json:"size"`
+	Title   string   `json:"title,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Links   []string `json:"links,omitempty"`
+}
+
+// metadataStore maps note filenames to their metadata
+type metadataStore struct {
+	Version int                     `json:"version"`
+	Notes   map[string]noteMetadata `json:"notes"`
+}
+
+var (
+	wikiLinkPattern     = regexp.MustCompile(`\[\[([^\]|#]+)(?:[#|][^\]]*)?\]\]`)
+	markdownLinkPattern = regexp.MustCompile(`\]\(([^)\s]+\.md)(?:#[^)]*)?\)`)
+	inlineTagPattern    = regexp.MustCompile(`(?:^|\s)#([A-Za-z0-9][\w/-]*)`)
+)
+
+// loadMetadata returns metadata for every note in notesDir, re-reading only
+// notes whose size or modification time changed since the store was written
+func loadMetadata(notesDir string) map[string]noteMetadata {
+	storePath := filepath.Join(notesDir, MetadataFile)
+	cached := readMetadataStore(storePath)
+
+	fresh := make(map[string]noteMetadata)
+	changed := false
+	for _, note := range findMatchingNotes(notesDir, "", false) {
+		info, err := os.Stat(filepath.Join(notesDir, note))
+		if err != nil {
+			continue
+		}
+		meta, ok := cached[note]
+		if !ok || meta.ModTime != info.ModTime().UnixNano() || meta.Size != info.Size() {
+			content, err := os.ReadFile(filepath.Join(notesDir, note))
+			if err != nil {
+				continue
+			}
+			meta = extractMetadata(string(content))
+			meta.ModTime = info.ModTime().UnixNano()
+			meta.Size = info.Size()
+			changed = true
+		}
+		fresh[note] = meta
+	}
+
+	if changed || len(fresh) != len(cached) {
+		// The store is only an optimization, so write failures are ignored
+		writeMetadataStore(storePath, fresh)
+	}
+	return fresh
+}
+
+// runReindex discards the cached metadata and title index and rebuilds them
+// from the notes on disk
+func runReindex(config Config) error {
+	for _, cache := range []string{MetadataFile, TitleIndexFile} {
+		if err := os.Remove(filepath.Join(config.NotesDir, cache)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing %s: %w", cache, err)
+		}
+	}
+	notes := loadMetadata(config.NotesDir)
+	loadTitleIndex(config.NotesDir)
+	fmt.Printf("Indexed %d notes\n", len(notes))
+	return nil
+}
+
+// extractMetadata parses the title, tags and outgoing links of a note.
+// Tags come from a frontmatter "tags:" list and inline #tags; links are
+// [[wiki-link]] targets and relative links to other .md files.
+func extractMetadata(content string) noteMetadata {
+	meta := noteMetadata{Title: noteTitle(content)}
+
+	tags := make(map[string]bool)
+	for _, tag := range strings.Split(strings.Trim(parseFrontmatter(content)["tags"], "[]"), ",") {
+		if tag = strings.Trim(strings.TrimSpace(tag), `"'#`); tag != "" {
+			tags[strings.ToLower(tag)] = true
+		}
+	}
+
+	links := make(map[string]bool)
+	lines := splitLines(content)
+	inFence := false
+	for _, line := range lines[frontmatterEnd(lines):] {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if headingLevel(line) == 0 {
+			for _, match := range inlineTagPattern.FindAllStringSubmatch(line, -1) {
+				tags[strings.ToLower(match[1])] = true
+			}
+		}
+		for _, match := range wikiLinkPattern.FindAllStringSubmatch(line, -1) {
+			links[strings.TrimSpace(match[1])] = true
+		}
+		for _, match := range markdownLinkPattern.FindAllStringSubmatch(line, -1) {
+			if !strings.Contains(match[1], "://") {
+				links[match[1]] = true
+			}
+		}
+	}
+
+	meta.Tags = sortedKeys(tags)
+	meta.Links = sortedKeys(links)
+	return meta
+}
+
+func sortedKeys[V any](set map[string]V) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func readMetadataStore(storePath string) map[string]noteMetadata {
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		return map[string]noteMetadata{}
+	}
+	var store metadataStore
+	if err := json.Unmarshal(data, &store); err != nil || store.Version != metadataVersion || store.Notes == nil {
+		return map[string]noteMetadata{}
+	}
+	return store.Notes
+}
+
+func writeMetadataStore(storePath string, notes map[string]noteMetadata) error {
+	data, err := json.Marshal(metadataStore{Version: metadataVersion, Notes: notes})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(storePath, append(data, '\n'), 0644)
+}
@@ -0,0 +1,308 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"note/pkg/notes"
+)
+
+// runMove renames a note and rewrites the links other notes make to it, so
+// references don't break. A dated note keeps its date unless --redate
+// stamps it with today's; a new name ending in .md is used as given.
+//
+//	note --mv <old-name> <new-name> [--redate]
+func runMove(config Config, flags *ParsedFlags, args []string) error {
+	redate := false
+	var names []string
+	for _, arg := range args {
+		if arg == "--redate" {
+			redate = true
+		} else {
+			names = append(names, arg)
+		}
+	}
+	if len(names) != 2 {
+		return usageErrorf("usage: note --mv <old-name> <new-name> [--redate]")
+	}
+
+	from, err := existingNotePath(config, names[0])
+	if err != nil {
+		return err
+	}
+	newName, err := renamedFilename(filepath.Base(from), names[1], redate, time.Now())
+	if err != nil {
+		return err
+	}
+	to := filepath.Join(filepath.Dir(from), newName)
+	updated, err := renameNote(config, flags, from, to)
+	if err != nil {
+		return err
+	}
+	oldRel, newRel := journalName(config.NotesDir, from), journalName(config.NotesDir, to)
+	fmt.Printf("Renamed %s to %s\n", oldRel, newRel)
+	if updated > 0 {
+		fmt.Printf("Updated links in %d note(s)\n", updated)
+	}
+	autoCommit(config, fmt.Sprintf("Rename %s to %s", oldRel, newRel))
+	return nil
+}
+
+// renameNote moves the note at from to to, journaled so --repair can
+// finish or undo it, and rewrites the links other notes make to it,
+// returning how many notes that changed. Locked notes are refused unless
+// --force is set. Callers hold the notes lock.
+func renameNote(config Config, flags *ParsedFlags, from, to string) (int, error) {
+	oldRel, newRel := journalName(config.NotesDir, from), journalName(config.NotesDir, to)
+	if to == from {
+		return 0, fmt.Errorf("%s already has that name", oldRel)
+	}
+	if pathExists(to) {
+		return 0, fmt.Errorf("%s already exists", newRel)
+	}
+	if isNoteLocked(from) && !flags.Force {
+		return 0, fmt.Errorf("%s is locked; use --force to rename it anyway, or --unlock it", oldRel)
+	}
+
+	// Undated links resolve by what else is in the notes, so which of
+	// them reach this note has to be worked out before it moves
+	undated := undatedLinkStem(config.NotesDir, from)
+	if err := beginJournal(config.NotesDir, "rename", []journalStep{{From: from, To: to}}); err != nil {
+		return 0, err
+	}
+	if err := moveNote(from, to); err != nil {
+		return 0, fmt.Errorf("error renaming %s: %w; run 'note --repair' to retry or undo the rename", oldRel, err)
+	}
+	finishJournal(config.NotesDir)
+	return rewriteNoteLinks(config, flags, from, to, undated), nil
+}
+
+// renamedFilename returns the filename a note called oldBase gets when
+// renamed to name, keeping its date stamp (or taking now's with redate)
+// and its encryption extension
+func renamedFilename(oldBase, name string, redate bool, now time.Time) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("%q is not a valid note name", name)
+	}
+	encryption := ""
+	if notes.IsEncrypted(oldBase) {
+		encryption = filepath.Ext(oldBase)
+		oldBase = strings.TrimSuffix(oldBase, encryption)
+	}
+	if strings.HasSuffix(name, ".md") {
+		return name + encryption, nil
+	}
+
+	if redate {
+		return datedNoteFilename(name, now) + encryption, nil
+	}
+	if match := dateStamp.FindStringSubmatch(oldBase); match != nil {
+		if date, err := time.ParseInLocation("20060102", match[1], time.Local); err == nil {
+			return datedNoteFilename(name, date) + encryption, nil
+		}
+	}
+	return strings.ReplaceAll(name, " ", "_") + ".md" + encryption, nil
+}
+
+// rewriteNoteLinks points the [[wiki-links]] and relative markdown links to
+// the note renamed from oldPath at newPath instead, in every note, and
+// returns how many notes it changed. undated is the undatedLinkStem the
+// note had before the rename; links by that name are kept on the note,
+// undated again if its new name allows. Notes that can't be updated are
+// reported and skipped, since the rename itself has already happened.
+func rewriteNoteLinks(config Config, flags *ParsedFlags, oldPath, newPath, undated string) int {
+	newUndated := undatedLinkStem(config.NotesDir, newPath)
+	if newUndated == "" {
+		newUndated = noteStem(newPath)
+	}
+	return rewriteLinks(config, flags, func(lines []string, dir string) bool {
+		return relinkLines(lines, dir, oldPath, newPath, undated, newUndated)
+	})
+}
+
+// undatedLinkStem returns the undated name, plan for plan-20240101.md, that
+// wiki-links use to reach the dated note at notePath: the one export
+// resolves them to, when no note has that exact name and notePath is the
+// newest with that stem. It returns "" when undated links miss the note.
+func undatedLinkStem(notesDir, notePath string) string {
+	stem := noteStem(notePath)
+	match := dateStamp.FindStringSubmatch(stem + ".md")
+	if match == nil {
+		return ""
+	}
+	undated := strings.TrimSuffix(stem, "-"+match[1])
+	reaches := true
+	walkNotes(notesDir, func(path string, info os.FileInfo) error {
+		if path == notePath {
+			return nil
+		}
+		other := noteStem(path)
+		if strings.EqualFold(other, undated) {
+			reaches = false
+		} else if m := dateStamp.FindStringSubmatch(other + ".md"); m != nil &&
+			strings.EqualFold(strings.TrimSuffix(other, "-"+m[1]), undated) && m[1] > match[1] {
+			reaches = false
+		}
+		return nil
+	})
+	if !reaches {
+		return ""
+	}
+	return undated
+}
+
+// rewriteLinks runs relink over the lines of every note, passing the
+// note's directory, and saves the notes it reports changing
+func rewriteLinks(config Config, flags *ParsedFlags, relink func(lines []string, dir string) bool) int {
+	updated := 0
+	walkNotes(config.NotesDir, func(notePath string, info os.FileInfo) error {
+		content, err := os.ReadFile(notePath)
+		if err != nil {
+			return nil
+		}
+		lines := splitLines(string(content))
+		if !relink(lines, filepath.Dir(notePath)) {
+			return nil
+		}
+
+		name := journalName(config.NotesDir, notePath)
+		wasLocked, err := checkNoteWritable(notePath, flags.Force)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Warning: links in %s not updated: %v\n", name, err)
+			return nil
+		}
+		defer restoreLock(notePath, wasLocked)
+		if err := writeFileAtomic(notePath, []byte(joinLines(lines)), notePerm(notePath)); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Warning: links in %s not updated: %v\n", name, err)
+			return nil
+		}
+		updated++
+		return nil
+	})
+	return updated
+}
+
+// relinkLines rewrites, in place, the links in lines of a note in dir that
+// point at oldPath to point at newPath, leaving code alone, and reports
+// whether any changed. Wiki-links name the note without .md and keep any
+// #heading or |alias; undated ones naming oldUndated, when it isn't "",
+// become newUndated. Markdown links keep their directory part.
+func relinkLines(lines []string, dir, oldPath, newPath, oldUndated, newUndated string) bool {
+	oldStem, newStem := noteStem(oldPath), noteStem(newPath)
+	relink := func(text string) string {
+		text = replaceSubmatch(wikiLinkPattern, text, func(target string) string {
+			switch trimmed := strings.TrimSpace(target); {
+			case strings.EqualFold(trimmed, oldStem):
+				return strings.Replace(target, trimmed, newStem, 1)
+			case strings.EqualFold(trimmed, oldStem+".md"):
+				return strings.Replace(target, trimmed, newStem+".md", 1)
+			case oldUndated != "" && strings.EqualFold(trimmed, oldUndated):
+				return strings.Replace(target, trimmed, newUndated, 1)
+			case oldUndated != "" && strings.EqualFold(trimmed, oldUndated+".md"):
+				return strings.Replace(target, trimmed, newUndated+".md", 1)
+			}
+			return target
+		})
+		return replaceSubmatch(markdownLinkPattern, text, func(target string) string {
+			if strings.Contains(target, "://") || filepath.Join(dir, filepath.FromSlash(target)) != oldPath {
+				return target
+			}
+			return path.Join(path.Dir(target), filepath.Base(newPath))
+		})
+	}
+
+	changed := false
+	code := codeLines(lines)
+	for i, line := range lines {
+		if code[i] {
+			continue
+		}
+		if relinked := outsideInlineCode(line, relink); relinked != line {
+			lines[i] = relinked
+			changed = true
+		}
+	}
+	return changed
+}
+
+// relinkNotebookLines rewrites, in place, the relative markdown links in
+// lines of a note in dir that point into the folder moved from oldDir to
+// newDir, leaving code alone, and reports whether any changed. Wiki-links
+// name notes without their folder, so they need no change.
+func relinkNotebookLines(lines []string, dir, oldDir, newDir string) bool {
+	relink := func(text string) string {
+		return replaceSubmatch(markdownLinkPattern, text, func(target string) string {
+			if strings.Contains(target, "://") {
+				return target
+			}
+			inside, err := filepath.Rel(oldDir, filepath.Join(dir, filepath.FromSlash(target)))
+			if err != nil || strings.HasPrefix(inside, "..") {
+				return target
+			}
+			moved, err := filepath.Rel(dir, filepath.Join(newDir, inside))
+			if err != nil {
+				return target
+			}
+			return filepath.ToSlash(moved)
+		})
+	}
+
+	changed := false
+	code := codeLines(lines)
+	for i, line := range lines {
+		if code[i] {
+			continue
+		}
+		if relinked := outsideInlineCode(line, relink); relinked != line {
+			lines[i] = relinked
+			changed = true
+		}
+	}
+	return changed
+}
+
+// noteStem is a note's filename without .md or an encryption extension, as
+// wiki-links name it
+func noteStem(notePath string) string {
+	base := filepath.Base(notePath)
+	if notes.IsEncrypted(base) {
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return strings.TrimSuffix(base, ".md")
+}
+
+// replaceSubmatch replaces the first capture group of every match of re in
+// text with what fn returns for it
+func replaceSubmatch(re *regexp.Regexp, text string, fn func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(text[last:match[2]])
+		b.WriteString(fn(text[match[2]:match[3]]))
+		last = match[3]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
@@ -0,0 +1,48 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// localOnlyNotebooks returns the notebooks listed in local_only, e.g.
+// local_only=Personal,journal/private. They never leave this machine:
+// backups leave them out, and so must anything that copies notes elsewhere.
+func localOnlyNotebooks(config Config) []string {
+	var notebooks []string
+	for _, notebook := range strings.Split(config.option("local_only"), ",") {
+		notebook = strings.Trim(filepath.ToSlash(strings.TrimSpace(notebook)), "/")
+		if notebook != "" {
+			notebooks = append(notebooks, notebook)
+		}
+	}
+	return notebooks
+}
+
+// inNotebook reports whether rel, a slash-separated path relative to the
+// notes directory, is one of notebooks or inside one
+func inNotebook(rel string, notebooks []string) bool {
+	for _, notebook := range notebooks {
+		if strings.EqualFold(rel, notebook) || strings.HasPrefix(strings.ToLower(rel), strings.ToLower(notebook)+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AttachmentsDir holds images and other files referenced from notes
+const AttachmentsDir = "attachments"
+
+// DefaultOCRCommand prints the text in an image; {} is the image path
+var DefaultOCRCommand = []string{"tesseract", "{}", "-"}
+
+// runOCR recognizes the text in an image, copies the image into the
+// attachments folder and appends both the text and a link to the image:
+//
+//	note --ocr whiteboard.jpg --into planning
+func runOCR(config Config, flags *ParsedFlags, args []string) error {
+	into, rest := intoArg(args)
+	if into == "" || len(rest) != 1 {
+		return usageErrorf("usage: note --ocr <image> --into <note>")
+	}
+	image := rest[0]
+
+	command := strings.Fields(config.option("ocr"))
+	if len(command) == 0 {
+		command = DefaultOCRCommand
+	}
+	text, err := runCaptureCommand(command, image)
+	if err != nil {
+		return err
+	}
+
+	attachment, err := storeAttachment(config.NotesDir, image, time.Now())
+	if err != nil {
+		return err
+	}
+	link := filepath.ToSlash(attachment)
+	capture := fmt.Sprintf("![%s](%s)\n\n%s", filepath.Base(image), link, text)
+	return appendCapture(config, flags, into, capture)
+}
+
+// storeAttachment copies file into the attachments folder unless it is
+// already inside the notes directory, returning its path relative to the
+// notes directory. Existing attachments are never overwritten.
+func storeAttachment(notesDir, file string, now time.Time) (string, error) {
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(notesDir, absFile); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel, nil
+	}
+
+	dir := filepath.Join(notesDir, AttachmentsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating %s: %w", AttachmentsDir, err)
+	}
+	name := filepath.Base(file)
+	if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+		ext := filepath.Ext(name)
+		name = fmt.Sprintf("%s-%s%s", strings.TrimSuffix(name, ext), now.Format("20060102-150405"), ext)
+	}
+	if err := copyFile(file, filepath.Join(dir, name)); err != nil {
+		return "", fmt.Errorf("error copying %s: %w", filepath.Base(file), err)
+	}
+	return filepath.Join(AttachmentsDir, name), nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// originField is one frontmatter entry recorded by --context
+type originField struct {
+	Key, Value string
+}
+
+// noteOrigin describes where a note is being written: the machine, the
+// working directory and, inside a git checkout, its repository and branch
+func noteOrigin(dir string) []originField {
+	var fields []originField
+	if host, err := os.Hostname(); err == nil && host != "" {
+		fields = append(fields, originField{"host", host})
+	}
+	fields = append(fields, originField{"cwd", dir})
+
+	git := func(args ...string) string {
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(output))
+	}
+	top := git("rev-parse", "--show-toplevel")
+	if top == "" {
+		return fields
+	}
+	// The remote identifies the project across machines; a local-only
+	// repository is named by its path
+	repo := git("remote", "get-url", "origin")
+	if repo == "" {
+		repo = top
+	}
+	fields = append(fields, originField{"repo", repo})
+	// A detached HEAD has no branch to record
+	if branch := git("symbolic-ref", "--short", "HEAD"); branch != "" {
+		fields = append(fields, originField{"branch", branch})
+	}
+	return fields
+}
+
+// withOrigin returns content with the current origin in its frontmatter
+func withOrigin(content string) string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return content
+	}
+	for _, field := range noteOrigin(dir) {
+		content = setFrontmatterValue(content, field.Key, field.Value)
+	}
+	return content
+}
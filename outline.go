@@ -0,0 +1,528 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// listItemPattern matches a bullet or numbered list item, capturing its
+// indentation, marker and text
+var listItemPattern = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])(?:[ \t]+(.*))?$`)
+
+// outlineItem is one line of a note in the outliner: a list item, or a
+// plain line (heading, paragraph, blank or code) that stays where it is
+type outlineItem struct {
+	Plain  bool
+	Line   string   // a plain line, as it is in the note
+	Depth  int      // nesting of a list item, from 0
+	Marker string   // "-", "*", "+", "1." or "1)"
+	Text   string   // the item's text after the marker
+	More   []string // continuation lines, without the item's indentation
+	Folded bool
+
+	// ChildIndent is how much deeper than the item its children are
+	// indented in the note; "" until it has some
+	ChildIndent string
+}
+
+// outline is a note as the outliner sees it. Runs of list items form
+// blocks; items move, indent and outdent with their children, but never
+// out of their block.
+type outline struct {
+	items  []*outlineItem
+	indent string // indentation for newly nested items, "" to follow markers
+}
+
+// parseOutline reads a note's lines into an outline. Nesting follows
+// indentation the way markdown does, whatever width the note uses.
+func parseOutline(content string) *outline {
+	o := &outline{}
+	lines := splitLines(content)
+	code := codeLines(lines)
+	var indents []int // indentation of the open list levels
+	var last *outlineItem
+	lastText := 0 // column where the last item's text starts
+	for i, line := range lines {
+		match := listItemPattern.FindStringSubmatch(line)
+		if code[i] || match == nil {
+			if last != nil && strings.TrimSpace(line) != "" && indentWidth(line) > 0 && !code[i] {
+				last.More = append(last.More, trimIndent(line, lastText))
+				continue
+			}
+			o.items = append(o.items, &outlineItem{Plain: true, Line: line})
+			indents, last = nil, nil
+			continue
+		}
+
+		width := indentWidth(match[1])
+		if strings.Contains(match[1], "\t") {
+			o.indent = "\t"
+		}
+		for len(indents) > 0 && indents[len(indents)-1] > width {
+			indents = indents[:len(indents)-1]
+		}
+		if len(indents) == 0 || width > indents[len(indents)-1] {
+			if len(indents) > 0 && last.ChildIndent == "" {
+				last.ChildIndent = strings.Repeat(" ", width-indents[len(indents)-1])
+				if strings.Contains(match[1], "\t") {
+					last.ChildIndent = "\t"
+				}
+				// The first nested list shows how the note indents new ones
+				if o.indent == "" {
+					o.indent = last.ChildIndent
+				}
+			}
+			indents = append(indents, width)
+		}
+		last = &outlineItem{Depth: len(indents) - 1, Marker: match[2], Text: match[3]}
+		lastText = width + len(match[2]) + 1
+		o.items = append(o.items, last)
+	}
+	return o
+}
+
+// indentWidth returns the width of a line's indentation, a tab counting as
+// four spaces
+func indentWidth(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// trimIndent strips the indentation of a continuation line, keeping the
+// part deeper than column, where its item's text starts
+func trimIndent(line string, column int) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	extra := indentWidth(line) - column
+	if extra <= 0 {
+		return trimmed
+	}
+	return strings.Repeat(" ", extra) + trimmed
+}
+
+// markdown writes the outline back as a note, renumbering numbered lists
+// so they stay in order after moves
+func (o *outline) markdown() string {
+	var lines []string
+	var prefixes []string // indentation of each open level's children
+	var numbers []int     // next number at each level, 0 for bullets
+	for i, item := range o.items {
+		if item.Plain {
+			lines = append(lines, item.Line)
+			prefixes, numbers = nil, nil
+			continue
+		}
+		prefixes, numbers = prefixes[:min(item.Depth, len(prefixes))], numbers[:min(item.Depth+1, len(numbers))]
+		for len(numbers) <= item.Depth {
+			numbers = append(numbers, 0)
+		}
+		prefix := strings.Join(prefixes, "")
+
+		marker := item.Marker
+		if n, ok := listNumber(item); ok {
+			if numbers[item.Depth] == 0 {
+				n = o.listStart(i)
+			} else {
+				n = numbers[item.Depth]
+			}
+			marker = strconv.Itoa(n) + marker[len(marker)-1:]
+			numbers[item.Depth] = n + 1
+		} else {
+			numbers[item.Depth] = 0
+		}
+
+		lines = append(lines, strings.TrimRight(prefix+marker+" "+item.Text, " "))
+		for _, more := range item.More {
+			lines = append(lines, prefix+strings.Repeat(" ", len(marker)+1)+more)
+		}
+		childIndent := item.ChildIndent
+		if childIndent == "" {
+			childIndent = o.indent
+		}
+		if childIndent == "" {
+			childIndent = strings.Repeat(" ", len(marker)+1)
+		}
+		prefixes = append(prefixes, childIndent)
+	}
+	return joinLines(lines)
+}
+
+// listNumber returns the number of a numbered list item
+func listNumber(item *outlineItem) (int, bool) {
+	n, err := strconv.Atoi(item.Marker[:len(item.Marker)-1])
+	return n, err == nil
+}
+
+// listStart returns the number a numbered list starting at item i counts
+// from: the lowest number among its items, so a list keeps its start
+// whichever item is moved first
+func (o *outline) listStart(i int) int {
+	start, _ := listNumber(o.items[i])
+	for j := o.end(i); j < len(o.items) && !o.items[j].Plain && o.items[j].Depth == o.items[i].Depth; j = o.end(j) {
+		n, ok := listNumber(o.items[j])
+		if !ok {
+			break
+		}
+		start = min(start, n)
+	}
+	return start
+}
+
+// end returns the index just past item i and its children
+func (o *outline) end(i int) int {
+	j := i + 1
+	for j < len(o.items) && !o.items[j].Plain && o.items[j].Depth > o.items[i].Depth {
+		j++
+	}
+	return j
+}
+
+// hasChildren reports whether item i has nested items
+func (o *outline) hasChildren(i int) bool {
+	return o.end(i) > i+1
+}
+
+// previousSibling returns the item before i at its depth under the same
+// parent, or -1
+func (o *outline) previousSibling(i int) int {
+	for j := i - 1; j >= 0 && !o.items[j].Plain; j-- {
+		if o.items[j].Depth < o.items[i].Depth {
+			return -1
+		}
+		if o.items[j].Depth == o.items[i].Depth {
+			return j
+		}
+	}
+	return -1
+}
+
+// parent returns the item i is nested under, or -1
+func (o *outline) parent(i int) int {
+	for j := i - 1; j >= 0 && !o.items[j].Plain; j-- {
+		if o.items[j].Depth < o.items[i].Depth {
+			return j
+		}
+	}
+	return -1
+}
+
+// visible returns the indexes of the items not hidden in a folded item
+func (o *outline) visible() []int {
+	var shown []int
+	for i := 0; i < len(o.items); i++ {
+		shown = append(shown, i)
+		if o.items[i].Folded {
+			i = o.end(i) - 1
+		}
+	}
+	return shown
+}
+
+// moveUp swaps item i and its children with the sibling before it and
+// returns the item's new index
+func (o *outline) moveUp(i int) (int, bool) {
+	prev := o.previousSibling(i)
+	if prev < 0 {
+		return i, false
+	}
+	o.swap(prev, i, o.end(i))
+	return prev, true
+}
+
+// moveDown swaps item i and its children with the sibling after it and
+// returns the item's new index
+func (o *outline) moveDown(i int) (int, bool) {
+	next := o.end(i)
+	if next >= len(o.items) || o.items[next].Plain || o.items[next].Depth != o.items[i].Depth {
+		return i, false
+	}
+	end := o.end(next)
+	o.swap(i, next, end)
+	return i + end - next, true
+}
+
+// swap exchanges the adjacent runs items[a:b] and items[b:c]
+func (o *outline) swap(a, b, c int) {
+	moved := append(append([]*outlineItem{}, o.items[b:c]...), o.items[a:b]...)
+	copy(o.items[a:c], moved)
+}
+
+// indentItem nests item i and its children under the sibling before it
+func (o *outline) indentItem(i int) bool {
+	prev := o.previousSibling(i)
+	if prev < 0 {
+		return false
+	}
+	o.items[prev].Folded = false
+	for j, end := i, o.end(i); j < end; j++ {
+		o.items[j].Depth++
+	}
+	return true
+}
+
+// outdentItem makes item i and its children the next sibling of its
+// parent, after the parent's other children, and returns its new index
+func (o *outline) outdentItem(i int) (int, bool) {
+	parent := o.parent(i)
+	if parent < 0 {
+		return i, false
+	}
+	end, parentEnd := o.end(i), o.end(parent)
+	o.swap(i, end, parentEnd)
+	at := parentEnd - (end - i)
+	for j := at; j < parentEnd; j++ {
+		o.items[j].Depth--
+	}
+	return at, true
+}
+
+// insertAfter adds an item after item i and its children, at i's depth,
+// and returns its index
+func (o *outline) insertAfter(i int, text string) int {
+	at, item := o.end(i), &outlineItem{Depth: o.items[i].Depth, Marker: o.items[i].Marker, Text: text}
+	o.items = append(o.items[:at], append([]*outlineItem{item}, o.items[at:]...)...)
+	return at
+}
+
+// remove deletes item i and its children
+func (o *outline) remove(i int) {
+	o.items = append(o.items[:i], o.items[o.end(i):]...)
+}
+
+// render draws the visible items from first, height lines at most,
+// highlighting the selected one
+func (o *outline) render(selected, first, height, width int) string {
+	var out strings.Builder
+	shown := o.visible()
+	for _, i := range shown[min(first, len(shown)):min(first+height, len(shown))] {
+		item := o.items[i]
+		line := item.Line
+		if !item.Plain {
+			glyph := "•"
+			if o.hasChildren(i) && item.Folded {
+				glyph = "▸"
+			} else if o.hasChildren(i) {
+				glyph = "▾"
+			}
+			line = strings.Repeat("  ", item.Depth) + glyph + " " + item.Text
+		}
+		line = strings.TrimRight(strings.ReplaceAll(line, "\t", "    "), " ")
+		if len([]rune(line)) > width {
+			line = padRight(line, width)
+		}
+		if i == selected {
+			line = ColorSelected + padRight(line, width) + ColorReset
+		}
+		out.WriteString(line + "\n")
+	}
+	return out.String()
+}
+
+// runOutline opens a note's lists in a minimal outliner. Arrow keys or
+// j/k select an item, ←/→ (h/l) fold and unfold it, Tab and Shift-Tab
+// (> and <) indent and outdent it, J and K move it down and up with its
+// children, e edits it, o adds an item after it and d deletes it. Each
+// change is written back to the note as markdown. Without a terminal the
+// outline is printed.
+func runOutline(config Config, flags *ParsedFlags, name string) error {
+	notePath, err := existingNotePath(config, name)
+	if err != nil {
+		return err
+	}
+	if isEncryptedNote(notePath) {
+		return fmt.Errorf("%s is encrypted; use -e to edit it", filepath.Base(notePath))
+	}
+	if _, err := compactNote(config, notePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+	o := parseOutline(string(content))
+
+	if !isInputFromTerminal() || !isOutputToTerminal() {
+		fmt.Print(o.render(-1, 0, len(o.items), terminalWidth()))
+		return nil
+	}
+
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+	restore, err := rawTerminal()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	saved, changed := string(content), false
+	save := func() error {
+		return withNotesLock(config.NotesDir, func() error {
+			current, err := os.ReadFile(notePath)
+			if err != nil {
+				return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+			}
+			if string(current) != saved {
+				return fmt.Errorf("%s changed since the outline was loaded", filepath.Base(notePath))
+			}
+			updated := o.markdown()
+			if err := writeFileAtomic(notePath, []byte(updated), notePerm(notePath)); err != nil {
+				return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+			}
+			saved, changed = updated, true
+			return nil
+		})
+	}
+	defer func() {
+		if changed {
+			postSave(config, notePath)
+		}
+	}()
+
+	selected, first := -1, 0
+	step := func(dir int) {
+		shown := o.visible()
+		at := -1
+		for k, i := range shown {
+			if i == selected {
+				at = k
+			}
+		}
+		for k := at + dir; k >= 0 && k < len(shown); k += dir {
+			if !o.items[shown[k]].Plain {
+				selected = shown[k]
+				return
+			}
+		}
+	}
+	step(1)
+
+	message := ""
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		width, height := terminalSize()
+		rows := max(1, height-3)
+		row := 0
+		for k, i := range o.visible() {
+			if i == selected {
+				row = k
+			}
+		}
+		first = max(min(first, row), row-rows+1)
+		fmt.Print("\033[H\033[2J" + o.render(selected, first, rows, width))
+		if message == "" && selected < 0 {
+			message = "No list items in " + filepath.Base(notePath) + "; press o to add one"
+		}
+		fmt.Printf("\n%s\n↑↓ select  ←→ fold  Tab/S-Tab indent  J/K move  e edit  o add  d delete  q quit", message)
+		message = ""
+
+		key, err := readKey(reader)
+		if err != nil {
+			return nil
+		}
+		edited := false
+		switch key {
+		case "q", "\x03", "\x1b":
+			fmt.Println()
+			return nil
+		case "k", "up":
+			step(-1)
+		case "j", "down":
+			step(1)
+		case "o":
+			text := promptLine(reader, "New item: ")
+			if text == "" {
+				continue
+			}
+			if selected < 0 {
+				o.items = append(o.items, &outlineItem{Marker: "-", Text: text})
+				selected = len(o.items) - 1
+			} else {
+				selected = o.insertAfter(selected, text)
+			}
+			edited = true
+		}
+		if selected < 0 {
+			continue
+		}
+		item := o.items[selected]
+		switch key {
+		case "h", "left":
+			if o.hasChildren(selected) && !item.Folded {
+				item.Folded = true
+			} else if parent := o.parent(selected); parent >= 0 {
+				selected = parent
+			}
+		case "l", "right", "\r", "\n", " ":
+			if o.hasChildren(selected) {
+				item.Folded = key != "l" && key != "right" && !item.Folded
+			}
+		case "K":
+			selected, edited = o.moveUp(selected)
+		case "J":
+			selected, edited = o.moveDown(selected)
+		case "\t", ">":
+			edited = o.indentItem(selected)
+		case "backtab", "<":
+			selected, edited = o.outdentItem(selected)
+		case "e":
+			if text := editLine(reader, "Edit: ", item.Text); text != "" && text != item.Text {
+				item.Text, edited = text, true
+			}
+		case "d":
+			if promptLine(reader, "Delete this item and its children? (y/N) ") == "y" {
+				o.remove(selected)
+				// Select the item that took its place, or else the one before
+				if selected >= len(o.items) || o.items[selected].Plain {
+					previous := -1
+					for _, i := range o.visible() {
+						if i < selected && !o.items[i].Plain {
+							previous = i
+						}
+					}
+					selected = previous
+				}
+				edited = true
+			}
+		}
+		if edited {
+			if err := save(); err != nil {
+				fmt.Println()
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// clipboardImageCommands print the clipboard's image as PNG; the first one
+// found in PATH is used unless paste_image is set
+var clipboardImageCommands = [][]string{
+	{"wl-paste", "--no-newline", "--type", "image/png"},
+	{"xclip", "-selection", "clipboard", "-target", "image/png", "-out"},
+	{"pngpaste", "-"},
+}
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// runPasteImage saves the image on the clipboard in the attachments folder
+// and appends a link to it, honoring --under and --prepend like --append:
+//
+//	note --paste-image <name> [--under "## Screenshots"]
+func runPasteImage(config Config, flags *ParsedFlags, args []string) error {
+	name := strings.Join(args, " ")
+	if name == "" {
+		return usageErrorf("usage: note --paste-image <name>")
+	}
+	notePath := resolveNotePath(config, name)
+
+	image, err := clipboardImage(config)
+	if err != nil {
+		return err
+	}
+	base := strings.TrimSuffix(filepath.Base(notePath), filepath.Ext(notePath))
+	attachment, err := saveAttachment(config.NotesDir, base+"-"+time.Now().Format("20060102-150405")+".png", image)
+	if err != nil {
+		return err
+	}
+
+	// Link relative to the note so it also renders outside note
+	link, err := filepath.Rel(filepath.Dir(notePath), filepath.Join(config.NotesDir, attachment))
+	if err != nil {
+		link = attachment
+	}
+	fmt.Printf("Saved %s\n", filepath.ToSlash(attachment))
+	alt := strings.TrimSuffix(filepath.Base(attachment), ".png")
+	return appendCapture(config, flags, name, fmt.Sprintf("![%s](%s)", alt, filepath.ToSlash(link)))
+}
+
+// clipboardImage reads the clipboard's image as PNG data
+func clipboardImage(config Config) ([]byte, error) {
+	command := strings.Fields(config.option("paste_image"))
+	if len(command) == 0 {
+		for _, candidate := range clipboardImageCommands {
+			if _, err := exec.LookPath(candidate[0]); err == nil {
+				command = candidate
+				break
+			}
+		}
+	}
+	if len(command) == 0 {
+		return nil, fmt.Errorf("no clipboard tool found; install wl-clipboard, xclip or pngpaste, or set paste_image")
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil || !bytes.HasPrefix(stdout.Bytes(), pngSignature) {
+		return nil, fmt.Errorf("the clipboard does not hold an image")
+	}
+	return stdout.Bytes(), nil
+}
+
+// saveAttachment writes data to a new file in the attachments folder,
+// returning its path relative to the notes directory. An existing file is
+// never overwritten; a counter is added to the name instead.
+func saveAttachment(notesDir, name string, data []byte) (string, error) {
+	dir := filepath.Join(notesDir, AttachmentsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating %s: %w", AttachmentsDir, err)
+	}
+	ext := filepath.Ext(name)
+	candidate := name
+	for i := 2; ; i++ {
+		file, err := os.OpenFile(filepath.Join(dir, candidate), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			candidate = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("error saving %s: %w", candidate, err)
+		}
+		_, err = file.Write(data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(file.Name())
+			return "", fmt.Errorf("error saving %s: %w", candidate, err)
+		}
+		return filepath.Join(AttachmentsDir, candidate), nil
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PeopleDir holds one page per person, e.g. people/alex.md for @alex
+const PeopleDir = "people"
+
+// personName normalizes a person's handle: "@Alex" and "alex" are the same
+func personName(name string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "@"))
+}
+
+// runPerson opens a person's page, creating it with a heading on first use
+func runPerson(config Config, flags *ParsedFlags, name string) error {
+	name = personName(name)
+	if name == "" {
+		return usageErrorf("usage: note --person <name>")
+	}
+	dir := filepath.Join(config.NotesDir, PeopleDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %w", PeopleDir, err)
+	}
+
+	notePath := filepath.Join(dir, name+".md")
+	if _, err := os.Stat(notePath); os.IsNotExist(err) {
+		if err := createNoteFile(notePath, []byte("# @"+name+"\n")); err != nil {
+			return err
+		}
+	}
+
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+	return editNote(config, notePath)
+}
+
+// runMentions lists every note that mentions @name, with the lines it
+// appears on
+func runMentions(config Config, flags *ParsedFlags, name string) error {
+	name = personName(name)
+	if name == "" {
+		return usageErrorf("usage: note --mentions <name>")
+	}
+	ctx, stop := interruptible()
+	defer stop()
+	results, err := findMentions(ctx, config, name, flags.Archive)
+	if err != nil {
+		return fmt.Errorf("search %w", errInterrupted)
+	}
+	if len(results) == 0 {
+		fmt.Printf("No notes mention @%s\n", name)
+		return nil
+	}
+	printSearchResults(results, []string{"@" + name})
+	return nil
+}
+
+// findMentions searches for @name as a whole handle, so @alex does not
+// match @alexandra or alex@example.com
+func findMentions(ctx context.Context, config Config, name string, includeArchived bool) ([]SearchResult, error) {
+	mention := regexp.MustCompile(`(?i)(^|[^\w@.])@` + regexp.QuoteMeta(name) + `\b`)
+
+	candidates, err := searchDirs(ctx, config, searchRoots(config, includeArchived), []string{"@" + name})
+	if err != nil {
+		return nil, err
+	}
+	var results []SearchResult
+	for _, result := range candidates {
+		var matches []SearchMatch
+		for _, match := range result.Matches {
+			if mention.MatchString(match.Text) {
+				matches = append(matches, match)
+			}
+		}
+		if len(matches) > 0 {
+			result.Matches = matches
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package notes
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Config is the parsed ~/.note configuration
+type Config struct {
+	Editor   string
+	NotesDir string
+	// Options holds any additional key=value settings from ~/.note so they
+	// survive a reconfigure even when setup doesn't ask about them
+	Options map[string]string
+	// Defaults holds the [defaults] section: flags (without the leading
+	// --) that are applied before the command line, which overrides them
+	Defaults map[string]string
+	// Schedule holds the [schedule] section: recurring note names and when
+	// they recur, e.g. 1:1-with-alex=tuesday
+	Schedule map[string]string
+	// Editors holds the [editors] section: the editor for notes with an
+	// extension (.org=emacs) or in a notebook (drawings/=krita)
+	Editors map[string]string
+	// Visibility holds the [visibility] section: who --serve shows the
+	// notes in a notebook to (private, shared or public), with * for the
+	// rest
+	Visibility map[string]string
+}
+
+// Option returns the value of an optional config setting as written in the
+// file, or "" if unset
+func (c Config) Option(key string) string {
+	return c.Options[key]
+}
+
+// BoolOption reports whether an optional config setting is switched on
+func (c Config) BoolOption(key string) bool {
+	return IsTrue(c.Option(key))
+}
+
+// IsTrue reports whether a config value switches a setting on
+func IsTrue(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
+
+// SplitList splits a comma-separated config value, e.g. "ref-, howto-",
+// trimming each entry and dropping empty ones
+func SplitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// ParseConfig reads ~/.note: key=value settings, optionally followed by a
+// [defaults] section of flags applied to every invocation, a [schedule]
+// section of recurring notes, an [editors] section and a [visibility]
+// section
+func ParseConfig(r io.Reader) Config {
+	config := Config{}
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		switch section {
+		case "defaults":
+			if config.Defaults == nil {
+				config.Defaults = make(map[string]string)
+			}
+			config.Defaults[key] = value
+			continue
+		case "schedule":
+			if config.Schedule == nil {
+				config.Schedule = make(map[string]string)
+			}
+			config.Schedule[key] = value
+			continue
+		case "editors":
+			if config.Editors == nil {
+				config.Editors = make(map[string]string)
+			}
+			config.Editors[key] = value
+			continue
+		case "visibility":
+			if config.Visibility == nil {
+				config.Visibility = make(map[string]string)
+			}
+			config.Visibility[key] = value
+			continue
+		}
+
+		switch key {
+		case "editor":
+			config.Editor = value
+		case "notesdir":
+			config.NotesDir = ExpandPath(value)
+		default:
+			if config.Options == nil {
+				config.Options = make(map[string]string)
+			}
+			config.Options[key] = value
+		}
+	}
+	return config
+}
+
+// ExpandPath expands a leading ~/ and resolves symlinks, returning the path
+// unchanged if it doesn't exist yet
+func ExpandPath(path string) string {
+	// Handle tilde expansion first
+	if strings.HasPrefix(path, "~/") {
+		homeDir, _ := os.UserHomeDir()
+		path = filepath.Join(homeDir, path[2:])
+	}
+
+	// Resolve symbolic links to get the actual path
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		// If we can't resolve symlinks, return the original path
+		// This handles cases where the path doesn't exist yet or other errors
+		return path
+	}
+
+	return resolvedPath
+}
+
+// DefaultMaxFileSize is the largest note searched unless maxfilesize is set
+const DefaultMaxFileSize = 10 << 20
+
+// ParseFileSize parses a maxfilesize setting in bytes, e.g. 10485760, 512K
+// or 10M, falling back to DefaultMaxFileSize when empty or invalid
+func ParseFileSize(value string) int64 {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier, value = 1<<10, strings.TrimSuffix(value, "K")
+	case strings.HasSuffix(value, "M"):
+		multiplier, value = 1<<20, strings.TrimSuffix(value, "M")
+	case strings.HasSuffix(value, "G"):
+		multiplier, value = 1<<30, strings.TrimSuffix(value, "G")
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size <= 0 {
+		return DefaultMaxFileSize
+	}
+	return size * multiplier
+}
@@ -0,0 +1,185 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package notes
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SplitLines splits content into lines without a trailing empty element
+func SplitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// JoinLines is the inverse of SplitLines
+func JoinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// HeadingLevel returns the markdown heading level of a line (0 if not a heading)
+func HeadingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ') {
+		return 0
+	}
+	return level
+}
+
+// FrontmatterEnd returns the index of the first line after a leading YAML
+// frontmatter block, or 0 when the note has none
+func FrontmatterEnd(lines []string) int {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// ParseFrontmatter reads simple "key: value" pairs from a leading YAML
+// frontmatter block. Nested YAML is not supported; values are returned
+// as written, with surrounding quotes removed.
+func ParseFrontmatter(content string) map[string]string {
+	values := make(map[string]string)
+	lines := SplitLines(content)
+	end := FrontmatterEnd(lines)
+	for i := 1; i < end-1; i++ {
+		key, value, ok := strings.Cut(lines[i], ":")
+		if !ok || strings.HasPrefix(lines[i], " ") || strings.HasPrefix(lines[i], "\t") {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return values
+}
+
+// Title returns the title of a note: the first level-1 heading, falling
+// back to a frontmatter "title:" value
+func Title(content string) string {
+	lines := SplitLines(content)
+	inFence := false
+	for _, line := range lines[FrontmatterEnd(lines):] {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence && HeadingLevel(line) == 1 {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return ParseFrontmatter(content)["title"]
+}
+
+// Heading is one markdown heading in a note
+type Heading struct {
+	Line   int // index into the note's lines
+	Level  int
+	Text   string
+	Anchor string // fragment identifier, unique within the note
+}
+
+// Headings returns the headings of a note in order, skipping frontmatter
+// and fenced code. Anchors follow GitHub's rules, so links written against
+// a rendered note keep working: "## Next Steps!" is "next-steps", and a
+// second "Next Steps" heading is "next-steps-1".
+func Headings(lines []string) []Heading {
+	var headings []Heading
+	used := make(map[string]int)
+	inFence := false
+	for i := FrontmatterEnd(lines); i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		level := HeadingLevel(line)
+		if inFence || level == 0 {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+		anchor := Anchor(text)
+		if n, ok := used[anchor]; ok {
+			used[anchor] = n + 1
+			anchor = fmt.Sprintf("%s-%d", anchor, n+1)
+		} else {
+			used[anchor] = 0
+		}
+		headings = append(headings, Heading{Line: i, Level: level, Text: text, Anchor: anchor})
+	}
+	return headings
+}
+
+// Anchor turns heading text into a fragment identifier: lower case, with
+// spaces as hyphens and punctuation other than hyphens and underscores
+// dropped
+func Anchor(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// FindHeading returns the heading a link fragment points at, matching its
+// anchor or, ignoring case, its text
+func FindHeading(lines []string, fragment string) (Heading, bool) {
+	headings := Headings(lines)
+	for _, heading := range headings {
+		if heading.Anchor == fragment {
+			return heading, true
+		}
+	}
+	for _, heading := range headings {
+		if strings.EqualFold(heading.Text, strings.TrimSpace(fragment)) || heading.Anchor == Anchor(fragment) {
+			return heading, true
+		}
+	}
+	return Heading{}, false
+}
+
+// WordsPerMinute is the reading speed used for reading time estimates
+const WordsPerMinute = 200
+
+// ReadingTime estimates how many minutes it takes to read content
+func ReadingTime(content string) int {
+	words := len(strings.Fields(content))
+	minutes := (words + WordsPerMinute - 1) / WordsPerMinute
+	if minutes < 1 {
+		minutes = 1
+	}
+	return minutes
+}
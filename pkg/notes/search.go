@@ -0,0 +1,155 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package notes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SearchMatch is one matching line in a note
+type SearchMatch struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// SearchResult describes a note containing the search term
+type SearchResult struct {
+	Path           string        `json:"path"` // relative to the notes directory
+	Title          string        `json:"title,omitempty"`
+	ReadingMinutes int           `json:"reading_minutes"`
+	Matches        []SearchMatch `json:"matches"`
+}
+
+// Search returns every note matching q that contains any of q.Terms, along
+// with all of its matching lines; result paths are relative to the notes
+// directory. Notes larger than MaxFileSize and binary files are skipped, as
+// are encrypted notes unless Decrypt is set. It stops between notes once ctx
+// is cancelled and returns the context's error.
+func (s *Store) Search(ctx context.Context, q Query) ([]SearchResult, error) {
+	var results []SearchResult
+	lowerTerms := make([]string, len(q.Terms))
+	for i, term := range q.Terms {
+		lowerTerms[i] = strings.ToLower(term)
+	}
+	want := isMarkdown
+	if s.Decrypt != nil {
+		want = func(name string) bool { return isMarkdown(name) || IsEncrypted(name) }
+	}
+	archiveDir := s.ArchiveDir()
+	for _, dir := range s.roots(q) {
+		// Only note files are visited, following symlinks safely
+		err := walkFiles(dir, want, func(path string, info os.FileInfo) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			// Archived notes are searched only when the archive is one of
+			// the roots, and then only once
+			if dir != archiveDir && strings.HasPrefix(path, archiveDir+string(filepath.Separator)) {
+				return nil
+			}
+			if !matchesPattern(info.Name(), q.Pattern) {
+				return nil
+			}
+			relPath, _ := filepath.Rel(s.Dir, path)
+			if info.Size() > s.MaxFileSize {
+				s.skip(relPath, fmt.Sprintf("%s is larger than maxfilesize", FormatSize(info.Size())))
+				return nil
+			}
+
+			// Read file and search
+			var content []byte
+			var err error
+			if IsEncrypted(path) {
+				if content, err = s.Decrypt(path); err != nil {
+					s.skip(relPath, fmt.Sprintf("could not decrypt: %v", err))
+					return nil
+				}
+			} else if content, err = os.ReadFile(path); err != nil {
+				return nil
+			}
+			if IsBinary(content) {
+				s.skip(relPath, "looks like a binary file")
+				return nil
+			}
+
+			scanner := bufio.NewScanner(bytes.NewReader(content))
+			// A note is searched only if it fits in memory anyway, so let a
+			// single line be as long as the whole file
+			scanner.Buffer(make([]byte, 64*1024), int(s.MaxFileSize)+1)
+			lineNum := 0
+			var matches []SearchMatch
+			for scanner.Scan() {
+				lineNum++
+				line := scanner.Text()
+				lowerLine := strings.ToLower(line)
+				for _, lowerTerm := range lowerTerms {
+					if strings.Contains(lowerLine, lowerTerm) {
+						matches = append(matches, SearchMatch{Line: lineNum, Text: line})
+						break
+					}
+				}
+			}
+
+			if len(matches) > 0 {
+				results = append(results, SearchResult{
+					Path:           relPath,
+					Title:          Title(string(content)),
+					ReadingMinutes: ReadingTime(string(content)),
+					Matches:        matches,
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func (s *Store) skip(path, reason string) {
+	if s.Skipped != nil {
+		s.Skipped(path, reason)
+	}
+}
+
+// FormatSize renders a file size for messages, e.g. "12.3 MB"
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
+
+// IsBinary reports whether content looks like binary data rather than text,
+// using the same heuristic as git: a NUL byte near the start of the file
+func IsBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) != -1
+}
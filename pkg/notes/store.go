@@ -0,0 +1,504 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package notes is the core of the note CLI as a library: finding, resolving,
+// searching, archiving and deleting the markdown notes in a notes directory.
+// The CLI is one user; TUIs, bots and editor plugins can import it to work
+// on the same notes the same way.
+package notes
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Store is a notes directory
+type Store struct {
+	// Dir is the notes directory
+	Dir string
+	// MaxFileSize is the largest note Search reads, in bytes
+	MaxFileSize int64
+	// Skipped, if set, is called with the relative path of each note Search
+	// passes over and why
+	Skipped func(path, reason string)
+	// Decrypt, if set, returns the content of an encrypted note, and Search
+	// looks in encrypted notes too
+	Decrypt func(path string) ([]byte, error)
+	// Sticky are the name prefixes of single-file notes, e.g. reference
+	// docs, which Resolve never gives a date stamp
+	Sticky []string
+}
+
+// Note is one note file in a Store
+type Note struct {
+	// Name is the note's path relative to the notes directory, e.g.
+	// "plan-20240101.md" or "Archive/plan-20240101.md"
+	Name     string
+	Path     string
+	Modified time.Time
+}
+
+// Query selects notes
+type Query struct {
+	// Pattern matches note filenames as a glob or, failing that, a
+	// substring, ignoring case; empty matches every note
+	Pattern string
+	// Terms are searched for in note content, ignoring case; a note
+	// matches if it contains any of them
+	Terms []string
+	// Archived includes the archive directory
+	Archived bool
+	// Dirs, if set, are searched instead of the notes directory and archive
+	Dirs []string
+	// Encrypted includes encrypted notes (see IsEncrypted) in List
+	Encrypted bool
+}
+
+// EncryptedExtensions are added to a note's .md name when it is encrypted:
+// .age for age and .gpg for GnuPG
+var EncryptedExtensions = []string{".age", ".gpg"}
+
+// IsEncrypted reports whether name is an encrypted note, e.g. plan.md.age
+func IsEncrypted(name string) bool {
+	for _, ext := range EncryptedExtensions {
+		if strings.HasSuffix(name, ".md"+ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewStore returns the store for the notes in dir
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir, MaxFileSize: DefaultMaxFileSize}
+}
+
+// Open returns the store for the notes directory of config
+func Open(config Config) *Store {
+	store := NewStore(config.NotesDir)
+	store.MaxFileSize = ParseFileSize(config.Option("maxfilesize"))
+	store.Sticky = SplitList(config.Option("sticky"))
+	return store
+}
+
+// ArchiveDir returns the path to the archive directory, checking for both
+// "Archive" and "archive"
+func (s *Store) ArchiveDir() string {
+	// Check for "Archive" first (preferred)
+	archiveDir := filepath.Join(s.Dir, "Archive")
+	if _, err := os.Stat(archiveDir); err == nil {
+		return archiveDir
+	}
+
+	// Check for "archive" (lowercase)
+	archiveDir = filepath.Join(s.Dir, "archive")
+	if _, err := os.Stat(archiveDir); err == nil {
+		return archiveDir
+	}
+
+	// Default to "Archive" if neither exists (for new creation)
+	return filepath.Join(s.Dir, "Archive")
+}
+
+// roots returns the directories a query looks in
+func (s *Store) roots(q Query) []string {
+	if len(q.Dirs) > 0 {
+		return q.Dirs
+	}
+	dirs := []string{s.Dir}
+	if q.Archived {
+		dirs = append(dirs, s.ArchiveDir())
+	}
+	return dirs
+}
+
+// List returns the notes whose filenames match q.Pattern, sorted by name.
+// Only the top level of each directory is listed.
+func (s *Store) List(q Query) []Note {
+	var notes []Note
+	for _, dir := range s.roots(q) {
+		names := Match(dir, q.Pattern, false)
+		if q.Encrypted {
+			names = append(names, matchEncrypted(dir, q.Pattern)...)
+		}
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			if rel, err := filepath.Rel(s.Dir, path); err == nil {
+				name = filepath.ToSlash(rel)
+			}
+			note := Note{Name: name, Path: path}
+			if info, err := os.Stat(path); err == nil {
+				note.Modified = info.ModTime()
+			}
+			notes = append(notes, note)
+		}
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].Name < notes[j].Name })
+	return notes
+}
+
+// Resolve maps a note name to its file: an explicit .md filename is used
+// as-is, then an exact match for name.md, today's dated note, and a note
+// whose title matches the name. Encrypted notes stand in for the .md notes
+// they were made from. Otherwise the name refers to today's dated note,
+// which may not exist yet. Sticky names always refer to name.md, never to
+// a dated note.
+func (s *Store) Resolve(name string) string {
+	// Check if it's a specific file with .md extension
+	if strings.HasSuffix(name, ".md") || IsEncrypted(name) {
+		return filepath.Join(s.Dir, name)
+	}
+
+	// Check if there's an exact match for name.md (existing file)
+	// This handles cases like 'roloText-Meeting-Notes-20240426' which should open 'roloText-Meeting-Notes-20240426.md'
+	exactPath := filepath.Join(s.Dir, name+".md")
+	if path, ok := existingNote(exactPath); ok {
+		return path
+	}
+
+	// Single-file notes are never dated
+	if s.IsSticky(name) {
+		path, _ := existingNote(filepath.Join(s.Dir, strings.ReplaceAll(name, " ", "_")+".md"))
+		return path
+	}
+
+	// Today's dated note wins if it already exists
+	datedPath := filepath.Join(s.Dir, DatedFilename(name, time.Now()))
+	if path, ok := existingNote(datedPath); ok {
+		return path
+	}
+
+	// Match against note titles (first "# heading"), newest note first
+	if matches := s.FindByTitle(name); len(matches) > 0 {
+		return s.newest(matches)
+	}
+
+	// Otherwise use today's dated filename
+	return datedPath
+}
+
+// IsSticky reports whether name is a single-file note, one starting with
+// a prefix in Sticky, ignoring case
+func (s *Store) IsSticky(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range s.Sticky {
+		if strings.HasPrefix(name, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// existingNote returns the note at path, or its encrypted form when only
+// that exists
+func existingNote(path string) (string, bool) {
+	if _, err := os.Stat(path); err == nil {
+		return path, true
+	}
+	for _, ext := range EncryptedExtensions {
+		if _, err := os.Stat(path + ext); err == nil {
+			return path + ext, true
+		}
+	}
+	return path, false
+}
+
+// newest returns the path of the most recently modified of notes
+func (s *Store) newest(notes []string) string {
+	newest := filepath.Join(s.Dir, notes[0])
+	var newestTime time.Time
+	for _, note := range notes {
+		path := filepath.Join(s.Dir, note)
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(newestTime) {
+			newest, newestTime = path, info.ModTime()
+		}
+	}
+	return newest
+}
+
+// Archive moves a note from the notes directory into the archive, creating
+// the archive directory if needed
+func (s *Store) Archive(name string) error {
+	archiveDir := s.ArchiveDir()
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return fmt.Errorf("error creating archive directory: %w", err)
+	}
+	return MoveNote(filepath.Join(s.Dir, name), filepath.Join(archiveDir, name))
+}
+
+// ArchiveNotebook moves the folder notebook, relative to the notes
+// directory, into the archive directory with everything in it, keeping its
+// path: "work/apollo" becomes "Archive/work/apollo". It never replaces an
+// existing folder; the error then wraps fs.ErrExist.
+func (s *Store) ArchiveNotebook(notebook string) error {
+	dst := filepath.Join(s.ArchiveDir(), notebook)
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists: %w", dst, fs.ErrExist)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("error creating archive directory: %w", err)
+	}
+	return os.Rename(filepath.Join(s.Dir, notebook), dst)
+}
+
+// Restore moves the archived note name back to the notes directory as
+// newName (usually the same name). It never replaces an existing note; the
+// error then wraps fs.ErrExist.
+func (s *Store) Restore(name, newName string) error {
+	dst := filepath.Join(s.Dir, newName)
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists: %w", newName, fs.ErrExist)
+	}
+	return MoveNote(filepath.Join(s.ArchiveDir(), name), dst)
+}
+
+// DatedFilename builds the filename for a new note, replacing spaces with
+// underscores and appending the -YYYYMMDD date stamp
+func DatedFilename(name string, date time.Time) string {
+	cleanName := strings.ReplaceAll(name, " ", "_")
+	return fmt.Sprintf("%s-%s.md", cleanName, date.Format("20060102"))
+}
+
+// Match returns the names of the notes in dir matching pattern as a glob or,
+// failing that, a substring, ignoring case. Subdirectories are only searched
+// when includeSubdirs is set.
+func Match(dir, pattern string, includeSubdirs bool) []string {
+	var notes []string
+
+	// Walk the directory
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		// Skip Archive directory unless we want subdirs
+		if !includeSubdirs && info.IsDir() && path != dir {
+			return filepath.SkipDir
+		}
+
+		// Only look for .md files
+		if !strings.HasSuffix(info.Name(), ".md") {
+			return nil
+		}
+
+		// Symlinks count as notes only if they resolve to a note file
+		if info.Mode()&os.ModeSymlink != 0 && !IsNote(path) {
+			return nil
+		}
+
+		// Skip if in Archive subdirectory (unless we want subdirs)
+		relPath, _ := filepath.Rel(dir, path)
+		if !includeSubdirs && strings.Contains(relPath, string(os.PathSeparator)) {
+			return nil
+		}
+
+		if matchesPattern(info.Name(), pattern) {
+			notes = append(notes, info.Name())
+		}
+		return nil
+	})
+
+	return notes
+}
+
+// matchEncrypted returns the names of the encrypted notes at the top of
+// dir matching pattern, like Match
+func matchEncrypted(dir, pattern string) []string {
+	var names []string
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if IsEncrypted(entry.Name()) && !entry.IsDir() && matchesPattern(entry.Name(), pattern) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+// matchesPattern reports whether a note filename matches pattern
+// (case-insensitive), supporting both glob patterns and substring matching
+func matchesPattern(name, pattern string) bool {
+	if pattern == "" {
+		return true
+	}
+	// First try glob pattern matching
+	matched, err := filepath.Match(strings.ToLower(pattern), strings.ToLower(name))
+	if err == nil && matched {
+		return true
+	}
+	// Fall back to substring matching if not a valid glob or no match
+	return strings.Contains(strings.ToLower(name), strings.ToLower(pattern))
+}
+
+// Walk calls fn for every .md file under root, following symlinked files
+// and directories. Each real directory is visited at most once, so symlink
+// cycles can't cause infinite loops. fn receives the path as seen through
+// the links and the info of the link target.
+func Walk(root string, fn func(path string, info os.FileInfo) error) error {
+	return walkFiles(root, isMarkdown, fn)
+}
+
+// walkFiles is Walk for the files whose names match want
+func walkFiles(root string, want func(name string) bool, fn func(path string, info os.FileInfo) error) error {
+	visited := make(map[string]bool)
+	return walkDir(root, visited, want, fn)
+}
+
+func isMarkdown(name string) bool {
+	return strings.HasSuffix(name, ".md")
+}
+
+func walkDir(dir string, visited map[string]bool, want func(name string) bool, fn func(path string, info os.FileInfo) error) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil || visited[realDir] {
+		return nil
+	}
+	visited[realDir] = true
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		// os.Stat follows symlinks; broken links are skipped
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			// Hidden directories hold note's own data (.snippets) or
+			// tooling such as .git, not notes
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			if err := walkDir(path, visited, want, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if !info.Mode().IsRegular() || !want(entry.Name()) {
+			continue
+		}
+		if err := fn(path, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsNote reports whether path is a note: a .md regular file, or a symlink
+// that resolves to one
+func IsNote(path string) bool {
+	if !strings.HasSuffix(path, ".md") {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// MoveNote moves a note to dstPath. Symlinked notes are moved as links, with
+// relative targets rewritten so they still resolve from the new location.
+func MoveNote(srcPath, dstPath string) error {
+	if info, err := os.Lstat(srcPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(srcPath)
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(target) {
+			absTarget := filepath.Join(filepath.Dir(srcPath), target)
+			if rel, err := filepath.Rel(filepath.Dir(dstPath), absTarget); err == nil {
+				target = rel
+			}
+		}
+		if err := os.Symlink(target, dstPath); err != nil {
+			return err
+		}
+		return os.Remove(srcPath)
+	}
+
+	if err := os.Rename(srcPath, dstPath); err != nil {
+		// Try copy and delete if rename fails (cross-device)
+		if err := CopyFile(srcPath, dstPath); err != nil {
+			return err
+		}
+		return os.Remove(srcPath)
+	}
+	return nil
+}
+
+// CopyFile copies the contents of src to dst
+func CopyFile(src, dst string) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer destination.Close()
+
+	_, err = io.Copy(destination, source)
+	return err
+}
+
+// WriteFileAtomic writes data to a temp file in the destination directory
+// and renames it into place, so readers never observe a partially written
+// file. A symlink at path is written through, so the link survives.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package notes
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "note-store-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	config := ParseConfig(strings.NewReader("editor=vim\nnotesdir=" + tempDir + "\nmaxfilesize=1K\nsticky=ref-, ,howto-\n"))
+	store := Open(config)
+	if store.Dir != tempDir || store.MaxFileSize != 1<<10 || strings.Join(store.Sticky, "|") != "ref-|howto-" {
+		t.Fatalf("Open: got dir %s, max %d, sticky %v", store.Dir, store.MaxFileSize, store.Sticky)
+	}
+
+	files := map[string]string{
+		"plan-20240101.md":    "# Budget Plan\nthe budget is tight\n",
+		"standup-20240102.md": "nothing about money\n",
+		"huge-20240103.md":    "budget " + strings.Repeat("x", 2<<10) + "\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Titles resolve to the note they head
+	if path := store.Resolve("budget plan"); path != filepath.Join(tempDir, "plan-20240101.md") {
+		t.Errorf("Resolve by title: got %s", path)
+	}
+	// Unknown names resolve to today's dated note
+	if path := store.Resolve("new idea"); path != filepath.Join(tempDir, DatedFilename("new idea", time.Now())) {
+		t.Errorf("Resolve new note: got %s", path)
+	}
+	// Sticky notes are never dated, even before they exist
+	if path := store.Resolve("ref-docker tips"); path != filepath.Join(tempDir, "ref-docker_tips.md") {
+		t.Errorf("Resolve sticky note: got %s", path)
+	}
+
+	var skipped []string
+	store.Skipped = func(path, reason string) { skipped = append(skipped, path) }
+	results, err := store.Search(context.Background(), Query{Terms: []string{"BUDGET"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Path != "plan-20240101.md" || results[0].Title != "Budget Plan" || len(results[0].Matches) != 2 {
+		t.Errorf("Search: got %+v", results)
+	}
+	if len(skipped) != 1 || skipped[0] != "huge-20240103.md" {
+		t.Errorf("Search should skip the oversized note, skipped %v", skipped)
+	}
+
+	if err := store.Archive("plan-20240101.md"); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, note := range store.List(Query{Pattern: "2024", Archived: true}) {
+		names = append(names, note.Name)
+	}
+	if strings.Join(names, " ") != "Archive/plan-20240101.md huge-20240103.md standup-20240102.md" {
+		t.Errorf("List: got %v", names)
+	}
+	if got := store.List(Query{Pattern: "plan"}); len(got) != 0 {
+		t.Errorf("archived notes should not be listed without Archived, got %v", got)
+	}
+
+	os.WriteFile(filepath.Join(tempDir, "plan-20240101.md"), []byte("newer plan\n"), 0644)
+	if err := store.Restore("plan-20240101.md", "plan-20240101.md"); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("Restore over an existing note: got %v, want fs.ErrExist", err)
+	}
+	if err := store.Restore("plan-20240101.md", "plan-20240101-2.md"); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(tempDir, "plan-20240101.md")); string(content) != "newer plan\n" {
+		t.Errorf("Restore replaced the existing note: %q", content)
+	}
+	if got := store.List(Query{Pattern: "plan-20240101-2"}); len(got) != 1 {
+		t.Errorf("restored note should be listed, got %v", got)
+	}
+}
+
+func TestHeadings(t *testing.T) {
+	lines := SplitLines("---\ntitle: x\n---\n# Weekly Sync\n## Next Steps!\n```\n## Not a heading\n```\n## Next Steps\n### C# & Go ##\n")
+	var anchors []string
+	for _, heading := range Headings(lines) {
+		anchors = append(anchors, heading.Anchor)
+	}
+	if got := strings.Join(anchors, " "); got != "weekly-sync next-steps next-steps-1 c--go" {
+		t.Errorf("Anchors = %s", got)
+	}
+
+	for fragment, line := range map[string]int{"next-steps-1": 8, "weekly sync": 3, "Next Steps!": 4, "C# & Go": 9} {
+		if heading, ok := FindHeading(lines, fragment); !ok || heading.Line != line {
+			t.Errorf("FindHeading(%q) = %+v, %v; want line %d", fragment, heading, ok, line)
+		}
+	}
+	if _, ok := FindHeading(lines, "not-a-heading"); ok {
+		t.Error("Headings in code blocks should not match")
+	}
+}
+
+func TestEncryptedNotes(t *testing.T) {
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "diary-20240101.md.age"), []byte("sealed"), 0600)
+	os.WriteFile(filepath.Join(tempDir, "plan.md"), []byte("budget\n"), 0644)
+	store := NewStore(tempDir)
+
+	if path := store.Resolve("diary-20240101"); path != filepath.Join(tempDir, "diary-20240101.md.age") {
+		t.Errorf("Resolve should find the encrypted note, got %s", path)
+	}
+	if got := store.List(Query{}); len(got) != 1 {
+		t.Errorf("Encrypted notes are listed only when asked for, got %v", got)
+	}
+	if got := store.List(Query{Encrypted: true}); len(got) != 2 || got[0].Name != "diary-20240101.md.age" {
+		t.Errorf("List with Encrypted: got %v", got)
+	}
+
+	results, _ := store.Search(context.Background(), Query{Terms: []string{"secret"}})
+	if len(results) != 0 {
+		t.Errorf("Encrypted notes are not searched without Decrypt, got %v", results)
+	}
+	store.Decrypt = func(path string) ([]byte, error) { return []byte("a secret\n"), nil }
+	results, _ = store.Search(context.Background(), Query{Terms: []string{"secret"}})
+	if len(results) != 1 || results[0].Path != "diary-20240101.md.age" || results[0].Matches[0].Text != "a secret" {
+		t.Errorf("Search with Decrypt: got %+v", results)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	tempDir := t.TempDir()
+	store := NewStore(tempDir)
+	june := time.Date(2025, 6, 1, 9, 0, 0, 0, time.Local)
+	for _, content := range []string{"first\n", "second\n"} {
+		os.WriteFile(filepath.Join(tempDir, "plan.md"), []byte(content), 0644)
+		if err := store.Trash("plan.md", june); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(tempDir, "idea.md"), nil, 0644)
+	if err := store.Trash("idea.md", june.AddDate(0, 0, 20)); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.List(Query{}); len(got) != 0 {
+		t.Errorf("trashed notes should not be listed, got %v", got)
+	}
+
+	var names []string
+	for _, note := range store.Trashed(time.Time{}) {
+		names = append(names, note.Name)
+	}
+	if got := strings.Join(names, " "); got != ".Trash/2025-06-01/plan-2.md .Trash/2025-06-01/plan.md .Trash/2025-06-21/idea.md" {
+		t.Errorf("Trashed() = %s", got)
+	}
+	if got := store.Trashed(june.AddDate(0, 0, 20)); len(got) != 2 {
+		t.Errorf("Trashed(June 21) = %v; want the two notes deleted on June 1", got)
+	}
+	if count, err := store.Purge(june.AddDate(0, 0, 20)); err != nil || count != 2 {
+		t.Errorf("Purge(June 21) = %d, %v; want 2", count, err)
+	}
+	if count, err := store.Purge(time.Time{}); err != nil || count != 1 || len(store.Trashed(time.Time{})) != 0 {
+		t.Errorf("Purge(zero) = %d, %v; want the last note removed", count, err)
+	}
+}
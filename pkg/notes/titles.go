@@ -0,0 +1,125 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package notes
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TitleIndexFile caches each note's title in the notes directory so title
+// lookups don't have to read every note
+const TitleIndexFile = ".note_titles"
+
+// TitleEntry is one cached title, keyed by filename and modification time
+type TitleEntry struct {
+	ModTime int64
+	Title   string
+}
+
+// Titles returns the title of every note in the notes directory,
+// refreshing the on-disk cache for notes that changed since it was written
+func (s *Store) Titles() map[string]string {
+	indexPath := filepath.Join(s.Dir, TitleIndexFile)
+	cached := ReadTitleIndex(indexPath)
+
+	titles := make(map[string]string)
+	fresh := make(map[string]TitleEntry)
+	changed := false
+	for _, note := range Match(s.Dir, "", false) {
+		info, err := os.Stat(filepath.Join(s.Dir, note))
+		if err != nil {
+			continue
+		}
+		modTime := info.ModTime().UnixNano()
+		entry, ok := cached[note]
+		if !ok || entry.ModTime != modTime {
+			content, err := os.ReadFile(filepath.Join(s.Dir, note))
+			if err != nil {
+				continue
+			}
+			entry = TitleEntry{ModTime: modTime, Title: Title(string(content))}
+			changed = true
+		}
+		fresh[note] = entry
+		if entry.Title != "" {
+			titles[note] = entry.Title
+		}
+	}
+
+	if changed || len(fresh) != len(cached) {
+		// The cache is only an optimization, so write failures are ignored
+		writeTitleIndex(indexPath, fresh)
+	}
+	return titles
+}
+
+// ReadTitleIndex reads the title cache at indexPath as it is on disk
+func ReadTitleIndex(indexPath string) map[string]TitleEntry {
+	entries := make(map[string]TitleEntry)
+	file, err := os.Open(indexPath)
+	if err != nil {
+		return entries
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		modTime, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		entries[parts[0]] = TitleEntry{ModTime: modTime, Title: parts[2]}
+	}
+	return entries
+}
+
+func writeTitleIndex(indexPath string, entries map[string]TitleEntry) error {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s\t%d\t%s\n", name, entries[name].ModTime, entries[name].Title)
+	}
+	return WriteFileAtomic(indexPath, []byte(b.String()), 0644)
+}
+
+// FindByTitle returns the notes whose title matches title, ignoring case
+func (s *Store) FindByTitle(title string) []string {
+	var matches []string
+	for note, noteTitle := range s.Titles() {
+		if strings.EqualFold(strings.TrimSpace(noteTitle), strings.TrimSpace(title)) {
+			matches = append(matches, note)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
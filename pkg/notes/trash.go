@@ -0,0 +1,131 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package notes
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TrashDir holds deleted notes inside the notes directory, in one folder
+// per day they were deleted on, e.g. .Trash/2025-06-01/plan.md. Like other
+// hidden directories it is never listed or searched.
+const TrashDir = ".Trash"
+
+// trashDayLayout names the trash's day folders
+const trashDayLayout = "2006-01-02"
+
+// TrashPath returns where Trash puts the note name when it is deleted at t:
+// under the day's folder, with the next free name (see FreeName) if a note
+// of that name was already deleted that day
+func (s *Store) TrashPath(name string, t time.Time) string {
+	path := filepath.Join(s.Dir, TrashDir, t.Format(trashDayLayout), name)
+	if _, err := os.Lstat(path); err == nil {
+		path = filepath.Join(filepath.Dir(path), FreeName(filepath.Dir(path), filepath.Base(path)))
+	}
+	return path
+}
+
+// Trash moves the note name into the trash as deleted at t
+func (s *Store) Trash(name string, t time.Time) error {
+	dst := s.TrashPath(name, t)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("error creating %s: %w", TrashDir, err)
+	}
+	return MoveNote(filepath.Join(s.Dir, name), dst)
+}
+
+// Trashed returns the notes deleted on a day before the day of before,
+// named relative to the notes directory; a zero before returns everything
+// in the trash
+func (s *Store) Trashed(before time.Time) []Note {
+	var trashed []Note
+	for _, dir := range s.trashDirs(before) {
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			note := Note{Path: path}
+			if rel, err := filepath.Rel(s.Dir, path); err == nil {
+				note.Name = filepath.ToSlash(rel)
+			}
+			if info, err := d.Info(); err == nil {
+				note.Modified = info.ModTime()
+			}
+			trashed = append(trashed, note)
+			return nil
+		})
+	}
+	sort.Slice(trashed, func(i, j int) bool { return trashed[i].Name < trashed[j].Name })
+	return trashed
+}
+
+// Purge permanently removes the notes Trashed(before) returns, and returns
+// how many there were
+func (s *Store) Purge(before time.Time) (int, error) {
+	count := len(s.Trashed(before))
+	for _, dir := range s.trashDirs(before) {
+		if err := os.RemoveAll(dir); err != nil {
+			return 0, fmt.Errorf("error removing %s: %w", dir, err)
+		}
+	}
+	return count, nil
+}
+
+// trashDirs returns the trash's day folders from before the day of before,
+// or every entry in the trash when before is zero
+func (s *Store) trashDirs(before time.Time) []string {
+	trashDir := filepath.Join(s.Dir, TrashDir)
+	entries, err := os.ReadDir(trashDir)
+	if err != nil {
+		return nil
+	}
+	cutoff := time.Date(before.Year(), before.Month(), before.Day(), 0, 0, 0, 0, time.Local)
+	var dirs []string
+	for _, entry := range entries {
+		if !before.IsZero() {
+			day, err := time.ParseInLocation(trashDayLayout, entry.Name(), time.Local)
+			if err != nil || !entry.IsDir() || !day.Before(cutoff) {
+				continue
+			}
+		}
+		dirs = append(dirs, filepath.Join(trashDir, entry.Name()))
+	}
+	return dirs
+}
+
+// FreeName returns name with the first -2, -3, ... suffix not yet used in
+// dir, keeping the .md (and any encryption) extension
+func FreeName(dir, name string) string {
+	ext := ".md"
+	if IsEncrypted(name) {
+		ext += filepath.Ext(name)
+	}
+	stem := strings.TrimSuffix(name, ext)
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d%s", stem, n, ext)
+		if _, err := os.Lstat(filepath.Join(dir, candidate)); err != nil {
+			return candidate
+		}
+	}
+}
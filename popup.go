@@ -0,0 +1,112 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// terminalFallbacks are tried in order when not running inside tmux and no
+// terminal is configured
+var terminalFallbacks = [][]string{
+	{"x-terminal-emulator", "-e"},
+	{"gnome-terminal", "--"},
+	{"konsole", "-e"},
+	{"xterm", "-e"},
+}
+
+// runPop handles `note --pop <name>`: the note is opened by a new note
+// process in a tmux popup or split, or in a new terminal window, so a quick
+// capture doesn't take over the current pane
+func runPop(config Config, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("--pop requires a note name")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not determine note command path: %w", err)
+	}
+
+	noteArgs := append(append([]string{exe}, globalFlagArgs()...), args...)
+	command, err := popupCommand(config, noteArgs, os.Getenv("TMUX") != "", exec.LookPath)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running %s: %w", command[0], err)
+	}
+	return nil
+}
+
+// popupCommand builds the command that runs noteCommand somewhere other
+// than the current pane. Inside tmux the tmux= option picks a popup
+// (default), split, or window; outside tmux the terminal= option or the
+// first available terminal emulator is used.
+func popupCommand(config Config, noteCommand []string, inTmux bool, lookPath func(string) (string, error)) ([]string, error) {
+	shellCommand := shellQuoteAll(noteCommand)
+
+	if inTmux {
+		switch mode := config.option("tmux"); mode {
+		case "", "popup":
+			return []string{"tmux", "display-popup", "-E", "-w", "80%", "-h", "80%", shellCommand}, nil
+		case "split":
+			return []string{"tmux", "split-window", "-v", shellCommand}, nil
+		case "window":
+			return []string{"tmux", "new-window", shellCommand}, nil
+		default:
+			return nil, fmt.Errorf("unknown tmux mode '%s' (use popup, split or window)", mode)
+		}
+	}
+
+	if terminal := strings.Fields(config.option("terminal")); len(terminal) > 0 {
+		return append(terminal, noteCommand...), nil
+	}
+	for _, terminal := range terminalFallbacks {
+		if _, err := lookPath(terminal[0]); err == nil {
+			return append(append([]string{}, terminal...), noteCommand...), nil
+		}
+	}
+	return nil, fmt.Errorf("not inside tmux and no terminal emulator found; set terminal= in ~/.note")
+}
+
+// shellQuote quotes s for use as a single POSIX shell word
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:@%+,", r))
+	}) == -1 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellQuoteAll quotes and joins args into one shell command line
+func shellQuoteAll(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
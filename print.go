@@ -0,0 +1,278 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Print layout: Courier 10pt on a page that fits both Letter and A4
+const (
+	PrintColumns      = 80
+	PrintLinesPerPage = 54 // body lines, between the header and the footer
+	printPageWidth    = 612
+	printPageHeight   = 792
+	printFontSize     = 10
+	printLeading      = 12
+	printMarginLeft   = 66
+	printMarginTop    = 60
+)
+
+// runPrint renders a note and sends it to the print spooler, or writes it
+// to a PDF instead:
+//
+//	note --print <name> [--pdf <file>]
+//
+// Every page has the note's title and date at the top and its name and page
+// number at the bottom. The spooler is lp, or lpr where there is no lp,
+// unless print_command is set (e.g. print_command=lp -d office).
+func runPrint(config Config, args []string) error {
+	var pdfPath string
+	var nameArgs []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--pdf" {
+			if i+1 >= len(args) {
+				return usageErrorf("--pdf requires a file name")
+			}
+			i++
+			pdfPath = args[i]
+			continue
+		}
+		nameArgs = append(nameArgs, args[i])
+	}
+	if len(nameArgs) == 0 {
+		return usageErrorf("usage: note --print <name> [--pdf <file>]")
+	}
+
+	notePath, err := existingNotePath(config, strings.Join(nameArgs, " "))
+	if err != nil {
+		return err
+	}
+	content, err := readNoteWithCaptures(config, notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+	info, err := os.Stat(notePath)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(notePath)
+	title := noteTitle(string(content))
+	if title == "" {
+		title = strings.TrimSuffix(name, ".md")
+	}
+	pages := printPages(title, noteDate(name, info.ModTime()), name, renderForPrint(expandEmoji(config, string(content))))
+	var pdf bytes.Buffer
+	if err := writePrintPDF(&pdf, pages); err != nil {
+		return err
+	}
+
+	if pdfPath != "" {
+		if err := os.WriteFile(pdfPath, pdf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("error writing %s: %w", pdfPath, err)
+		}
+		fmt.Printf("Wrote %s (%d page(s))\n", pdfPath, len(pages))
+		return nil
+	}
+
+	command, err := printCommand(config)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = &pdf
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", command[0], err)
+	}
+	fmt.Printf("Sent %s to the printer (%d page(s))\n", name, len(pages))
+	return nil
+}
+
+// printCommand returns the spooler command, which reads the PDF on stdin
+func printCommand(config Config) ([]string, error) {
+	if command := strings.Fields(config.option("print_command")); len(command) > 0 {
+		return command, nil
+	}
+	for _, spooler := range []string{"lp", "lpr"} {
+		if _, err := exec.LookPath(spooler); err == nil {
+			return []string{spooler}, nil
+		}
+	}
+	return nil, fmt.Errorf("neither lp nor lpr found in PATH; set print_command or use --pdf <file>")
+}
+
+// renderForPrint turns markdown into plain lines for the page: frontmatter
+// is dropped, headings lose their #s and the top two levels are underlined,
+// and long lines are wrapped keeping their indentation. Code blocks are
+// kept as they are apart from wrapping.
+func renderForPrint(content string) []string {
+	lines := splitLines(content)
+	lines = lines[frontmatterEnd(lines):]
+	code := codeLines(lines)
+
+	var out []string
+	for i, line := range lines {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		if level := headingLevel(line); !code[i] && level > 0 {
+			text := strings.TrimSpace(line[level:])
+			out = append(out, text)
+			if underline := map[int]string{1: "=", 2: "-"}[level]; underline != "" {
+				out = append(out, strings.Repeat(underline, min(len([]rune(text)), PrintColumns)))
+			}
+			continue
+		}
+		out = append(out, wrapForPrint(line, PrintColumns)...)
+	}
+	for len(out) > 0 && strings.TrimSpace(out[0]) == "" {
+		out = out[1:]
+	}
+	return out
+}
+
+// wrapForPrint breaks line at spaces so no part is wider than width,
+// indenting continuations like the first line
+func wrapForPrint(line string, width int) []string {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return []string{line}
+	}
+	indent := len(runes) - len([]rune(strings.TrimLeft(line, " ")))
+	if indent > width/2 {
+		indent = 0
+	}
+	var wrapped []string
+	for len(runes) > width {
+		cut := width
+		for cut > indent && runes[cut] != ' ' {
+			cut--
+		}
+		if cut == indent {
+			cut = width
+		}
+		wrapped = append(wrapped, strings.TrimRight(string(runes[:cut]), " "))
+		runes = append([]rune(strings.Repeat(" ", indent)), []rune(strings.TrimLeft(string(runes[cut:]), " "))...)
+	}
+	return append(wrapped, string(runes))
+}
+
+// printPages lays body out over pages, each with a header and a footer
+func printPages(title string, date time.Time, name string, body []string) [][]string {
+	var chunks [][]string
+	for len(body) > PrintLinesPerPage {
+		chunks = append(chunks, body[:PrintLinesPerPage])
+		body = body[PrintLinesPerPage:]
+	}
+	chunks = append(chunks, body)
+
+	pages := make([][]string, len(chunks))
+	for i, chunk := range chunks {
+		page := []string{printSpread(title, date.Format("2006-01-02")), ""}
+		page = append(page, chunk...)
+		for len(page) < PrintLinesPerPage+2 {
+			page = append(page, "")
+		}
+		pages[i] = append(page, "", printSpread(name, fmt.Sprintf("Page %d of %d", i+1, len(chunks))))
+	}
+	return pages
+}
+
+// printSpread puts left and right at either end of a line, shortening left
+// if they do not both fit
+func printSpread(left, right string) string {
+	room := PrintColumns - len([]rune(right)) - 2
+	if leftRunes := []rune(left); len(leftRunes) > room {
+		left = string(leftRunes[:room-3]) + "..."
+	}
+	return left + strings.Repeat(" ", PrintColumns-len([]rune(left))-len([]rune(right))) + right
+}
+
+// writePrintPDF writes pages as a PDF using the built-in Courier font, so
+// no fonts need to be embedded
+func writePrintPDF(w io.Writer, pages [][]string) error {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	for i, page := range pages {
+		var text strings.Builder
+		fmt.Fprintf(&text, "BT /F1 %d Tf %d TL %d %d Td\n", printFontSize, printLeading, printMarginLeft, printPageHeight-printMarginTop)
+		for _, line := range page {
+			fmt.Fprintf(&text, "(%s) Tj T*\n", pdfString(line))
+		}
+		text.WriteString("ET")
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", printPageWidth, printPageHeight, 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", text.Len(), text.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// winAnsi maps the punctuation notes commonly contain onto the PDF's
+// WinAnsi encoding; other characters outside Latin-1 print as ?
+var winAnsi = map[rune]byte{
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '…': 0x85, '€': 0x80,
+}
+
+// pdfString escapes text for a PDF string literal in WinAnsi encoding
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case winAnsi[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", winAnsi[r])
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,165 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ProjectTemplatesDir holds the templates --project new scaffolds a project
+// from, one note per .md file, replacing DefaultProjectTemplates
+var ProjectTemplatesDir = filepath.Join(TemplatesDir, "project")
+
+// ProjectIndex is the note --project new generates to link a project's
+// notes together
+const ProjectIndex = "index.md"
+
+// projectTemplate is a note a new project starts with
+type projectTemplate struct {
+	Name string // filename in the project's folder
+	Body string
+}
+
+// DefaultProjectTemplates are the notes a new project starts with, in the
+// order its index lists them, unless .templates/project/ has templates of
+// its own
+var DefaultProjectTemplates = []projectTemplate{
+	{"overview.md", `# {{title}}: Overview
+
+Started: {{date}}
+
+## Goal
+
+## Scope
+
+## People
+
+## Milestones
+`},
+	{"decisions.md", `# {{title}}: Decisions
+
+| Date | Decision | Why |
+|------|----------|-----|
+`},
+	{"meetings.md", `# {{title}}: Meetings
+
+Link each meeting's note here, newest first.
+`},
+	{"retro.md", `# {{title}}: Retro
+
+## What went well
+
+## What didn't
+
+## What we'll change
+`},
+}
+
+// runProject scaffolds a project notebook:
+//
+//	note --project new "Apollo"
+//
+// creates the folder Apollo with a note for each project template and an
+// index.md linking to them.
+func runProject(config Config, args []string) error {
+	if len(args) < 2 || args[0] != "new" {
+		return usageErrorf("usage: note --project new <name>")
+	}
+	title := strings.Join(args[1:], " ")
+	dir, err := createProject(config, title, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created project %s in %s\n", title, dir)
+	return nil
+}
+
+// createProject writes the notes of a new project to a folder named after
+// it, returning the folder. Templates are expanded with the project's name
+// as {{title}} (and {{project}}).
+func createProject(config Config, title string, now time.Time) (string, error) {
+	folder := strings.ReplaceAll(title, " ", "_")
+	if folder == "" || strings.HasPrefix(folder, ".") || strings.ContainsAny(folder, `/\`) {
+		return "", usageErrorf("invalid project name '%s'", title)
+	}
+	dir := filepath.Join(config.NotesDir, folder)
+	if pathExists(dir) {
+		return "", fmt.Errorf("%s already exists", folder)
+	}
+
+	templates, err := projectTemplates(config)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating %s: %w", folder, err)
+	}
+
+	vars := templateVars(title, now)
+	vars["title"] = title
+	vars["project"] = title
+	index := []string{"# " + title, ""}
+	for _, template := range templates {
+		body := expandTemplate(template.Body, vars)
+		notePath := filepath.Join(dir, template.Name)
+		if err := createNoteFile(notePath, []byte(body)); err != nil {
+			return "", err
+		}
+		postSave(config, notePath)
+		label := strings.TrimPrefix(noteTitle(body), title+": ")
+		if label == "" {
+			label = strings.TrimSuffix(template.Name, ".md")
+		}
+		index = append(index, fmt.Sprintf("- [%s](%s)", label, template.Name))
+	}
+
+	indexPath := filepath.Join(dir, ProjectIndex)
+	if err := createNoteFile(indexPath, []byte(joinLines(index))); err != nil {
+		return "", err
+	}
+	postSave(config, indexPath)
+	return folder, nil
+}
+
+// projectTemplates returns the templates in ProjectTemplatesDir in filename
+// order, or DefaultProjectTemplates when there are none
+func projectTemplates(config Config) ([]projectTemplate, error) {
+	entries, err := os.ReadDir(filepath.Join(config.NotesDir, ProjectTemplatesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading %s: %w", ProjectTemplatesDir, err)
+	}
+	var templates []projectTemplate
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") || entry.Name() == ProjectIndex {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(config.NotesDir, ProjectTemplatesDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", entry.Name(), err)
+		}
+		templates = append(templates, projectTemplate{Name: entry.Name(), Body: string(content)})
+	}
+	if len(templates) == 0 {
+		return DefaultProjectTemplates, nil
+	}
+	return templates, nil
+}
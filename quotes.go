@@ -0,0 +1,212 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultQuotesNote is the collection --quote keeps unless quotes_note is
+// set
+const DefaultQuotesNote = "quotes.md"
+
+// runQuote adds a quote to the quotes note as a blockquote, its source and
+// tags on the last line so they stay part of the quote:
+//
+//	note --quote "text" [--source "Book p.42"] [--tag reading,stoic]
+//
+//	> text
+//	> — Book p.42 #reading #stoic
+func runQuote(config Config, flags *ParsedFlags, args []string) error {
+	var words, tags []string
+	source := ""
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--source" && i+1 < len(args):
+			i++
+			source = strings.TrimSpace(args[i])
+		case args[i] == "--tag" && i+1 < len(args):
+			i++
+			tags = append(tags, splitQuoteTags(args[i])...)
+		default:
+			words = append(words, args[i])
+		}
+	}
+	text := strings.TrimSpace(strings.Join(words, " "))
+	if text == "" {
+		return usageErrorf(`usage: note --quote "text" [--source "Book p.42"] [--tag <tags>]`)
+	}
+
+	return withNotesLock(config.NotesDir, func() error {
+		notePath := quotesNotePath(config)
+		content, err := os.ReadFile(notePath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if len(content) == 0 {
+			content = []byte("# Quotes\n")
+		}
+		wasLocked, err := checkNoteWritable(notePath, flags.Force)
+		if err != nil {
+			return err
+		}
+		defer restoreLock(notePath, wasLocked)
+
+		updated := insertText(string(content), "\n"+formatQuote(text, source, tags), appendOptions{})
+		if err := writeFileAtomic(notePath, []byte(updated), notePerm(notePath)); err != nil {
+			return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+		}
+		postSave(config, notePath)
+		fmt.Printf("Added quote to %s\n", filepath.Base(notePath))
+		return nil
+	})
+}
+
+// runQuotes prints the quotes collection, or one quote at random
+//
+//	note --quotes [--random] [--tag stoic]
+func runQuotes(config Config, args []string) error {
+	random, tag := false, ""
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--random":
+			random = true
+		case args[i] == "--tag" && i+1 < len(args):
+			i++
+			tag = strings.ToLower(strings.TrimPrefix(args[i], "#"))
+		default:
+			return usageErrorf("usage: note --quotes [--random] [--tag <tag>]")
+		}
+	}
+
+	content, err := os.ReadFile(quotesNotePath(config))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	quotes := parseQuotes(string(content))
+	if tag != "" {
+		quotes = quotesTagged(quotes, tag)
+	}
+	if len(quotes) == 0 {
+		fmt.Println("No quotes yet; add one with 'note --quote \"text\" --source \"...\"'")
+		return nil
+	}
+	if random {
+		quotes = []string{quotes[rand.IntN(len(quotes))]}
+	}
+	printQuotes(os.Stdout, quotes)
+	return nil
+}
+
+// quotesNotePath returns the path of the quotes note
+func quotesNotePath(config Config) string {
+	name := config.option("quotes_note")
+	if name == "" {
+		name = DefaultQuotesNote
+	}
+	if !strings.HasSuffix(name, ".md") {
+		name += ".md"
+	}
+	return filepath.Join(config.NotesDir, name)
+}
+
+// splitQuoteTags splits a comma- or space-separated tag list, dropping any
+// leading #
+func splitQuoteTags(list string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if tag = strings.TrimPrefix(tag, "#"); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// formatQuote returns text as a blockquote followed by a blank line, with
+// an attribution line when there is a source or tags
+func formatQuote(text, source string, tags []string) string {
+	var b strings.Builder
+	for _, line := range splitLines(strings.TrimRight(text, "\n")) {
+		b.WriteString(strings.TrimRight("> "+strings.TrimSpace(line), " ") + "\n")
+	}
+	var attribution []string
+	if source != "" {
+		attribution = append(attribution, "— "+source)
+	}
+	for _, tag := range tags {
+		attribution = append(attribution, "#"+tag)
+	}
+	if len(attribution) > 0 {
+		b.WriteString("> " + strings.Join(attribution, " ") + "\n")
+	}
+	return b.String()
+}
+
+// parseQuotes returns each blockquote in content, a run of lines starting
+// with ">", with the markers removed
+func parseQuotes(content string) []string {
+	var quotes []string
+	var current []string
+	flush := func() {
+		if len(current) > 0 {
+			quotes = append(quotes, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+	lines := splitLines(content)
+	code := codeLines(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, ">") || code[i] {
+			flush()
+			continue
+		}
+		current = append(current, strings.TrimPrefix(strings.TrimPrefix(trimmed, ">"), " "))
+	}
+	flush()
+	return quotes
+}
+
+// quotesTagged returns the quotes carrying #tag
+func quotesTagged(quotes []string, tag string) []string {
+	var tagged []string
+	for _, quote := range quotes {
+		for _, match := range inlineTagPattern.FindAllStringSubmatch(quote, -1) {
+			if strings.EqualFold(match[1], tag) {
+				tagged = append(tagged, quote)
+				break
+			}
+		}
+	}
+	return tagged
+}
+
+// printQuotes prints quotes separated by blank lines
+func printQuotes(w io.Writer, quotes []string) {
+	for i, quote := range quotes {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, quote)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultReadingNote is the reading list --read keeps unless reading_note
+// is set
+const DefaultReadingNote = "reading.md"
+
+// readingAdded matches the date an entry was added, at the end of its text
+// or before the date it was read
+var readingAdded = regexp.MustCompile(`\(added (\d{4}-\d{2}-\d{2})(?:, read \d{4}-\d{2}-\d{2})?\)$`)
+
+// runRead keeps a reading list as checklist items in a note, numbered in
+// the order they appear:
+//
+//	note --read add <url|title>   - [ ] <entry> (added 2025-06-01)
+//	note --read [list]            unread entries
+//	note --read next              the oldest unread entry
+//	note --read done <n>          - [x] <entry> (added 2025-06-01, read 2025-06-09)
+func runRead(config Config, flags *ParsedFlags, args []string) error {
+	notePath := readingNotePath(config)
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	text := strings.Join(args[min(1, len(args)):], " ")
+	now := time.Now()
+
+	switch action {
+	case "add":
+		if strings.TrimSpace(text) == "" {
+			return usageErrorf("usage: note --read add <url|title>")
+		}
+		return addReading(config, flags, notePath, unfurlText(config, strings.TrimSpace(text)), now)
+	case "list", "next":
+		if text != "" {
+			return usageErrorf("usage: note --read %s", action)
+		}
+		content, err := os.ReadFile(notePath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		items := checklistItems(string(content))
+		unread := unreadEntries(items)
+		if len(unread) == 0 {
+			fmt.Println("Nothing left to read")
+			return nil
+		}
+		if action == "next" {
+			unread = unread[:1]
+		}
+		printReadingEntries(os.Stdout, items, unread)
+		return nil
+	case "done":
+		n, err := strconv.Atoi(text)
+		if err != nil {
+			return usageErrorf("usage: note --read done <n>, with n from 'note --read'")
+		}
+		return markRead(config, flags, notePath, n, now)
+	}
+	return usageErrorf("unknown --read action '%s' (use add, list, next or done)", action)
+}
+
+// readingNotePath returns the path of the reading list note
+func readingNotePath(config Config) string {
+	name := config.option("reading_note")
+	if name == "" {
+		name = DefaultReadingNote
+	}
+	if !strings.HasSuffix(name, ".md") {
+		name += ".md"
+	}
+	return filepath.Join(config.NotesDir, name)
+}
+
+// addReading appends an unread entry to the reading list, creating it if
+// needed
+func addReading(config Config, flags *ParsedFlags, notePath, entry string, now time.Time) error {
+	return withNotesLock(config.NotesDir, func() error {
+		content, err := os.ReadFile(notePath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if len(content) == 0 {
+			content = []byte("# Reading List\n\n")
+		}
+		wasLocked, err := checkNoteWritable(notePath, flags.Force)
+		if err != nil {
+			return err
+		}
+		defer restoreLock(notePath, wasLocked)
+
+		line := fmt.Sprintf("- [ ] %s (added %s)\n", entry, now.Format("2006-01-02"))
+		updated := insertText(string(content), line, appendOptions{})
+		if err := writeFileAtomic(notePath, []byte(updated), notePerm(notePath)); err != nil {
+			return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+		}
+		postSave(config, notePath)
+		fmt.Printf("Added #%d to %s\n", len(checklistItems(updated)), filepath.Base(notePath))
+		return nil
+	})
+}
+
+// unreadEntries returns the indexes into items of the unread entries,
+// oldest added first; entries without an added date keep their place
+// after the dated ones
+func unreadEntries(items []checklistItem) []int {
+	var unread []int
+	for i, item := range items {
+		if !item.Done {
+			unread = append(unread, i)
+		}
+	}
+	added := func(i int) string {
+		if match := readingAdded.FindStringSubmatch(items[i].Text); match != nil {
+			return match[1]
+		}
+		return "9999-99-99"
+	}
+	sort.SliceStable(unread, func(a, b int) bool { return added(unread[a]) < added(unread[b]) })
+	return unread
+}
+
+// printReadingEntries prints the given entries with the numbers --read done
+// takes
+func printReadingEntries(w io.Writer, items []checklistItem, indexes []int) {
+	for _, i := range indexes {
+		fmt.Fprintf(w, "%3d. %s\n", i+1, items[i].Text)
+	}
+}
+
+// markRead ticks off entry n of the reading list and records the date it
+// was read
+func markRead(config Config, flags *ParsedFlags, notePath string, n int, now time.Time) error {
+	return withNotesLock(config.NotesDir, func() error {
+		content, err := os.ReadFile(notePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+		}
+		items := checklistItems(string(content))
+		if n < 1 || n > len(items) {
+			return fmt.Errorf("%s has no entry #%d", filepath.Base(notePath), n)
+		}
+		item := items[n-1]
+		if item.Done {
+			fmt.Printf("#%d is already read: %s\n", n, item.Text)
+			return nil
+		}
+
+		lines := splitLines(string(content))
+		line := strings.Replace(lines[item.Line], "[ ]", "[x]", 1)
+		read := now.Format("2006-01-02")
+		if readingAdded.MatchString(strings.TrimSpace(line)) {
+			line = strings.TrimRight(line, " )") + ", read " + read + ")"
+		} else {
+			line = strings.TrimRight(line, " ") + " (read " + read + ")"
+		}
+		lines[item.Line] = line
+
+		wasLocked, err := checkNoteWritable(notePath, flags.Force)
+		if err != nil {
+			return err
+		}
+		defer restoreLock(notePath, wasLocked)
+		if err := writeFileAtomic(notePath, []byte(joinLines(lines)), notePerm(notePath)); err != nil {
+			return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+		}
+		postSave(config, notePath)
+		fmt.Printf("Read #%d: %s\n", n, item.Text)
+		return nil
+	})
+}
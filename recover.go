@@ -0,0 +1,128 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// editorExitError describes an editor that crashed, was killed or exited
+// with an error, and points at --recover since the note may be incomplete
+func editorExitError(err error, notePath string) error {
+	name := strings.TrimSuffix(filepath.Base(notePath), ".md")
+	hint := fmt.Sprintf("swap and autosave files were left alone; run 'note --recover %s' to find them", name)
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return fmt.Errorf("editor was killed by %v; %s", status.Signal(), hint)
+		}
+		return fmt.Errorf("editor exited with status %d; %s", exitErr.ExitCode(), hint)
+	}
+	return fmt.Errorf("error opening editor: %w", err)
+}
+
+// runRecover lists editor swap, autosave and backup files, leftover temp
+// copies and conflict files for a note, so content lost in a crash can be
+// restored by hand
+func runRecover(config Config, noteName string) error {
+	if noteName == "" {
+		return usageErrorf("usage: note --recover <name>")
+	}
+	notePath := resolveNotePath(config, noteName)
+	homeDir, _ := os.UserHomeDir()
+
+	artifacts := recoveryArtifacts(notePath, os.TempDir(), homeDir)
+	if len(artifacts) == 0 {
+		fmt.Printf("No recovery files found for %s\n", filepath.Base(notePath))
+		return nil
+	}
+
+	fmt.Printf("Recovery files for %s:\n", filepath.Base(notePath))
+	for _, artifact := range artifacts {
+		modTime := ""
+		if info, err := os.Stat(artifact); err == nil {
+			modTime = info.ModTime().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("  %s  %s\n", modTime, artifact)
+	}
+	if hasSwapFile(artifacts) {
+		fmt.Printf("\nRestore a vim swap file with: vim -r %s\n", shellQuote(notePath))
+	}
+	return nil
+}
+
+// recoveryArtifacts returns existing files that may hold unsaved content for
+// notePath: vim/neovim swap files next to the note or in the default swap
+// directories, emacs autosave and backup files, tempedit copies left in
+// tempDir, and conflict files
+func recoveryArtifacts(notePath, tempDir, homeDir string) []string {
+	dir, base := filepath.Dir(notePath), filepath.Base(notePath)
+	name := strings.TrimSuffix(base, ".md")
+
+	// Vim names swap files .swp, .swo, ... down to .saa
+	patterns := []string{filepath.Join(dir, "."+base+".s??")}
+	if homeDir != "" {
+		// Swap directories name files after the full path with / as %
+		encoded := strings.ReplaceAll(notePath, string(filepath.Separator), "%")
+		for _, swapDir := range []string{
+			filepath.Join(homeDir, ".vim", "swap"),
+			filepath.Join(homeDir, ".local", "state", "nvim", "swap"),
+			filepath.Join(homeDir, ".local", "share", "nvim", "swap"),
+		} {
+			patterns = append(patterns, filepath.Join(swapDir, encoded+".s??"))
+		}
+	}
+	patterns = append(patterns,
+		filepath.Join(dir, "#"+base+"#"),
+		filepath.Join(dir, base+"~"),
+		filepath.Join(dir, name+".conflict-*.md"),
+		filepath.Join(tempDir, "note-edit-*", base),
+	)
+
+	seen := make(map[string]bool)
+	var artifacts []string
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() && !seen[match] {
+				seen[match] = true
+				artifacts = append(artifacts, match)
+			}
+		}
+	}
+	sort.Strings(artifacts)
+	return artifacts
+}
+
+func hasSwapFile(artifacts []string) bool {
+	for _, artifact := range artifacts {
+		ext := filepath.Ext(artifact)
+		if len(ext) == 4 && strings.HasPrefix(ext, ".s") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,295 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Inline links like "[text](https://example.com "Title")", not images
+	inlineLink = regexp.MustCompile(`(^|[^!])\[([^\]]*)\]\(([^()\s]+(?:\s+"[^"]*")?)\)`)
+	// Full and collapsed reference links like "[text][docs]" and "[docs][]"
+	referenceLink = regexp.MustCompile(`\[([^\]]*)\]\[([^\]]*)\]`)
+	// Shortcut reference links like "[docs]"
+	shortcutLink = regexp.MustCompile(`\[([^\]^][^\]]*)\]`)
+	// Footnote references "[^1]" and definitions "[^1]: text"
+	footnoteLabel = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+	footnoteDef   = regexp.MustCompile(`^\[\^([^\]\s]+)\]:`)
+	// Link definitions like "[docs]: https://example.com", with the label
+	// and destination captured
+	linkDefinition = regexp.MustCompile(`^\s{0,3}\[([^\]^][^\]]*)\]:\s+(.+)$`)
+)
+
+// Link styles --refs tidy can convert to
+const (
+	LinkStyleReference = "reference"
+	LinkStyleInline    = "inline"
+)
+
+// refsStats counts what tidyReferences changed
+type refsStats struct {
+	Renumbered, Converted, Removed int
+}
+
+// runRefs handles `note --refs tidy <name> [--reference | --inline]`
+func runRefs(config Config, flags *ParsedFlags, args []string) error {
+	if len(args) == 0 || args[0] != "tidy" {
+		return usageErrorf("usage: note --refs tidy <name> [--reference | --inline]")
+	}
+	style := ""
+	var nameParts []string
+	for _, arg := range args[1:] {
+		switch arg {
+		case "--reference":
+			style = LinkStyleReference
+		case "--inline":
+			style = LinkStyleInline
+		default:
+			nameParts = append(nameParts, arg)
+		}
+	}
+
+	notePath, err := existingNotePath(config, strings.Join(nameParts, " "))
+	if err != nil {
+		return err
+	}
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+	tidied, stats := tidyReferences(string(content), style)
+	if tidied == string(content) {
+		fmt.Printf("%s is already tidy\n", filepath.Base(notePath))
+		return nil
+	}
+	if err := writeFileAtomic(notePath, []byte(tidied), notePerm(notePath)); err != nil {
+		return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+	}
+	postSave(config, notePath)
+	fmt.Printf("Tidied %s: renumbered %d footnote(s), converted %d link(s), removed %d unused definition(s)\n",
+		filepath.Base(notePath), stats.Renumbered, stats.Converted, stats.Removed)
+	return nil
+}
+
+// tidyReferences renumbers footnotes in the order they are referenced,
+// converts links to style ("" leaves them as they are) and removes link
+// definitions nothing refers to. Code is left alone.
+func tidyReferences(content, style string) (string, refsStats) {
+	var stats refsStats
+	lines := splitLines(content)
+	code := codeLines(lines)
+
+	// Link definitions, keyed by lowercased label as markdown matches them
+	definitions := make(map[string]string)
+	var definitionOrder []string
+	for i, line := range lines {
+		if match := linkDefinition.FindStringSubmatch(line); match != nil && !code[i] {
+			label := strings.ToLower(match[1])
+			if _, ok := definitions[label]; !ok {
+				definitions[label] = strings.TrimSpace(match[2])
+				definitionOrder = append(definitionOrder, label)
+			}
+		}
+	}
+	isDefinition := func(i int) bool { return !code[i] && linkDefinition.MatchString(lines[i]) }
+
+	switch style {
+	case LinkStyleInline:
+		for i := range lines {
+			if code[i] || isDefinition(i) {
+				continue
+			}
+			lines[i] = outsideInlineCode(lines[i], func(text string) string {
+				return referenceLink.ReplaceAllStringFunc(text, func(link string) string {
+					match := referenceLink.FindStringSubmatch(link)
+					label := match[2]
+					if label == "" {
+						label = match[1]
+					}
+					destination, ok := definitions[strings.ToLower(label)]
+					if !ok {
+						return link
+					}
+					stats.Converted++
+					return "[" + match[1] + "](" + destination + ")"
+				})
+			})
+		}
+	case LinkStyleReference:
+		labelFor := make(map[string]string)
+		for _, label := range definitionOrder {
+			if _, ok := labelFor[definitions[label]]; !ok {
+				labelFor[definitions[label]] = label
+			}
+		}
+		next := 1
+		var added []string
+		for i := range lines {
+			if code[i] || isDefinition(i) {
+				continue
+			}
+			lines[i] = outsideInlineCode(lines[i], func(text string) string {
+				return inlineLink.ReplaceAllStringFunc(text, func(link string) string {
+					match := inlineLink.FindStringSubmatch(link)
+					label, ok := labelFor[match[3]]
+					if !ok {
+						// Number new definitions, skipping labels in use
+						for {
+							label = strconv.Itoa(next)
+							next++
+							if _, taken := definitions[label]; !taken {
+								break
+							}
+						}
+						definitions[label] = match[3]
+						labelFor[match[3]] = label
+						added = append(added, "["+label+"]: "+match[3])
+					}
+					stats.Converted++
+					return match[1] + "[" + match[2] + "][" + label + "]"
+				})
+			})
+		}
+		if len(added) > 0 {
+			for len(lines) > 0 && lines[len(lines)-1] == "" {
+				lines = lines[:len(lines)-1]
+			}
+			if len(definitionOrder) == 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, added...)
+			code = codeLines(lines)
+		}
+	}
+
+	// Drop definitions nothing refers to any more
+	used := make(map[string]bool)
+	for i, line := range lines {
+		if code[i] || isDefinition(i) {
+			continue
+		}
+		outsideInlineCode(line, func(text string) string {
+			for _, match := range referenceLink.FindAllStringSubmatch(text, -1) {
+				used[strings.ToLower(match[1])] = used[strings.ToLower(match[1])] || match[2] == ""
+				used[strings.ToLower(match[2])] = true
+			}
+			for _, match := range shortcutLink.FindAllStringSubmatch(text, -1) {
+				used[strings.ToLower(match[1])] = true
+			}
+			return text
+		})
+	}
+	var kept []string
+	for i, line := range lines {
+		if match := linkDefinition.FindStringSubmatch(line); match != nil && !code[i] && !used[strings.ToLower(match[1])] {
+			stats.Removed++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	lines = kept
+
+	stats.Renumbered = renumberFootnotes(lines, codeLines(lines))
+	return joinLines(lines), stats
+}
+
+// renumberFootnotes renames footnotes 1, 2, ... in the order they are first
+// referenced; definitions nothing refers to are numbered after them. It
+// returns how many footnotes changed label.
+func renumberFootnotes(lines []string, code []bool) int {
+	order := make(map[string]string)
+	var unreferenced []string
+	for i, line := range lines {
+		if code[i] {
+			continue
+		}
+		definition := footnoteDef.FindStringSubmatch(line)
+		for _, loc := range footnoteLabel.FindAllStringSubmatchIndex(line, -1) {
+			label := line[loc[2]:loc[3]]
+			if definition != nil && loc[0] == 0 {
+				continue
+			}
+			if _, ok := order[label]; !ok {
+				order[label] = strconv.Itoa(len(order) + 1)
+			}
+		}
+		if definition != nil {
+			unreferenced = append(unreferenced, definition[1])
+		}
+	}
+	for _, label := range unreferenced {
+		if _, ok := order[label]; !ok {
+			order[label] = strconv.Itoa(len(order) + 1)
+		}
+	}
+
+	changed := 0
+	for label, number := range order {
+		if label != number {
+			changed++
+		}
+	}
+	for i := range lines {
+		if code[i] {
+			continue
+		}
+		lines[i] = outsideInlineCode(lines[i], func(text string) string {
+			return footnoteLabel.ReplaceAllStringFunc(text, func(ref string) string {
+				return "[^" + order[footnoteLabel.FindStringSubmatch(ref)[1]] + "]"
+			})
+		})
+	}
+	return changed
+}
+
+// codeLines marks the lines that are inside fenced code blocks, fences
+// included
+func codeLines(lines []string) []bool {
+	code := make([]bool, len(lines))
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			code[i] = true
+			continue
+		}
+		code[i] = inFence
+	}
+	return code
+}
+
+// outsideInlineCode applies fn to the parts of line outside `code` spans
+func outsideInlineCode(line string, fn func(string) string) string {
+	parts := strings.Split(line, "`")
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = fn(parts[i])
+	}
+	return strings.Join(parts, "`")
+}
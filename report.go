@@ -0,0 +1,115 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// DefaultStaleThan is how long a note goes unmodified before --report
+// stale lists it, without --than
+const DefaultStaleThan = "6m"
+
+// staleNote is a note --report stale lists, with when it last changed
+type staleNote struct {
+	Name     string
+	Modified time.Time
+}
+
+// runReport prints a report for keeping a growing collection tidy: notes
+// with neither tags nor links, or notes left unmodified for a long time.
+//
+//	note --report untagged
+//	note --report stale [--than 6m]
+func runReport(config Config, flags *ParsedFlags, args []string) error {
+	usage := usageErrorf("usage: note --report untagged | note --report stale [--than 6m]")
+	if len(args) == 0 {
+		return usage
+	}
+	switch args[0] {
+	case "untagged":
+		if len(args) != 1 {
+			return usage
+		}
+		names := untaggedNotes(loadMetadata(config.NotesDir))
+		if len(names) == 0 {
+			fmt.Println("Every note has tags or links")
+			return nil
+		}
+		return printNoteList(config, flags, names, "")
+	case "stale":
+		spec := DefaultStaleThan
+		if len(args) == 3 && args[1] == "--than" {
+			spec = args[2]
+		} else if len(args) != 1 {
+			return usage
+		}
+		age, err := parseTTL(spec)
+		if err != nil {
+			return usageErrorf("%v", err)
+		}
+		stale := staleNotes(loadMetadata(config.NotesDir), time.Now().Add(-age))
+		if len(stale) == 0 {
+			fmt.Printf("No notes unmodified for %s\n", spec)
+			return nil
+		}
+		printStaleNotes(os.Stdout, stale)
+		return nil
+	}
+	return usage
+}
+
+// untaggedNotes returns the notes with no tags and no links to other notes,
+// sorted by name
+func untaggedNotes(metadata map[string]noteMetadata) []string {
+	var names []string
+	for _, name := range sortedKeys(metadata) {
+		if meta := metadata[name]; len(meta.Tags) == 0 && len(meta.Links) == 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// staleNotes returns the notes last modified before cutoff, oldest first
+func staleNotes(metadata map[string]noteMetadata, cutoff time.Time) []staleNote {
+	var stale []staleNote
+	for name, meta := range metadata {
+		if modified := time.Unix(0, meta.ModTime); modified.Before(cutoff) {
+			stale = append(stale, staleNote{Name: name, Modified: modified})
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		if !stale[i].Modified.Equal(stale[j].Modified) {
+			return stale[i].Modified.Before(stale[j].Modified)
+		}
+		return stale[i].Name < stale[j].Name
+	})
+	return stale
+}
+
+// printStaleNotes prints each stale note after the date it last changed
+func printStaleNotes(w io.Writer, stale []staleNote) {
+	for _, note := range stale {
+		fmt.Fprintf(w, "%s  %s\n", note.Modified.Format("2006-01-02"), note.Name)
+	}
+}
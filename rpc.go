@@ -0,0 +1,190 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// rpcRequest is one line of input in --rpc mode, e.g.
+// {"id": 1, "method": "search", "params": {"term": "todo"}}
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params rpcParams       `json:"params"`
+}
+
+// rpcParams holds the parameters used by any rpc method
+type rpcParams struct {
+	Name     string `json:"name"`
+	Pattern  string `json:"pattern"`
+	Term     string `json:"term"`
+	Body     string `json:"body"`
+	Under    string `json:"under"`
+	Archived bool   `json:"archived"`
+}
+
+// rpcResponse is one line of output in --rpc mode
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// rpcResolved is the result of the resolve and create methods
+type rpcResolved struct {
+	Path   string `json:"path"`
+	Name   string `json:"name"`
+	Exists bool   `json:"exists"`
+}
+
+// runRPC serves newline-delimited JSON requests on stdin until EOF so editor
+// plugins can keep one note process running instead of shelling out per
+// keystroke. Methods: list, search, resolve, create, append.
+func runRPC(config Config) error {
+	ctx, stop := interruptible()
+	defer stop()
+	return serveRPC(ctx, config, os.Stdin, os.Stdout)
+}
+
+func serveRPC(ctx context.Context, config Config, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var request rpcRequest
+		var response rpcResponse
+		if err := json.Unmarshal(line, &request); err != nil {
+			response.Error = fmt.Sprintf("invalid request: %v", err)
+		} else {
+			response.ID = request.ID
+			result, err := handleRPC(ctx, config, request, nil)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = result
+			}
+		}
+
+		if err := encoder.Encode(response); err != nil {
+			return fmt.Errorf("error writing response: %w", err)
+		}
+	}
+	return scanner.Err()
+}
+
+// handleRPC runs a single request and returns its result. Searches stop
+// when ctx is cancelled, e.g. because an HTTP client went away. When
+// visible is set, notes it rejects are left out of results and treated as
+// missing, so callers cannot learn they exist.
+func handleRPC(ctx context.Context, config Config, request rpcRequest, visible func(notePath string) bool) (interface{}, error) {
+	if visible == nil {
+		visible = func(string) bool { return true }
+	}
+	params := request.Params
+	switch request.Method {
+	case "list":
+		var names []string
+		for _, name := range pickerCandidates(config, params.Pattern, params.Archived) {
+			if visible(filepath.Join(config.NotesDir, name)) {
+				names = append(names, name)
+			}
+		}
+		return nonNil(names), nil
+	case "search":
+		if params.Term == "" {
+			return nil, fmt.Errorf("search requires a term")
+		}
+		results, err := findSearchResults(ctx, config, params.Term, params.Archived)
+		if err != nil {
+			return nil, err
+		}
+		shown := []SearchResult{}
+		for _, result := range results {
+			if visible(filepath.Join(config.NotesDir, result.Path)) {
+				shown = append(shown, result)
+			}
+		}
+		return shown, nil
+	case "resolve":
+		if params.Name == "" {
+			return nil, fmt.Errorf("resolve requires a name")
+		}
+		notePath := resolveNotePath(config, params.Name)
+		if !visible(notePath) {
+			return nil, fmt.Errorf("note '%s' not found", params.Name)
+		}
+		_, err := os.Stat(notePath)
+		return rpcResolved{Path: notePath, Name: filepath.Base(notePath), Exists: err == nil}, nil
+	case "create":
+		if params.Name == "" {
+			return nil, fmt.Errorf("create requires a name")
+		}
+		notePath := filepath.Join(config.NotesDir, datedNoteFilename(params.Name, time.Now()))
+		if !visible(notePath) {
+			return nil, fmt.Errorf("cannot create '%s' here", params.Name)
+		}
+		if err := createNoteFile(notePath, []byte(params.Body)); err != nil {
+			return nil, err
+		}
+		postSave(config, notePath)
+		return rpcResolved{Path: notePath, Name: filepath.Base(notePath), Exists: true}, nil
+	case "append":
+		if params.Name == "" || params.Body == "" {
+			return nil, fmt.Errorf("append requires a name and a body")
+		}
+		notePath := resolveNotePath(config, params.Name)
+		if !visible(notePath) {
+			return nil, fmt.Errorf("note '%s' not found", params.Name)
+		}
+		// Locked notes are never modified remotely; there is no --force here
+		if _, err := checkNoteWritable(notePath, false); err != nil {
+			return nil, err
+		}
+		logged, err := appendOrLog(config, notePath, params.Body, appendOptions{Under: params.Under})
+		if err != nil {
+			return nil, err
+		}
+		if !logged {
+			postSave(config, notePath)
+		}
+		return rpcResolved{Path: notePath, Name: filepath.Base(notePath), Exists: true}, nil
+	}
+	return nil, fmt.Errorf("unknown method '%s'", request.Method)
+}
+
+// nonNil makes empty lists encode as [] rather than null
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,181 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TemplatesDir holds note templates inside the notes directory; a recurring
+// note named 1:1-with-alex starts from .templates/1:1-with-alex.md
+const TemplatesDir = ".templates"
+
+// recurrence says on which days a scheduled note occurs
+type recurrence struct {
+	weekdays map[time.Weekday]bool // weekly on these days
+	monthDay int                   // monthly on this day (clamped to the month's length)
+	everyDay bool
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseRecurrence parses a [schedule] value: "daily", "weekdays", one or
+// more day names ("tuesday", "mon,thu") or "monthly <day>"
+func parseRecurrence(spec string) (recurrence, error) {
+	var rec recurrence
+	fields := strings.Fields(strings.ToLower(spec))
+	if len(fields) == 0 {
+		return rec, fmt.Errorf("empty schedule")
+	}
+
+	switch fields[0] {
+	case "daily":
+		rec.everyDay = true
+		return rec, nil
+	case "weekdays":
+		rec.weekdays = map[time.Weekday]bool{time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true}
+		return rec, nil
+	case "monthly":
+		if len(fields) != 2 {
+			return rec, fmt.Errorf("monthly schedules need a day, e.g. 'monthly 1'")
+		}
+		day, err := strconv.Atoi(fields[1])
+		if err != nil || day < 1 || day > 31 {
+			return rec, fmt.Errorf("invalid day of month '%s'", fields[1])
+		}
+		rec.monthDay = day
+		return rec, nil
+	}
+
+	rec.weekdays = make(map[time.Weekday]bool)
+	for _, name := range strings.FieldsFunc(strings.ToLower(spec), func(r rune) bool { return r == ',' || r == ' ' }) {
+		if len(name) < 3 {
+			return rec, fmt.Errorf("unknown day '%s'", name)
+		}
+		day, ok := weekdayNames[name[:3]]
+		if !ok {
+			return rec, fmt.Errorf("unknown day '%s'", name)
+		}
+		rec.weekdays[day] = true
+	}
+	return rec, nil
+}
+
+// next returns the first date on or after from when the note occurs
+func (r recurrence) next(from time.Time) time.Time {
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	for i := 0; i < 366; i++ {
+		if r.occursOn(day) {
+			return day
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+func (r recurrence) occursOn(day time.Time) bool {
+	switch {
+	case r.everyDay:
+		return true
+	case r.monthDay > 0:
+		lastDay := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		return day.Day() == min(r.monthDay, lastDay)
+	}
+	return r.weekdays[day.Weekday()]
+}
+
+// runScheduled creates the next occurrence of every note in the [schedule]
+// config section. It never prompts and skips notes that already exist, so
+// it is safe to run from cron as often as you like.
+func runScheduled(config Config) error {
+	names := make([]string, 0, len(config.Schedule))
+	for name := range config.Schedule {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		rec, err := parseRecurrence(config.Schedule[name])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping scheduled note %s: %v\n", name, err)
+			continue
+		}
+		created, notePath, err := createDatedNote(config, name, rec.next(now))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		if created {
+			fmt.Printf("Created %s\n", filepath.Base(notePath))
+		}
+	}
+	return nil
+}
+
+// runNext opens the upcoming occurrence of a scheduled note, creating it
+// from its template first if needed
+func runNext(config Config, flags *ParsedFlags, name string) error {
+	spec, ok := config.Schedule[name]
+	if !ok {
+		return fmt.Errorf("'%s' is not in the [schedule] section of ~/.note", name)
+	}
+	rec, err := parseRecurrence(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule for %s: %w", name, err)
+	}
+	_, notePath, err := createDatedNote(config, name, rec.next(time.Now()))
+	if err != nil {
+		return err
+	}
+
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+	return editNote(config, notePath)
+}
+
+// createDatedNote creates the note for name on date from its template in
+// TemplatesDir, if there is one, reporting whether it had to be created
+func createDatedNote(config Config, name string, date time.Time) (bool, string, error) {
+	notePath := filepath.Join(config.NotesDir, datedNoteFilename(name, date))
+	if _, err := os.Stat(notePath); err == nil {
+		return false, notePath, nil
+	}
+
+	var body []byte
+	if template, err := os.ReadFile(filepath.Join(config.NotesDir, TemplatesDir, name+".md")); err == nil {
+		body = []byte(expandTemplate(string(template), templateVars(name, date)))
+	}
+	if err := createNoteFile(notePath, body); err != nil {
+		return false, notePath, err
+	}
+	postSave(config, notePath)
+	return true, notePath, nil
+}
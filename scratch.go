@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ScratchDir holds the scratch note. Like other hidden directories it is
+// never listed or searched.
+const ScratchDir = ".scratch"
+
+// runScratch opens the one persistent scratch note, or with --flush moves
+// what it holds into a dated note and empties it:
+//
+//	note --scratch
+//	note --scratch --flush [name]    into scratch-YYYYMMDD.md or name-YYYYMMDD.md
+func runScratch(config Config, flags *ParsedFlags, args []string) error {
+	scratchPath := filepath.Join(config.NotesDir, ScratchDir, "scratch.md")
+	if len(args) > 0 && args[0] == "--flush" {
+		name := strings.Join(args[1:], " ")
+		if name == "" {
+			name = "scratch"
+		}
+		return flushScratch(config, flags, scratchPath, name, time.Now())
+	}
+	if len(args) > 0 {
+		return usageErrorf("usage: note --scratch [--flush [name]]")
+	}
+
+	if !pathExists(scratchPath) {
+		if err := os.MkdirAll(filepath.Dir(scratchPath), 0755); err != nil {
+			return fmt.Errorf("error creating %s: %w", ScratchDir, err)
+		}
+		if err := os.WriteFile(scratchPath, nil, 0644); err != nil {
+			return fmt.Errorf("error creating the scratch note: %w", err)
+		}
+	}
+	return editNote(config, scratchPath)
+}
+
+// flushScratch appends the scratch note to the dated note for name and now,
+// then empties it
+func flushScratch(config Config, flags *ParsedFlags, scratchPath, name string, now time.Time) error {
+	content, err := os.ReadFile(scratchPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading the scratch note: %w", err)
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		fmt.Println("The scratch note is empty")
+		return nil
+	}
+
+	notePath := filepath.Join(config.NotesDir, datedNoteFilename(name, now))
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+	text := strings.Trim(string(content), "\n")
+	if pathExists(notePath) {
+		// Keep the flushed text a paragraph of its own
+		text = "\n" + text
+	}
+	if err := appendToNote(config, notePath, text, appendOptions{}); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(scratchPath, nil, notePerm(scratchPath)); err != nil {
+		return fmt.Errorf("error emptying the scratch note: %w", err)
+	}
+	postSave(config, notePath)
+	fmt.Printf("Moved the scratch note into %s\n", filepath.Base(notePath))
+	return nil
+}
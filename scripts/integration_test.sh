@@ -286,6 +286,25 @@ run_test "Zsh completion includes archive flag detection" "grep -q 'include_arch
 # Cleanup completion test directory
 rm -rf "$TEST_DIR_COMPLETION"
 
+# Test 30: Note creation from stdin
+TEST_DIR_STDIN=$(mktemp -d)
+HOME=$TEST_DIR_STDIN
+echo -e "vim\n$TEST_DIR_STDIN/Notes\ny\nn\nn\n" | $NOTE_CMD > /dev/null 2>&1
+echo "build output" | $NOTE_CMD --new build-log > /dev/null 2>&1
+run_test "Piped stdin creates dated note" "grep -q 'build output' $TEST_DIR_STDIN/Notes/build-log-$TODAY.md" ""
+run_test "Piped stdin refuses to overwrite" "! echo 'again' | $NOTE_CMD --new build-log >/dev/null 2>&1" ""
+rm -rf "$TEST_DIR_STDIN"
+
+# Test 31: --notes-dir and --config-file run without touching $HOME
+TEST_DIR_OVERRIDE=$(mktemp -d)
+HOME="$TEST_DIR_OVERRIDE/empty-home"
+echo "sandbox" | $NOTE_CMD --notes-dir "$TEST_DIR_OVERRIDE/notes" --new sandboxed > /dev/null 2>&1
+run_test "--notes-dir works without a config" "grep -q sandbox $TEST_DIR_OVERRIDE/notes/sandboxed-$TODAY.md" ""
+run_test "--notes-dir leaves HOME alone" "test ! -e $TEST_DIR_OVERRIDE/empty-home/.note" ""
+printf 'editor=vim\nnotesdir=%s\n' "$TEST_DIR_OVERRIDE/notes" > "$TEST_DIR_OVERRIDE/alt.conf"
+run_test "--config-file selects the config" "$NOTE_CMD --config-file $TEST_DIR_OVERRIDE/alt.conf -l | grep -q sandboxed" ""
+rm -rf "$TEST_DIR_OVERRIDE"
+
 # Cleanup additional test directories
 rm -rf "$TEST_DIR_NEW" "$TEST_DIR_LOWER"
 
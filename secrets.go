@@ -0,0 +1,223 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// EncryptedPrefix marks config values encrypted with --encrypt-config, e.g.
+// smtp_password=enc:v1:<base64>. They are decrypted when read, so the rest
+// of note never sees the difference.
+const EncryptedPrefix = "enc:v1:"
+
+// MasterKeyEnv overrides the keyring, for machines without one
+const MasterKeyEnv = "NOTE_MASTER_KEY"
+
+// masterKey caches the key so the keyring is asked at most once per run
+var masterKey []byte
+
+// runEncryptConfig stores a config setting encrypted with the master key,
+// reading the value from the arguments or stdin so it stays out of shell
+// history:
+//
+//	note --encrypt-config smtp_password
+func runEncryptConfig(config Config, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: note --encrypt-config <key> [value]")
+	}
+	key := args[0]
+	if key == "editor" || key == "notesdir" {
+		return fmt.Errorf("%s cannot be encrypted", key)
+	}
+
+	value := strings.Join(args[1:], " ")
+	if value == "" {
+		if isInputFromTerminal() {
+			fmt.Printf("Value for %s: ", key)
+		}
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("error reading value: %w", err)
+		}
+		value = strings.TrimRight(line, "\r\n")
+	}
+
+	keyBytes, err := loadMasterKey(true)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encryptConfigValue(keyBytes, value)
+	if err != nil {
+		return err
+	}
+
+	if config.Options == nil {
+		config.Options = make(map[string]string)
+	}
+	config.Options[key] = encrypted
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+	fmt.Printf("Stored %s encrypted in ~/.note\n", key)
+	return nil
+}
+
+// decryptOption returns the plain text of an option value, decrypting it if
+// it was stored with --encrypt-config
+func decryptOption(key, value string) string {
+	if !strings.HasPrefix(value, EncryptedPrefix) {
+		return value
+	}
+	keyBytes, err := loadMasterKey(false)
+	if err == nil {
+		var plain string
+		if plain, err = decryptConfigValue(keyBytes, value); err == nil {
+			return plain
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Warning: could not decrypt %s: %v\n", key, err)
+	return ""
+}
+
+// encryptConfigValue seals value with AES-256-GCM under key
+func encryptConfigValue(key []byte, value string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("error generating nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptConfigValue reverses encryptConfigValue
+func decryptConfigValue(key []byte, value string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted value")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("wrong master key or corrupted value")
+	}
+	return string(plain), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// loadMasterKey returns the 256-bit master key from $NOTE_MASTER_KEY or the
+// OS keyring (secret-tool on Linux, the login keychain on macOS), creating
+// and storing a new key when create is set and none exists yet
+func loadMasterKey(create bool) ([]byte, error) {
+	if masterKey != nil {
+		return masterKey, nil
+	}
+
+	encoded := os.Getenv(MasterKeyEnv)
+	if encoded == "" {
+		var err error
+		encoded, err = keyringLookup()
+		if err != nil {
+			if !create {
+				return nil, fmt.Errorf("no master key in the keyring (set $%s or run note --encrypt-config)", MasterKeyEnv)
+			}
+			key := make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, fmt.Errorf("error generating master key: %w", err)
+			}
+			encoded = base64.StdEncoding.EncodeToString(key)
+			if err := keyringStore(encoded); err != nil {
+				return nil, fmt.Errorf("error storing master key in the keyring: %w (set $%s instead)", err, MasterKeyEnv)
+			}
+		}
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("master key must be 32 bytes of base64")
+	}
+	masterKey = key
+	return key, nil
+}
+
+func keyringLookup() (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "find-generic-password", "-s", "note", "-a", "master", "-w")
+	} else {
+		cmd = exec.Command("secret-tool", "lookup", "service", "note", "key", "master")
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	if len(strings.TrimSpace(string(output))) == 0 {
+		return "", fmt.Errorf("empty master key")
+	}
+	return string(output), nil
+}
+
+func keyringStore(encoded string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		// security -i reads the command from stdin, keeping the key out of
+		// argv where ps would show it; base64 needs no quoting
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s note -a master -w %s\n", encoded))
+	} else {
+		// secret-tool reads the secret from stdin
+		cmd = exec.Command("secret-tool", "store", "--label=note master key", "service", "note", "key", "master")
+		cmd.Stdin = strings.NewReader(encoded)
+	}
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	// security -i succeeds even when the command it read fails
+	if stored, err := keyringLookup(); err != nil || strings.TrimSpace(stored) != encoded {
+		return fmt.Errorf("the keyring did not keep the key")
+	}
+	return nil
+}
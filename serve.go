@@ -0,0 +1,279 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultServeAddr is where --serve listens unless given an address or the
+// serve config setting
+const DefaultServeAddr = "127.0.0.1:7531"
+
+// rpcMethodScopes is the token scope each --rpc method needs over HTTP
+var rpcMethodScopes = map[string]string{
+	"list":    ScopeRead,
+	"search":  ScopeRead,
+	"resolve": ScopeRead,
+	"create":  ScopeAppend,
+	"append":  ScopeAppend,
+}
+
+// DefaultServeRate is how many requests per minute each token may make
+// unless serve_rate is set
+const DefaultServeRate = 60
+
+// AuditLogFile records every --serve request in the home directory
+const AuditLogFile = ".note_audit.log"
+
+// ServeShutdownTimeout is how long --serve waits for requests in flight
+// when it is stopped
+const ServeShutdownTimeout = 10 * time.Second
+
+// runServe serves the --rpc methods over HTTP: each POST to /rpc carries
+// one request object and gets one response object back. Every request needs
+// an "Authorization: Bearer <token>" header with a token from --serve-token,
+// is rate limited per token and is recorded in ~/.note_audit.log. GET
+// /share/ shows a single note to holders of a --share-link URL, and GET
+// /notes/ shows public notes to anyone. What each caller sees is limited by
+// the [visibility] section. Ctrl-C stops accepting requests and lets the
+// ones in flight finish.
+func runServe(config Config, args []string) error {
+	addr := DefaultServeAddr
+	if configured := config.option("serve"); configured != "" {
+		addr = configured
+	}
+	if len(args) > 0 {
+		addr = args[0]
+	}
+
+	tokensPath, err := tokensFilePath()
+	if err != nil {
+		return err
+	}
+	if err := checkVisibility(config); err != nil {
+		return err
+	}
+	if tokens, err := loadTokens(tokensPath); err != nil {
+		return err
+	} else if len(tokens) == 0 && !hasPublicNotes(config) {
+		return fmt.Errorf("no API tokens; create one with 'note --serve-token create'")
+	}
+
+	rate := DefaultServeRate
+	if value, err := strconv.Atoi(config.option("serve_rate")); err == nil && value > 0 {
+		rate = value
+	}
+
+	auditLog, err := os.OpenFile(filepath.Join(filepath.Dir(tokensPath), AuditLogFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening audit log: %w", err)
+	}
+	defer auditLog.Close()
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           newServeHandler(config, tokensPath, newRateLimiter(rate, time.Minute), auditLog),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := interruptible()
+	defer stop()
+	errs := make(chan error, 1)
+	go func() { errs <- server.ListenAndServe() }()
+
+	fmt.Printf("Serving notes on http://%s/rpc (%d requests/minute per token)\n", addr, rate)
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	fmt.Println("\nShutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ServeShutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// newServeHandler returns the HTTP handler for --serve. Tokens are re-read
+// on every request so revoking one takes effect without a restart.
+func newServeHandler(config Config, tokensPath string, limiter *rateLimiter, audit io.Writer) http.Handler {
+	var auditMu sync.Mutex
+	record := func(r *http.Request, tokenID, method, note string, status int) {
+		auditMu.Lock()
+		defer auditMu.Unlock()
+		fmt.Fprintf(audit, "%s\t%s\t%s\t%s\t%s\t%d\n", time.Now().Format(time.RFC3339), tokenID, r.RemoteAddr, method, note, status)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rpc", func(w http.ResponseWriter, r *http.Request) {
+		tokenID, method, note := "-", "-", "-"
+		status, response := func() (int, rpcResponse) {
+			if r.Method != http.MethodPost {
+				return http.StatusMethodNotAllowed, rpcResponse{Error: "use POST"}
+			}
+
+			tokens, err := loadTokens(tokensPath)
+			if err != nil {
+				return http.StatusInternalServerError, rpcResponse{Error: "error reading tokens"}
+			}
+			// Without a token, callers may only read public notes, and only
+			// when there are any; they share a limit per client address
+			authenticated := r.Header.Get("Authorization") != ""
+			token, limitKey := apiToken{ID: "-", Scope: ScopeRead}, "anonymous "+clientAddr(r)
+			if authenticated || !hasPublicNotes(config) {
+				secret, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+				var ok bool
+				if token, ok = authenticate(tokens, strings.TrimSpace(secret)); !ok {
+					return http.StatusUnauthorized, rpcResponse{Error: "invalid or missing token"}
+				}
+				tokenID, limitKey = token.ID, token.ID
+			}
+
+			if wait, ok := limiter.allow(limitKey, time.Now()); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+				return http.StatusTooManyRequests, rpcResponse{Error: "rate limit exceeded"}
+			}
+
+			var request rpcRequest
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16<<20)).Decode(&request); err != nil {
+				return http.StatusBadRequest, rpcResponse{Error: fmt.Sprintf("invalid request: %v", err)}
+			}
+			method = request.Method
+			if request.Params.Name != "" {
+				note = request.Params.Name
+			}
+			response := rpcResponse{ID: request.ID}
+
+			scope, known := rpcMethodScopes[request.Method]
+			if known && !token.allows(scope) && !authenticated {
+				response.Error = fmt.Sprintf("%s needs a token", request.Method)
+				return http.StatusUnauthorized, response
+			}
+			if known && !token.allows(scope) {
+				response.Error = fmt.Sprintf("token %s has %s scope; %s needs %s", token.ID, token.Scope, request.Method, scope)
+				return http.StatusForbidden, response
+			}
+
+			result, err := handleRPC(r.Context(), config, request, visibleTo(config, authenticated))
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = result
+				if resolved, ok := result.(rpcResolved); ok {
+					note = resolved.Name
+				}
+			}
+			return http.StatusOK, response
+		}()
+
+		record(r, tokenID, method, note, status)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+	})
+
+	// Share links carry their own signature instead of a token, so they
+	// are rate limited per client address
+	keyPath := filepath.Join(filepath.Dir(tokensPath), ShareKeyFile)
+	mux.HandleFunc("/share/", func(w http.ResponseWriter, r *http.Request) {
+		if wait, ok := limiter.allow("share "+clientAddr(r), time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			record(r, "-", "share", "-", http.StatusTooManyRequests)
+			return
+		}
+		note, status := serveShare(w, r, config, keyPath, time.Now())
+		record(r, "-", "share", note, status)
+	})
+
+	// Public notes can be read by anyone at /notes/<path>
+	mux.HandleFunc("/notes/", func(w http.ResponseWriter, r *http.Request) {
+		note := strings.TrimPrefix(r.URL.Path, "/notes/")
+		if r.Method != http.MethodGet {
+			http.Error(w, "use GET", http.StatusMethodNotAllowed)
+			record(r, "-", "view", note, http.StatusMethodNotAllowed)
+			return
+		}
+		if wait, ok := limiter.allow("anonymous "+clientAddr(r), time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			record(r, "-", "view", note, http.StatusTooManyRequests)
+			return
+		}
+		record(r, "-", "view", note, writeNotePage(w, config, note, visibleTo(config, false)))
+	})
+	return mux
+}
+
+// clientAddr returns the address a request came from, without the port
+func clientAddr(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// rateLimiter allows each key a number of requests per period, refilling
+// continuously (a token bucket per key)
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   float64
+	period  time.Duration
+	buckets map[string]*rateBucket
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(limit int, period time.Duration) *rateLimiter {
+	return &rateLimiter{limit: float64(limit), period: period, buckets: make(map[string]*rateBucket)}
+}
+
+// allow takes one request from key's bucket, or reports how long to wait
+// until the next one is available
+func (l *rateLimiter) allow(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &rateBucket{tokens: l.limit, last: now}
+		l.buckets[key] = bucket
+	}
+	perSecond := l.limit / l.period.Seconds()
+	bucket.tokens = math.Min(l.limit, bucket.tokens+now.Sub(bucket.last).Seconds()*perSecond)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return time.Duration((1 - bucket.tokens) / perSecond * float64(time.Second)), false
+	}
+	bucket.tokens--
+	return 0, true
+}
@@ -0,0 +1,262 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShareKeyFile holds the secret share links are signed with, next to
+// ~/.note. Deleting it (or --share-link --revoke-all) invalidates every
+// link handed out so far.
+const ShareKeyFile = ".note_share_key"
+
+// DefaultShareTTL is how long a share link works unless --ttl says otherwise
+const DefaultShareTTL = "1h"
+
+// runShareLink prints a signed, expiring URL at which --serve shows a note
+// to anyone who has it, no token needed:
+//
+//	note --share-link <name> [--ttl 1h] [--qr]
+//	note --share-link --revoke-all
+//
+// share_url sets the address the link uses (e.g. http://laptop.local:7531)
+// for when --serve is reached through something other than its listen
+// address.
+func runShareLink(config Config, args []string) error {
+	keyPath, err := shareKeyPath()
+	if err != nil {
+		return err
+	}
+	ttl := DefaultShareTTL
+	var qr bool
+	var nameArgs []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--revoke-all":
+			if err := os.Remove(keyPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("error removing share key: %w", err)
+			}
+			fmt.Println("Revoked every share link")
+			return nil
+		case "--qr":
+			qr = true
+		case "--ttl":
+			if i+1 >= len(args) {
+				return usageErrorf("--ttl requires a duration such as 1h or 2d")
+			}
+			i++
+			ttl = args[i]
+		default:
+			nameArgs = append(nameArgs, args[i])
+		}
+	}
+	if len(nameArgs) == 0 {
+		return usageErrorf("usage: note --share-link <name> [--ttl 1h] [--qr]")
+	}
+	lifetime, err := parseTTL(ttl)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	notePath, err := existingNotePath(config, strings.Join(nameArgs, " "))
+	if err != nil {
+		return err
+	}
+	if noteVisibility(config, notePath) == VisibilityPrivate {
+		return fmt.Errorf("%s is private in [visibility]; --serve never shows it", filepath.Base(notePath))
+	}
+	note, _ := filepath.Rel(config.NotesDir, notePath)
+	key, err := loadShareKey(keyPath, true)
+	if err != nil {
+		return err
+	}
+
+	addr := DefaultServeAddr
+	if configured := config.option("serve"); configured != "" {
+		addr = configured
+	}
+	base := config.option("share_url")
+	if base == "" {
+		base = "http://" + addr
+	}
+	if conn, err := net.DialTimeout("tcp", addr, time.Second); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: --serve is not running on %s; the link works once it is\n", addr)
+	} else {
+		conn.Close()
+	}
+
+	expires := time.Now().Add(lifetime)
+	link := shareURL(base, key, note, expires)
+	fmt.Println(link)
+	fmt.Fprintf(os.Stderr, "Valid until %s\n", expires.Format("2006-01-02 15:04"))
+	if qr {
+		return printQRCode(link)
+	}
+	return nil
+}
+
+// shareKeyPath keeps the key next to ~/.note, like the API tokens
+func shareKeyPath() (string, error) {
+	configPath, err := configFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), ShareKeyFile), nil
+}
+
+// loadShareKey reads the signing key, generating one first when create is
+// set and there is none
+func loadShareKey(keyPath string, create bool) ([]byte, error) {
+	data, err := os.ReadFile(keyPath)
+	if err == nil {
+		return hex.DecodeString(strings.TrimSpace(string(data)))
+	}
+	if !os.IsNotExist(err) || !create {
+		return nil, fmt.Errorf("no share key: %w", err)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("error generating share key: %w", err)
+	}
+	if err := writeFileAtomic(keyPath, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("error saving share key: %w", err)
+	}
+	return key, nil
+}
+
+// shareSignature signs a note path (relative to the notes directory, with
+// forward slashes) together with when the link expires
+func shareSignature(key []byte, note string, expires int64) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s\n%d", note, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// shareURL returns the link for note under base
+func shareURL(base string, key []byte, note string, expires time.Time) string {
+	note = filepath.ToSlash(note)
+	query := url.Values{
+		"expires": {strconv.FormatInt(expires.Unix(), 10)},
+		"sig":     {shareSignature(key, note, expires.Unix())},
+	}
+	return strings.TrimRight(base, "/") + "/share/" + (&url.URL{Path: note}).EscapedPath() + "?" + query.Encode()
+}
+
+// serveShare answers a /share/ request, returning the note it was for and
+// the status sent. Links are checked against the current key on every
+// request, so revoking takes effect immediately.
+func serveShare(w http.ResponseWriter, r *http.Request, config Config, keyPath string, now time.Time) (string, int) {
+	note := strings.TrimPrefix(r.URL.Path, "/share/")
+	fail := func(status int, message string) (string, int) {
+		http.Error(w, message, status)
+		return note, status
+	}
+	if r.Method != http.MethodGet {
+		return fail(http.StatusMethodNotAllowed, "use GET")
+	}
+
+	key, err := loadShareKey(keyPath, false)
+	if err != nil {
+		return fail(http.StatusForbidden, "invalid or expired link")
+	}
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	valid := hmac.Equal([]byte(r.URL.Query().Get("sig")), []byte(shareSignature(key, note, expires)))
+	if err != nil || !valid || now.Unix() > expires {
+		return fail(http.StatusForbidden, "invalid or expired link")
+	}
+
+	// A signature cannot make a private note visible
+	return note, writeNotePage(w, config, note, visibleTo(config, true))
+}
+
+// writeNotePage answers with note, a slash-separated path relative to the
+// notes directory, as a minimal HTML page, returning the status sent. Notes
+// outside the notes directory or rejected by visible are reported missing,
+// and so is anything that isn't a note: other files, whatever sits in a
+// hidden folder such as .git or .Trash, and encrypted notes, which are
+// never decrypted for a web request.
+func writeNotePage(w http.ResponseWriter, config Config, note string, visible func(notePath string) bool) int {
+	relPath := filepath.Clean(filepath.FromSlash(note))
+	notePath := filepath.Join(config.NotesDir, relPath)
+	if filepath.IsAbs(relPath) || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) ||
+		hiddenPath(relPath) || !isNoteFile(notePath) || !visible(notePath) {
+		http.Error(w, "note not found", http.StatusNotFound)
+		return http.StatusNotFound
+	}
+	content, err := readNoteWithCaptures(config, notePath)
+	if err != nil {
+		http.Error(w, "note not found", http.StatusNotFound)
+		return http.StatusNotFound
+	}
+
+	title := noteTitle(string(content))
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(relPath), ".md")
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%s</title>
+<style>body{margin:1.5em auto;max-width:46em;padding:0 1em}pre{white-space:pre-wrap;font:1em/1.5 ui-monospace,monospace}</style>
+</head><body><pre>%s</pre></body></html>
+`, html.EscapeString(title), html.EscapeString(expandEmoji(config, string(content))))
+	return http.StatusOK
+}
+
+// hiddenPath reports whether any component of relPath starts with a dot,
+// the rule notes.Walk uses to skip folders that hold no notes
+func hiddenPath(relPath string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(relPath), "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// printQRCode draws link as a QR code in the terminal with qrencode
+func printQRCode(link string) error {
+	if _, err := exec.LookPath("qrencode"); err != nil {
+		return fmt.Errorf("qrencode not found in PATH; install it to show QR codes")
+	}
+	cmd := exec.Command("qrencode", "-t", "ANSIUTF8", link)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("qrencode failed: %w", err)
+	}
+	return nil
+}
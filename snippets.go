@@ -0,0 +1,117 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SnippetsDir holds reusable text blocks inside the notes directory
+const SnippetsDir = ".snippets"
+
+// runSnippet manages the snippet library:
+//
+//	note --snippet add <name>                   (body from stdin, or the editor)
+//	note --snippet insert <name> --into <note> [key=value ...]
+//	note --snippet list
+//
+// Inserted snippets expand {{date}}, {{note}} and other template variables,
+// plus any key=value pairs given, and honor --prepend and --under.
+func runSnippet(config Config, flags *ParsedFlags, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: note --snippet add|insert|list")
+	}
+	dir := filepath.Join(config.NotesDir, SnippetsDir)
+
+	switch args[0] {
+	case "list", "ls":
+		for _, name := range findMatchingNotes(dir, strings.Join(args[1:], " "), false) {
+			fmt.Println(strings.TrimSuffix(name, ".md"))
+		}
+		return nil
+	case "add":
+		if len(args) < 2 {
+			return usageErrorf("usage: note --snippet add <name>")
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("error creating %s: %w", SnippetsDir, err)
+		}
+		snippetPath := snippetPath(dir, args[1])
+		if isInputFromTerminal() {
+			return editNote(config, snippetPath)
+		}
+		body, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("error reading stdin: %w", err)
+		}
+		if err := writeFileAtomic(snippetPath, body, 0644); err != nil {
+			return fmt.Errorf("error saving snippet: %w", err)
+		}
+		fmt.Printf("Saved snippet %s\n", args[1])
+		return nil
+	case "insert":
+		return insertSnippet(config, flags, dir, args[1:])
+	}
+	return fmt.Errorf("unknown snippet command '%s' (use add, insert or list)", args[0])
+}
+
+func insertSnippet(config Config, flags *ParsedFlags, dir string, args []string) error {
+	into, rest := intoArg(args)
+	if into == "" {
+		return usageErrorf("usage: note --snippet insert <name> --into <note>")
+	}
+
+	notePath := resolveNotePath(config, into)
+	vars := templateVars(strings.TrimSuffix(filepath.Base(notePath), ".md"), time.Now())
+	rest = parseTemplateVars(rest, vars)
+	if len(rest) == 0 {
+		return usageErrorf("usage: note --snippet insert <name> --into <note>")
+	}
+	name := strings.Join(rest, " ")
+
+	body, err := os.ReadFile(snippetPath(dir, name))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no snippet named '%s' (see note --snippet list)", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading snippet: %w", err)
+	}
+
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	opts := appendOptions{Prepend: flags.Prepend, Under: flags.Under}
+	if err := appendToNote(config, notePath, expandTemplate(string(body), vars), opts); err != nil {
+		return err
+	}
+	postSave(config, notePath)
+	fmt.Printf("Inserted %s into %s\n", name, filepath.Base(notePath))
+	return nil
+}
+
+func snippetPath(dir, name string) string {
+	return filepath.Join(dir, strings.TrimSuffix(name, ".md")+".md")
+}
@@ -0,0 +1,98 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sortKeys are the orders --sort accepts. Like ls, name sorts A to Z and
+// the others newest or largest first; --reverse flips any of them.
+var sortKeys = []string{"name", "mtime", "created", "size"}
+
+// sortNotes orders listed notes, named relative to the notes directory, by
+// key; notes that tie are ordered by name
+func sortNotes(config Config, names []string, key string, reverse bool) ([]string, error) {
+	if key == "" {
+		key = "name"
+	}
+	type sortable struct {
+		name     string
+		modified time.Time
+		created  time.Time
+		size     int64
+	}
+	notes := make([]sortable, len(names))
+	for i, name := range names {
+		notes[i].name = name
+		if info, err := os.Stat(filepath.Join(config.NotesDir, name)); err == nil {
+			notes[i].modified = info.ModTime()
+			notes[i].size = info.Size()
+		}
+		notes[i].created = createdDate(name, notes[i].modified)
+	}
+
+	var less func(a, b sortable) bool
+	switch key {
+	case "name":
+		less = func(a, b sortable) bool { return false }
+	case "mtime":
+		less = func(a, b sortable) bool { return a.modified.After(b.modified) }
+	case "created":
+		less = func(a, b sortable) bool { return a.created.After(b.created) }
+	case "size":
+		less = func(a, b sortable) bool { return a.size > b.size }
+	default:
+		return nil, usageErrorf("unknown --sort '%s' (use %s)", key, strings.Join(sortKeys, ", "))
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		a, b := notes[i], notes[j]
+		if reverse {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.name < b.name
+	})
+
+	sorted := make([]string, len(notes))
+	for i, note := range notes {
+		sorted[i] = note.name
+	}
+	return sorted, nil
+}
+
+// createdDate is the day a note was created according to its -YYYYMMDD
+// date stamp; notes without one fall back to modified, as the filesystem
+// does not reliably record creation times
+func createdDate(name string, modified time.Time) time.Time {
+	if match := dateStamp.FindStringSubmatch(filepath.Base(name)); match != nil {
+		if date, err := time.ParseInLocation("20060102", match[1], time.Local); err == nil {
+			return date
+		}
+	}
+	return modified
+}
@@ -0,0 +1,165 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// DictionaryFile holds words the spell checker should accept, one per line
+const DictionaryFile = ".dictionary"
+
+// runSpell spell-checks the notes matching nameOrPattern and reports
+// misspelled words with their line numbers
+func runSpell(config Config, nameOrPattern string) error {
+	checker, err := findSpellChecker(config)
+	if err != nil {
+		return err
+	}
+
+	notes := selectNotes(config, nameOrPattern)
+	if len(notes) == 0 {
+		fmt.Printf("No notes found matching '%s'\n", nameOrPattern)
+		return nil
+	}
+
+	dictionary := loadDictionary(config.NotesDir)
+	for _, notePath := range notes {
+		content, err := os.ReadFile(notePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", filepath.Base(notePath), err)
+			continue
+		}
+
+		lines := spellCheckableLines(string(content))
+		misspelled, err := runSpellChecker(checker, strings.Join(lines, "\n"))
+		if err != nil {
+			return err
+		}
+
+		report := misspellingsByLine(lines, misspelled, dictionary)
+		if len(report) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", filepath.Base(notePath))
+		for _, entry := range report {
+			fmt.Printf("  %d: %s\n", entry.Line, strings.Join(entry.Words, ", "))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// findSpellChecker returns the command used to list misspelled words from
+// stdin, preferring the spellcheck config option over aspell and hunspell
+func findSpellChecker(config Config) ([]string, error) {
+	if custom := strings.Fields(config.option("spellcheck")); len(custom) > 0 {
+		return custom, nil
+	}
+	if _, err := exec.LookPath("aspell"); err == nil {
+		return []string{"aspell", "list"}, nil
+	}
+	if _, err := exec.LookPath("hunspell"); err == nil {
+		return []string{"hunspell", "-l"}, nil
+	}
+	return nil, fmt.Errorf("no spell checker found; install aspell or hunspell, or set spellcheck= in ~/.note")
+}
+
+// runSpellChecker feeds text to the checker and returns the set of words it
+// reports as misspelled
+func runSpellChecker(checker []string, text string) (map[string]bool, error) {
+	cmd := exec.Command(checker[0], checker[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running %s: %w", checker[0], err)
+	}
+
+	misspelled := make(map[string]bool)
+	for _, word := range strings.Fields(string(output)) {
+		misspelled[word] = true
+	}
+	return misspelled, nil
+}
+
+// spellCheckableLines returns the note's lines with fenced code blocks
+// blanked out, keeping line numbers intact
+func spellCheckableLines(content string) []string {
+	lines := splitLines(content)
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			lines[i] = ""
+			continue
+		}
+		if inFence {
+			lines[i] = ""
+		}
+	}
+	return lines
+}
+
+// loadDictionary reads the notes directory's custom dictionary
+func loadDictionary(notesDir string) map[string]bool {
+	dictionary := make(map[string]bool)
+	content, err := os.ReadFile(filepath.Join(notesDir, DictionaryFile))
+	if err != nil {
+		return dictionary
+	}
+	for _, word := range strings.Fields(string(content)) {
+		dictionary[strings.ToLower(word)] = true
+	}
+	return dictionary
+}
+
+// spellingIssue lists the misspelled words found on one line
+type spellingIssue struct {
+	Line  int
+	Words []string
+}
+
+// misspellingsByLine locates misspelled words in lines, skipping any found
+// in the custom dictionary
+func misspellingsByLine(lines []string, misspelled, dictionary map[string]bool) []spellingIssue {
+	var issues []spellingIssue
+	for i, line := range lines {
+		seen := make(map[string]bool)
+		var words []string
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return !unicode.IsLetter(r) && r != '\''
+		})
+		for _, word := range fields {
+			word = strings.Trim(word, "'")
+			if !misspelled[word] || dictionary[strings.ToLower(word)] || seen[word] {
+				continue
+			}
+			seen[word] = true
+			words = append(words, word)
+		}
+		if len(words) > 0 {
+			issues = append(issues, spellingIssue{Line: i + 1, Words: words})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,193 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// noteSection is one part of a note being split: a heading and the lines
+// under it, End exclusive
+type noteSection struct {
+	Heading    string
+	Start, End int
+}
+
+// runSplit breaks a note into one note per top-level heading:
+//
+//	note --split <name> --by-heading
+//
+// Each section becomes a note named after its heading and dated like the
+// original, and is replaced in the original by a [[wiki-link]] to it.
+func runSplit(config Config, flags *ParsedFlags, args []string) error {
+	byHeading := false
+	var nameArgs []string
+	for _, arg := range args {
+		if arg == "--by-heading" {
+			byHeading = true
+		} else {
+			nameArgs = append(nameArgs, arg)
+		}
+	}
+	if !byHeading || len(nameArgs) == 0 {
+		return usageErrorf("usage: note --split <name> --by-heading")
+	}
+
+	notePath, err := existingNotePath(config, strings.Join(nameArgs, " "))
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(notePath)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filepath.Base(notePath), err)
+	}
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+
+	lines := splitLines(string(content))
+	sections, level := splitSections(lines)
+	if len(sections) == 0 {
+		return fmt.Errorf("%s has no headings to split at", filepath.Base(notePath))
+	}
+
+	// Check every name first so a clash leaves nothing half split
+	date := noteDate(filepath.Base(notePath), info.ModTime())
+	dir := filepath.Dir(notePath)
+	paths := make([]string, len(sections))
+	seen := make(map[string]bool)
+	for i, section := range sections {
+		paths[i] = filepath.Join(dir, datedNoteFilename(splitNoteName(section.Heading), date))
+		if seen[paths[i]] || pathExists(paths[i]) || paths[i] == notePath {
+			return fmt.Errorf("%s already exists; rename the heading '%s' first", filepath.Base(paths[i]), section.Heading)
+		}
+		seen[paths[i]] = true
+	}
+
+	// The new notes are written before the original is rewritten, so an
+	// interruption can leave a section in two places but never in none
+	for i, section := range sections {
+		if err := createNoteFile(paths[i], []byte(joinLines(promoteHeadings(lines[section.Start:section.End], level-1)))); err != nil {
+			return err
+		}
+		postSave(config, paths[i])
+		fmt.Printf("Created %s\n", filepath.Base(paths[i]))
+	}
+
+	if err := writeFileAtomic(notePath, []byte(joinLines(replaceSections(lines, sections, paths))), notePerm(notePath)); err != nil {
+		return fmt.Errorf("error writing %s: %w", filepath.Base(notePath), err)
+	}
+	postSave(config, notePath)
+	fmt.Printf("Split %s into %d notes\n", filepath.Base(notePath), len(sections))
+	return nil
+}
+
+// splitSections finds the sections to split a note into and the heading
+// level they start at. That is level 1 unless the note has a single level 1
+// heading, its title, in which case the level 2 headings under it are used.
+// Headings inside code blocks are ignored.
+func splitSections(lines []string) ([]noteSection, int) {
+	code := codeLines(lines)
+	start := frontmatterEnd(lines)
+	headingsAt := func(level int) []int {
+		var found []int
+		for i := start; i < len(lines); i++ {
+			if !code[i] && headingLevel(lines[i]) == level {
+				found = append(found, i)
+			}
+		}
+		return found
+	}
+
+	level := 1
+	starts := headingsAt(1)
+	if len(starts) <= 1 {
+		level = 2
+		starts = headingsAt(2)
+	}
+
+	var sections []noteSection
+	for _, i := range starts {
+		end := len(lines)
+		for j := i + 1; j < len(lines); j++ {
+			if l := headingLevel(lines[j]); !code[j] && l > 0 && l <= level {
+				end = j
+				break
+			}
+		}
+		for end > i+1 && strings.TrimSpace(lines[end-1]) == "" {
+			end--
+		}
+		heading := strings.TrimSpace(strings.TrimLeft(lines[i], "#"))
+		if heading == "" {
+			continue
+		}
+		sections = append(sections, noteSection{Heading: heading, Start: i, End: end})
+	}
+	return sections, level
+}
+
+// splitNoteName makes a heading usable as a note name
+func splitNoteName(heading string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, heading)
+}
+
+// promoteHeadings raises every heading outside code blocks by levels, so a
+// level 2 section becomes a note with a level 1 title
+func promoteHeadings(lines []string, levels int) []string {
+	code := codeLines(lines)
+	promoted := make([]string, len(lines))
+	for i, line := range lines {
+		if l := headingLevel(line); !code[i] && l > levels && levels > 0 {
+			line = line[levels:]
+		}
+		promoted[i] = line
+	}
+	return promoted
+}
+
+// replaceSections swaps each section of lines for a link to the note it
+// was moved to. Blank lines between adjacent sections are dropped so the
+// links form one list.
+func replaceSections(lines []string, sections []noteSection, paths []string) []string {
+	var result []string
+	next := 0
+	for i, section := range sections {
+		between := lines[next:section.Start]
+		if i == 0 || strings.TrimSpace(strings.Join(between, "")) != "" {
+			result = append(result, between...)
+		}
+		result = append(result, "- [["+strings.TrimSuffix(filepath.Base(paths[i]), ".md")+"]]")
+		next = section.End
+	}
+	return append(result, lines[next:]...)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// NotesLockFile is held while note moves, adds to or rewrites notes, so two
+// note processes (say a capture and an archive, or a capture and --meta)
+// take turns instead of one losing what the other just wrote
+const NotesLockFile = ".note_dir.lock"
+
+// withStateLock runs fn while holding an exclusive lock on path, so two note
+// processes started at once (e.g. from shell aliases) take turns updating a
+// state file instead of losing each other's changes. The lock is taken on a
+// separate path.lock file because atomic writes replace the file itself.
+func withStateLock(path string, fn func() error) error {
+	return withLockFile(path+".lock", path, fn)
+}
+
+// withNotesLock runs fn while holding the lock on the notes directory.
+// Locks are not reentrant, so fn must not take it again.
+func withNotesLock(notesDir string, fn func() error) error {
+	return withLockFile(filepath.Join(notesDir, NotesLockFile), notesDir, fn)
+}
+
+// withLockFile runs fn while holding an exclusive lock on lockPath, naming
+// what it guards in errors
+func withLockFile(lockPath, guarded string, fn func() error) error {
+	lock, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening lock for %s: %w", guarded, err)
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		return fmt.Errorf("error locking %s: %w", guarded, err)
+	}
+	defer unlockFile(lock)
+	return fn()
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"note/pkg/notes"
+)
+
+// Finding, resolving, searching, archiving and deleting notes lives in
+// pkg/notes so other programs can reuse it. The CLI keeps its own names for
+// the pieces it uses everywhere.
+var (
+	splitLines        = notes.SplitLines
+	joinLines         = notes.JoinLines
+	headingLevel      = notes.HeadingLevel
+	frontmatterEnd    = notes.FrontmatterEnd
+	parseFrontmatter  = notes.ParseFrontmatter
+	noteTitle         = notes.Title
+	readingTime       = notes.ReadingTime
+	isBinary          = notes.IsBinary
+	formatByteSize    = notes.FormatSize
+	expandPath        = notes.ExpandPath
+	isTrue            = notes.IsTrue
+	datedNoteFilename = notes.DatedFilename
+	findMatchingNotes = notes.Match
+	walkNotes         = notes.Walk
+	isNoteFile        = notes.IsNote
+	moveNote          = notes.MoveNote
+	freeNoteName      = notes.FreeName
+	copyFile          = notes.CopyFile
+	writeFileAtomic   = notes.WriteFileAtomic
+	readTitleIndex    = notes.ReadTitleIndex
+)
+
+type (
+	SearchMatch  = notes.SearchMatch
+	SearchResult = notes.SearchResult
+)
+
+const (
+	DefaultMaxFileSize = notes.DefaultMaxFileSize
+	TitleIndexFile     = notes.TitleIndexFile
+)
+
+// noteStore returns the library view of the notes directory, using the
+// CLI's reading of maxfilesize and warning about notes search skips
+func noteStore(config Config) *notes.Store {
+	store := notes.NewStore(config.NotesDir)
+	store.MaxFileSize = config.maxFileSize()
+	store.Sticky = notes.SplitList(config.option("sticky"))
+	store.Skipped = func(path, reason string) {
+		fmt.Fprintf(os.Stderr, "⚠ Warning: skipping %s: %s\n", path, reason)
+	}
+	return store
+}
+
+// resolveNotePath maps a note name to its file; see notes.Store.Resolve
+func resolveNotePath(config Config, noteName string) string {
+	return noteStore(config).Resolve(noteName)
+}
+
+// getArchiveDir returns the path to the archive directory, checking for both "Archive" and "archive"
+func getArchiveDir(notesDir string) string {
+	return notes.NewStore(notesDir).ArchiveDir()
+}
+
+// loadTitleIndex returns the title of every note in notesDir, refreshing
+// the on-disk title cache
+func loadTitleIndex(notesDir string) map[string]string {
+	return notes.NewStore(notesDir).Titles()
+}
+
+// findNotesByTitle returns the notes whose title matches title, ignoring case
+func findNotesByTitle(notesDir, title string) []string {
+	return notes.NewStore(notesDir).FindByTitle(title)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runAliasNote handles `note --alias-note <existing> <aliasname>`, giving a
+// note a second name via a relative symlink in the notes directory
+func runAliasNote(config Config, args []string) error {
+	if len(args) != 2 {
+		return usageErrorf("usage: note --alias-note <existing> <aliasname>")
+	}
+	target, err := existingNotePath(config, args[0])
+	if err != nil {
+		return err
+	}
+
+	aliasName := args[1]
+	if !strings.HasSuffix(aliasName, ".md") {
+		aliasName += ".md"
+	}
+	aliasPath := filepath.Join(config.NotesDir, aliasName)
+	if _, err := os.Lstat(aliasPath); err == nil {
+		return fmt.Errorf("%s already exists", aliasName)
+	}
+
+	relTarget, err := filepath.Rel(filepath.Dir(aliasPath), target)
+	if err != nil {
+		relTarget = target
+	}
+	if err := os.Symlink(relTarget, aliasPath); err != nil {
+		return fmt.Errorf("error creating alias: %w", err)
+	}
+	fmt.Printf("Created %s -> %s\n", aliasName, filepath.Base(target))
+	return nil
+}
@@ -0,0 +1,253 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TagSeparator separates the levels of nested tags, as in #project/alpha
+const TagSeparator = "/"
+
+// runTags handles -t: with no tag it prints every tag and how many notes
+// carry it, with a tag it lists those notes (matching pattern, if given),
+// and with -s it searches only those notes. --tree prints the tags (under
+// the tag, if given) as a hierarchy instead.
+func runTags(config Config, flags *ParsedFlags, args []string) error {
+	tree := false
+	var patternArgs []string
+	for _, arg := range args {
+		if arg == "--tree" {
+			tree = true
+		} else {
+			patternArgs = append(patternArgs, arg)
+		}
+	}
+	pattern := strings.Join(patternArgs, " ")
+
+	tagged := noteTags(config, flags.Archive)
+	if tree {
+		if !printTagTree(os.Stdout, tagged, strings.ToLower(flags.Tag)) {
+			fmt.Println("No tags (add tags: [a, b] to a note's frontmatter or write #tag)")
+		}
+		return nil
+	}
+	if flags.Tag == "" {
+		counts := tagCounts(tagged)
+		if len(counts) == 0 {
+			fmt.Println("No tags (add tags: [a, b] to a note's frontmatter or write #tag)")
+			return nil
+		}
+		for _, tag := range sortedByCount(counts) {
+			fmt.Printf("%4d  #%s\n", counts[tag], tag)
+		}
+		return nil
+	}
+
+	if flags.Search != "" {
+		ctx, stop := interruptible()
+		defer stop()
+		fmt.Printf("Searching #%s for '%s'...\n\n", flags.Tag, strings.Join(flags.SearchTerms, "' or '"))
+		results, err := searchTagged(ctx, config, tagged, flags.Tag, flags.SearchTerms, flags.Archive)
+		if err != nil {
+			return fmt.Errorf("search %w", errInterrupted)
+		}
+		printSearchResults(results, flags.SearchTerms)
+		return nil
+	}
+
+	var names []string
+	for _, name := range collectNotes(config, pattern, flags.Archive) {
+		if hasTag(tagged[name], flags.Tag) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		fmt.Printf("No notes tagged #%s\n", flags.Tag)
+		return nil
+	}
+	return printNoteList(config, flags, names, pattern)
+}
+
+// noteTags returns the tags of every note, keyed by the name -l lists it
+// under. Notes in the notes directory come from the metadata index;
+// archived notes are read directly so the archive gets no index of its own.
+func noteTags(config Config, includeArchived bool) map[string][]string {
+	tags := make(map[string][]string)
+	for name, meta := range loadMetadata(config.NotesDir) {
+		tags[name] = meta.Tags
+	}
+	if includeArchived {
+		archiveDir := getArchiveDir(config.NotesDir)
+		for _, name := range findMatchingNotes(archiveDir, "", false) {
+			if content, err := os.ReadFile(filepath.Join(archiveDir, name)); err == nil {
+				tags[filepath.Base(archiveDir)+"/"+name] = extractMetadata(string(content)).Tags
+			}
+		}
+	}
+	return tags
+}
+
+// hasTag reports whether tags include tag or, for nested tags, one under
+// it: work matches #work and #work/projects
+func hasTag(tags []string, tag string) bool {
+	tag = strings.ToLower(tag)
+	for _, noteTag := range tags {
+		if noteTag == tag || strings.HasPrefix(noteTag, tag+TagSeparator) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagCounts counts the notes carrying each tag
+func tagCounts(tagged map[string][]string) map[string]int {
+	counts := make(map[string]int)
+	for _, tags := range tagged {
+		for _, tag := range tags {
+			counts[tag]++
+		}
+	}
+	return counts
+}
+
+// sortedByCount returns the keys of counts, most frequent first
+func sortedByCount(counts map[string]int) []string {
+	keys := sortedKeys(counts)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return counts[keys[i]] > counts[keys[j]]
+	})
+	return keys
+}
+
+// searchTagged searches the notes tagged tag for any of terms
+func searchTagged(ctx context.Context, config Config, tagged map[string][]string, tag string, terms []string, includeArchived bool) ([]SearchResult, error) {
+	results, err := searchDirs(ctx, config, searchRoots(config, includeArchived), terms)
+	if err != nil {
+		return nil, err
+	}
+	var kept []SearchResult
+	for _, result := range results {
+		if hasTag(tagged[filepath.ToSlash(result.Path)], tag) {
+			kept = append(kept, result)
+		}
+	}
+	return kept, nil
+}
+
+// tagNode is one level of the tag hierarchy: the notes carrying the tag or
+// any tag under it
+type tagNode struct {
+	notes    map[string]bool
+	children map[string]*tagNode
+}
+
+// buildTagTree arranges the tags of every note into a hierarchy, so a note
+// tagged project/alpha counts under both project and project/alpha
+func buildTagTree(tagged map[string][]string) *tagNode {
+	root := &tagNode{notes: make(map[string]bool), children: make(map[string]*tagNode)}
+	for note, tags := range tagged {
+		for _, tag := range tags {
+			node := root
+			for _, part := range strings.Split(tag, TagSeparator) {
+				child := node.children[part]
+				if child == nil {
+					child = &tagNode{notes: make(map[string]bool), children: make(map[string]*tagNode)}
+					node.children[part] = child
+				}
+				child.notes[note] = true
+				node = child
+			}
+		}
+	}
+	return root
+}
+
+// printTagTree draws the tag hierarchy with note counts, starting at tag
+// when one is given, and reports whether there was anything to draw
+func printTagTree(w io.Writer, tagged map[string][]string, tag string) bool {
+	node := buildTagTree(tagged)
+	if tag == "" {
+		for _, name := range sortedKeys(node.children) {
+			child := node.children[name]
+			fmt.Fprintf(w, "#%s (%d)\n", name, len(child.notes))
+			writeTagBranches(w, child, "")
+		}
+		return len(node.children) > 0
+	}
+	for _, part := range strings.Split(tag, TagSeparator) {
+		if node = node.children[part]; node == nil {
+			return false
+		}
+	}
+	fmt.Fprintf(w, "#%s (%d)\n", tag, len(node.notes))
+	writeTagBranches(w, node, "")
+	return true
+}
+
+// writeTagBranches draws the tags under node, indented by prefix
+func writeTagBranches(w io.Writer, node *tagNode, prefix string) {
+	names := sortedKeys(node.children)
+	for i, name := range names {
+		branch, indent := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, indent = "└── ", "    "
+		}
+		child := node.children[name]
+		fmt.Fprintf(w, "%s%s%s (%d)\n", prefix, branch, name, len(child.notes))
+		writeTagBranches(w, child, prefix+indent)
+	}
+}
+
+// runCompleteTags prints tag completions for prefix for the shell
+// completions of -t
+func runCompleteTags(config Config, prefix string) error {
+	for _, tag := range tagCompletions(sortedKeys(tagCounts(noteTags(config, false))), prefix) {
+		fmt.Println(tag)
+	}
+	return nil
+}
+
+// tagCompletions completes prefix one level of nesting at a time: "pro"
+// offers project and project/ when project has tags under it, and
+// "project/" offers project/alpha
+func tagCompletions(tags []string, prefix string) []string {
+	prefix = strings.ToLower(strings.TrimPrefix(prefix, "#"))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, prefix) {
+			continue
+		}
+		rest := tag[len(prefix):]
+		if i := strings.Index(rest, TagSeparator); i >= 0 {
+			if i > 0 {
+				seen[prefix+rest[:i]] = true
+			}
+			seen[prefix+rest[:i+1]] = true
+		} else {
+			seen[tag] = true
+		}
+	}
+	return sortedKeys(seen)
+}
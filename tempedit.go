@@ -0,0 +1,115 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// editViaTempFile copies a note to a local temp file, runs the editor on the
+// copy, and writes the result back atomically. If the original changed while
+// the editor was open, the edited copy is saved next to it as a conflict file
+// instead of overwriting someone else's changes.
+func editViaTempFile(editor, notePath string, line int) error {
+	original, err := os.ReadFile(notePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading %s: %w", notePath, err)
+	}
+	existed := err == nil
+
+	tempDir, err := os.MkdirTemp("", "note-edit-")
+	if err != nil {
+		return fmt.Errorf("error creating temp directory: %w", err)
+	}
+
+	// Keep the original filename so the editor picks up markdown settings
+	tempPath := filepath.Join(tempDir, filepath.Base(notePath))
+	if err := os.WriteFile(tempPath, original, 0644); err != nil {
+		os.RemoveAll(tempDir)
+		return fmt.Errorf("error creating temp copy: %w", err)
+	}
+
+	cmd := exec.Command(editor, editorArgs(editor, tempPath, line)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		// Leave the temp copy in place so nothing typed is lost
+		return fmt.Errorf("%w (your edits are in %s)", editorExitError(err, notePath), tempPath)
+	}
+
+	edited, err := os.ReadFile(tempPath)
+	if err != nil {
+		return fmt.Errorf("error reading temp copy: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Nothing to write back if the note was left untouched
+	if bytes.Equal(edited, original) && (existed || len(edited) == 0) {
+		return nil
+	}
+
+	conflict, err := remoteChanged(notePath, original, existed)
+	if err != nil {
+		return err
+	}
+	if conflict {
+		conflictPath := conflictFilePath(notePath, time.Now())
+		if err := writeFileAtomic(conflictPath, edited, notePerm(notePath)); err != nil {
+			return fmt.Errorf("error saving conflict copy: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "⚠ Warning: %s changed while you were editing.\n", filepath.Base(notePath))
+		fmt.Fprintf(os.Stderr, "  Your version was saved as %s\n", filepath.Base(conflictPath))
+		return nil
+	}
+
+	if err := writeFileAtomic(notePath, edited, notePerm(notePath)); err != nil {
+		return fmt.Errorf("error writing %s: %w", notePath, err)
+	}
+	return nil
+}
+
+// remoteChanged reports whether the note on disk differs from the snapshot
+// taken before the editor was started
+func remoteChanged(notePath string, snapshot []byte, existed bool) (bool, error) {
+	current, err := os.ReadFile(notePath)
+	if os.IsNotExist(err) {
+		// Deleted meanwhile only counts as a conflict if it used to exist
+		return existed, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error re-reading %s: %w", notePath, err)
+	}
+	if !existed {
+		return true, nil
+	}
+	return !bytes.Equal(current, snapshot), nil
+}
+
+// conflictFilePath returns the name used to save edits that could not be
+// written back, e.g. meeting-20260109.conflict-20260109-153000.md
+func conflictFilePath(notePath string, now time.Time) string {
+	base := strings.TrimSuffix(notePath, ".md")
+	return fmt.Sprintf("%s.conflict-%s.md", base, now.Format("20060102-150405"))
+}
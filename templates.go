@@ -0,0 +1,71 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var templateVarPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][\w-]*)\s*\}\}`)
+
+// templateVars returns the built-in template variables for a note created
+// or edited at now
+func templateVars(noteName string, now time.Time) map[string]string {
+	return map[string]string{
+		"date":     now.Format("2006-01-02"),
+		"time":     now.Format("15:04"),
+		"datetime": now.Format("2006-01-02 15:04"),
+		"weekday":  now.Format("Monday"),
+		"year":     now.Format("2006"),
+		"month":    now.Format("01"),
+		"day":      now.Format("02"),
+		"note":     noteName,
+		"title":    strings.ReplaceAll(noteName, "_", " "),
+		"user":     os.Getenv("USER"),
+	}
+}
+
+// expandTemplate replaces {{name}} placeholders with values from vars.
+// Unknown placeholders are left as they are so typos stay visible.
+func expandTemplate(text string, vars map[string]string) string {
+	return templateVarPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := templateVarPattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// parseTemplateVars reads extra "key=value" arguments into vars, returning
+// the arguments that were not assignments
+func parseTemplateVars(args []string, vars map[string]string) []string {
+	var rest []string
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if ok && templateVarPattern.MatchString("{{"+key+"}}") {
+			vars[key] = value
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest
+}
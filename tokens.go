@@ -0,0 +1,226 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TokensFile holds the API tokens accepted by --serve, one
+// "id<TAB>scope<TAB>sha256<TAB>created" line each. Only hashes are stored,
+// so a token is shown once when it is created and never again.
+const TokensFile = ".note_tokens"
+
+// Token scopes, from least to most access
+const (
+	ScopeRead   = "read"   // list, search and resolve notes
+	ScopeAppend = "append" // also create notes and append to them
+	ScopeFull   = "full"   // everything
+)
+
+var scopeLevels = map[string]int{ScopeRead: 1, ScopeAppend: 2, ScopeFull: 3}
+
+// apiToken is one stored token
+type apiToken struct {
+	ID      string
+	Scope   string
+	Hash    string
+	Created time.Time
+}
+
+// allows reports whether the token's scope covers the required scope
+func (t apiToken) allows(scope string) bool {
+	return scopeLevels[t.Scope] >= scopeLevels[scope]
+}
+
+// runServeToken manages the tokens accepted by --serve:
+//
+//	note --serve-token create [--read-only | --scope read|append|full]
+//	note --serve-token list
+//	note --serve-token revoke <id>
+func runServeToken(args []string) error {
+	tokensPath, err := tokensFilePath()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return usageErrorf("usage: note --serve-token create|list|revoke")
+	}
+
+	switch args[0] {
+	case "create":
+		scope := ScopeFull
+		for i := 1; i < len(args); i++ {
+			switch args[i] {
+			case "--read-only":
+				scope = ScopeRead
+			case "--scope":
+				if i+1 >= len(args) {
+					return usageErrorf("--scope requires read, append or full")
+				}
+				i++
+				scope = args[i]
+			}
+		}
+		if _, ok := scopeLevels[scope]; !ok {
+			return fmt.Errorf("unknown scope '%s' (use read, append or full)", scope)
+		}
+		token, secret, err := createToken(tokensPath, scope, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s token %s:\n\n  %s\n\nStore it now; it cannot be shown again.\n", token.Scope, token.ID, secret)
+		return nil
+	case "list":
+		tokens, err := loadTokens(tokensPath)
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			fmt.Println("No tokens (create one with 'note --serve-token create')")
+		}
+		for _, token := range tokens {
+			fmt.Printf("%s  %-6s  created %s\n", token.ID, token.Scope, token.Created.Format("2006-01-02 15:04"))
+		}
+		return nil
+	case "revoke":
+		if len(args) < 2 {
+			return usageErrorf("usage: note --serve-token revoke <id>")
+		}
+		if err := revokeToken(tokensPath, args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked token %s\n", args[1])
+		return nil
+	}
+	return fmt.Errorf("unknown token command '%s' (use create, list or revoke)", args[0])
+}
+
+// tokensFilePath keeps tokens next to ~/.note rather than in the notes
+// directory, which may be synced or shared
+func tokensFilePath() (string, error) {
+	configPath, err := configFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), TokensFile), nil
+}
+
+// createToken generates a token with the given scope and stores its hash,
+// returning the token and the secret to hand to the client
+func createToken(tokensPath, scope string, now time.Time) (apiToken, string, error) {
+	random := make([]byte, 28)
+	if _, err := rand.Read(random); err != nil {
+		return apiToken{}, "", fmt.Errorf("error generating token: %w", err)
+	}
+	id := hex.EncodeToString(random[:4])
+	secret := "note_" + id + "_" + hex.EncodeToString(random[4:])
+
+	token := apiToken{ID: id, Scope: scope, Hash: hashToken(secret), Created: now}
+	err := withStateLock(tokensPath, func() error {
+		tokens, err := loadTokens(tokensPath)
+		if err != nil {
+			return err
+		}
+		return saveTokens(tokensPath, append(tokens, token))
+	})
+	if err != nil {
+		return apiToken{}, "", err
+	}
+	return token, secret, nil
+}
+
+// revokeToken removes the token with the given id
+func revokeToken(tokensPath, id string) error {
+	return withStateLock(tokensPath, func() error {
+		tokens, err := loadTokens(tokensPath)
+		if err != nil {
+			return err
+		}
+		var kept []apiToken
+		for _, token := range tokens {
+			if token.ID != id {
+				kept = append(kept, token)
+			}
+		}
+		if len(kept) == len(tokens) {
+			return fmt.Errorf("no token with id '%s'", id)
+		}
+		return saveTokens(tokensPath, kept)
+	})
+}
+
+// authenticate returns the stored token matching secret
+func authenticate(tokens []apiToken, secret string) (apiToken, bool) {
+	hash := hashToken(secret)
+	for _, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token.Hash), []byte(hash)) == 1 {
+			return token, true
+		}
+	}
+	return apiToken{}, false
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func loadTokens(tokensPath string) ([]apiToken, error) {
+	file, err := os.Open(tokensPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading tokens: %w", err)
+	}
+	defer file.Close()
+
+	var tokens []apiToken
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.Split(scanner.Text(), "\t")
+		if len(parts) != 4 {
+			continue
+		}
+		created, _ := strconv.ParseInt(parts[3], 10, 64)
+		tokens = append(tokens, apiToken{ID: parts[0], Scope: parts[1], Hash: parts[2], Created: time.Unix(created, 0)})
+	}
+	return tokens, scanner.Err()
+}
+
+func saveTokens(tokensPath string, tokens []apiToken) error {
+	var b strings.Builder
+	for _, token := range tokens {
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%d\n", token.ID, token.Scope, token.Hash, token.Created.Unix())
+	}
+	if err := writeFileAtomic(tokensPath, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("error saving tokens: %w", err)
+	}
+	return nil
+}
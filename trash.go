@@ -0,0 +1,206 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"note/pkg/notes"
+)
+
+// TrashDir holds deleted notes, one folder per day they were deleted on.
+// Like other hidden directories it is never listed or searched.
+const TrashDir = notes.TrashDir
+
+// DefaultTrashDays is how long --purge keeps deleted notes unless
+// trash_days is set
+const DefaultTrashDays = 30
+
+// runDelete moves the notes matching pattern to the trash, after asking
+// unless --yes is given. Locked notes need --force.
+//
+//	note --delete <pattern> [--yes]
+func runDelete(config Config, flags *ParsedFlags, args []string) error {
+	yes, rest := trashArgs(args)
+	pattern := strings.Join(rest, " ")
+	if pattern == "" {
+		return usageErrorf("usage: note --delete <pattern> [--yes]")
+	}
+
+	var selected []string
+	for _, note := range noteStore(config).List(notes.Query{Pattern: pattern, Encrypted: true}) {
+		if isNoteLocked(note.Path) && !flags.Force {
+			fmt.Fprintf(os.Stderr, "Warning: %s is locked; use --force to delete it\n", note.Name)
+			continue
+		}
+		selected = append(selected, note.Name)
+	}
+	if len(selected) == 0 {
+		fmt.Printf("No notes found matching '%s'\n", pattern)
+		return nil
+	}
+	fmt.Println("Deleting:")
+	for _, name := range selected {
+		fmt.Printf("  %s\n", name)
+	}
+	if !yes && !askYesNo(os.Stdin, os.Stdout, fmt.Sprintf("Move %d note(s) to the trash?", len(selected))) {
+		return nil
+	}
+	if err := trashNotes(config, selected, time.Now(), flags.Force); err != nil {
+		return err
+	}
+	fmt.Printf("Moved %d note(s) to %s\n", len(selected), TrashDir)
+	autoCommit(config, fmt.Sprintf("Delete %d note(s)", len(selected)))
+	return nil
+}
+
+// trashArgs separates --yes from a command's other arguments
+func trashArgs(args []string) (bool, []string) {
+	yes := false
+	var rest []string
+	for _, arg := range args {
+		if arg == "--yes" {
+			yes = true
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	return yes, rest
+}
+
+// askYesNo asks question on out and reports whether the answer read from in
+// was yes
+func askYesNo(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s (y/N): ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// trashNotes moves the named notes, relative to the notes directory, into
+// the trash folder for now's day. Locked notes are refused unless force is
+// set. The moves are journaled like -d, so an interrupted run can be
+// finished or undone with --repair, and made under the notes lock.
+func trashNotes(config Config, names []string, now time.Time, force bool) error {
+	return withNotesLock(config.NotesDir, func() error {
+		store := noteStore(config)
+		for _, name := range names {
+			if !force && isNoteLocked(filepath.Join(config.NotesDir, name)) {
+				return fmt.Errorf("%s is locked; unlock it first", name)
+			}
+		}
+		steps := make([]journalStep, len(names))
+		for i, name := range names {
+			steps[i] = journalStep{From: filepath.Join(config.NotesDir, name), To: store.TrashPath(name, now)}
+		}
+		if err := beginJournal(config.NotesDir, "delete", steps); err != nil {
+			return err
+		}
+
+		failed := false
+		for _, name := range names {
+			if err := store.Trash(name, now); err != nil {
+				fmt.Fprintf(os.Stderr, "Error deleting %s: %v\n", name, err)
+				failed = true
+			}
+		}
+		if failed {
+			return fmt.Errorf("some notes could not be moved to the trash; run 'note --repair' to retry or undo")
+		}
+		finishJournal(config.NotesDir)
+		return nil
+	})
+}
+
+// runPurge permanently removes the notes deleted more than trash_days
+// (default 30) days ago, or --days N, after asking unless --yes is given
+//
+//	note --purge [--days N] [--yes]
+func runPurge(config Config, args []string) error {
+	yes, rest := trashArgs(args)
+	days := DefaultTrashDays
+	if value := config.option("trash_days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid trash_days '%s' (use a number of days)", value)
+		}
+		days = n
+	}
+	if len(rest) == 2 && rest[0] == "--days" {
+		n, err := strconv.Atoi(rest[1])
+		if err != nil || n < 0 {
+			return usageErrorf("invalid --days '%s' (use a number of days)", rest[1])
+		}
+		days = n
+	} else if len(rest) != 0 {
+		return usageErrorf("usage: note --purge [--days N] [--yes]")
+	}
+
+	store := noteStore(config)
+	before := time.Now().AddDate(0, 0, -days)
+	count := len(store.Trashed(before))
+	if count == 0 {
+		fmt.Printf("No notes deleted more than %d day(s) ago\n", days)
+		return nil
+	}
+	if !yes && !askYesNo(os.Stdin, os.Stdout, fmt.Sprintf("Permanently remove %d note(s) deleted more than %d day(s) ago?", count, days)) {
+		return nil
+	}
+	return purgeTrash(store, before)
+}
+
+// runEmptyTrash permanently removes every deleted note, after asking
+// unless --yes is given
+//
+//	note --empty-trash [--yes]
+func runEmptyTrash(config Config, args []string) error {
+	yes, rest := trashArgs(args)
+	if len(rest) != 0 {
+		return usageErrorf("usage: note --empty-trash [--yes]")
+	}
+	store := noteStore(config)
+	count := len(store.Trashed(time.Time{}))
+	if count == 0 {
+		fmt.Println("The trash is empty")
+		return nil
+	}
+	if !yes && !askYesNo(os.Stdin, os.Stdout, fmt.Sprintf("Permanently remove all %d note(s) in the trash?", count)) {
+		return nil
+	}
+	return purgeTrash(store, time.Time{})
+}
+
+// purgeTrash permanently removes the notes deleted before the day of
+// before, or everything in the trash when before is zero
+func purgeTrash(store *notes.Store, before time.Time) error {
+	return withNotesLock(store.Dir, func() error {
+		count, err := store.Purge(before)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Permanently removed %d note(s)\n", count)
+		return nil
+	})
+}
@@ -0,0 +1,413 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// browser is the state of the interactive note browser: the notes it
+// offers, the filter typed so far and the selected match
+type browser struct {
+	config          Config
+	pattern         string
+	includeArchived bool
+	notes           []string // note names, most recently modified first
+	filter          string
+	matches         []string
+	selected        int
+	message         string
+
+	// pick makes Enter return the selected note instead of opening it, for
+	// choosing among candidates (fixed notes, instead of those matching
+	// pattern); cancel names what Esc leads to
+	pick       bool
+	candidates []string
+	cancel     string
+}
+
+// runBrowse lists notes full-screen with fuzzy filtering and a preview of
+// the selected note. Typing filters, arrow keys select, Enter opens the
+// note and Ctrl-A, Ctrl-R and Ctrl-D archive, rename and delete it. Without
+// a terminal it prints the list like -l.
+func runBrowse(config Config, flags *ParsedFlags, pattern string) error {
+	if !isInputFromTerminal() || !isOutputToTerminal() {
+		return listNotes(config, flags, pattern, flags.Archive)
+	}
+
+	b := &browser{config: config, pattern: pattern, includeArchived: flags.Archive}
+	_, err := b.run()
+	return err
+}
+
+// run shows the browser until it is quit or, when picking, a note is
+// picked, returning the picked note's name ("" if none)
+func (b *browser) run() (string, error) {
+	restore, err := rawTerminal()
+	if err != nil {
+		return "", err
+	}
+	defer func() { restore() }()
+
+	config := b.config
+	b.reload()
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		width, height := terminalSize()
+		fmt.Print("\033[H\033[2J" + b.render(width, height))
+		b.message = ""
+
+		key, err := readKey(reader)
+		if err != nil {
+			return "", nil
+		}
+		if b.pick && (key == "\x01" || key == "\x12" || key == "\x04") {
+			continue
+		}
+		switch key {
+		case "\x03":
+			fmt.Println()
+			return "", nil
+		case "\x1b":
+			// Esc clears the filter first, then quits
+			if b.filter == "" {
+				fmt.Println()
+				return "", nil
+			}
+			b.setFilter("")
+		case "up", "\x10":
+			b.selected = max(0, b.selected-1)
+		case "down", "\x0e":
+			b.selected = min(len(b.matches)-1, b.selected+1)
+		case "\x7f", "\b":
+			if runes := []rune(b.filter); len(runes) > 0 {
+				b.setFilter(string(runes[:len(runes)-1]))
+			}
+		case "\r", "\n":
+			if note := b.current(); note != "" && b.pick {
+				fmt.Println()
+				return note, nil
+			} else if note != "" {
+				restore()
+				editErr := editNote(config, filepath.Join(config.NotesDir, note))
+				if restore, err = rawTerminal(); err != nil {
+					return "", err
+				}
+				b.report("", editErr)
+			}
+		case "\x01":
+			b.report(b.archive())
+		case "\x12":
+			if note := b.current(); note != "" {
+				if newName := promptLine(reader, "Rename "+note+" to: "); newName != "" {
+					b.report(b.rename(newName))
+				}
+			}
+		case "\x04":
+			if note := b.current(); note != "" && promptLine(reader, "Delete "+note+"? (y/N) ") == "y" {
+				b.report(b.delete())
+			}
+		default:
+			if r := []rune(key); len(r) == 1 && unicode.IsPrint(r[0]) {
+				b.setFilter(b.filter + key)
+			}
+		}
+	}
+}
+
+// reload rereads the notes, keeping the filter and, where it can, the
+// selection
+func (b *browser) reload() {
+	b.notes = b.candidates
+	if !b.pick {
+		b.notes = pickerCandidates(b.config, b.pattern, b.includeArchived)
+	}
+	b.setFilter(b.filter)
+}
+
+func (b *browser) setFilter(filter string) {
+	b.filter = filter
+	b.matches = fuzzyFilter(b.notes, filter)
+	b.selected = max(0, min(b.selected, len(b.matches)-1))
+}
+
+// current returns the selected note's name, or "" when nothing matches
+func (b *browser) current() string {
+	if b.selected < len(b.matches) {
+		return b.matches[b.selected]
+	}
+	return ""
+}
+
+// report shows the outcome of an action and rereads the notes it changed
+func (b *browser) report(message string, err error) {
+	if err != nil {
+		message = "Error: " + err.Error()
+	}
+	b.message = message
+	b.reload()
+}
+
+// archive moves the selected note into the archive, journaled like -d and
+// under the notes lock
+func (b *browser) archive() (string, error) {
+	note := b.current()
+	if note == "" {
+		return "", nil
+	}
+	store := noteStore(b.config)
+	archiveDir := store.ArchiveDir()
+	if strings.HasPrefix(note, filepath.Base(archiveDir)+"/") {
+		return "", fmt.Errorf("%s is already archived", note)
+	}
+	err := withNotesLock(b.config.NotesDir, func() error {
+		to := filepath.Join(archiveDir, note)
+		if pathExists(to) {
+			return fmt.Errorf("%s already exists", journalName(b.config.NotesDir, to))
+		}
+		steps := []journalStep{{From: filepath.Join(b.config.NotesDir, note), To: to}}
+		if err := beginJournal(b.config.NotesDir, "archive", steps); err != nil {
+			return err
+		}
+		if err := store.Archive(note); err != nil {
+			return fmt.Errorf("error archiving %s: %w; run 'note --repair' to retry or undo the archive", note, err)
+		}
+		finishJournal(b.config.NotesDir)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	autoCommit(b.config, "Archive 1 note(s)")
+	return "Archived " + note, nil
+}
+
+// rename gives the selected note a new name in the same directory the way
+// --mv does, keeping its date stamp and rewriting links to it
+func (b *browser) rename(newName string) (string, error) {
+	note := b.current()
+	if note == "" {
+		return "", nil
+	}
+	from := filepath.Join(b.config.NotesDir, note)
+	filename, err := renamedFilename(filepath.Base(from), newName, false, time.Now())
+	if err != nil {
+		return "", err
+	}
+	to := filepath.Join(filepath.Dir(from), filename)
+	var updated int
+	err = withNotesLock(b.config.NotesDir, func() error {
+		updated, err = renameNote(b.config, &ParsedFlags{}, from, to)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	renamed := journalName(b.config.NotesDir, to)
+	autoCommit(b.config, fmt.Sprintf("Rename %s to %s", note, renamed))
+	message := "Renamed " + note + " to " + renamed
+	if updated > 0 {
+		message += fmt.Sprintf(", updated links in %d note(s)", updated)
+	}
+	return message, nil
+}
+
+// delete moves the selected note to the trash like --delete; locked notes
+// are refused
+func (b *browser) delete() (string, error) {
+	note := b.current()
+	if note == "" {
+		return "", nil
+	}
+	if err := trashNotes(b.config, []string{note}, time.Now(), false); err != nil {
+		return "", err
+	}
+	autoCommit(b.config, "Delete "+note)
+	return "Moved " + note + " to " + TrashDir, nil
+}
+
+// render draws the browser in width by height characters: the filter, the
+// matching notes beside a preview of the selected one, and a status line
+func (b *browser) render(width, height int) string {
+	listWidth := max(20, min(40, width/3))
+	previewWidth := max(10, width-listWidth-3)
+	rows := max(1, height-4)
+
+	// Scroll so the selected note stays in view
+	first := max(0, b.selected-rows+1)
+	var preview []string
+	if note := b.current(); note != "" {
+		preview = previewLines(filepath.Join(b.config.NotesDir, note), previewWidth)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "> %s\n", b.filter)
+	out.WriteString(strings.Repeat("─", min(width, listWidth+3+previewWidth)) + "\n")
+	for r := 0; r < rows; r++ {
+		cell := ""
+		if i := first + r; i < len(b.matches) {
+			cell = b.matches[i]
+		}
+		cell = padRight(cell, listWidth)
+		if first+r == b.selected && len(b.matches) > 0 {
+			cell = ColorSelected + cell + ColorReset
+		}
+		line := cell + " │ "
+		if r < len(preview) {
+			line += preview[r]
+		}
+		out.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	status := b.message
+	if status == "" {
+		status = fmt.Sprintf("%d/%d notes", len(b.matches), len(b.notes))
+	}
+	if b.pick {
+		fmt.Fprintf(&out, "%s\n↑↓ select  Enter open  Esc %s", status, b.cancel)
+	} else {
+		fmt.Fprintf(&out, "%s\n↑↓ select  Enter open  ^A archive  ^R rename  ^D delete  Esc quit", status)
+	}
+	return out.String()
+}
+
+// previewLines renders the start of a note for the preview pane: no
+// frontmatter, headings highlighted and every line cut to width
+func previewLines(notePath string, width int) []string {
+	if isEncryptedNote(notePath) {
+		return []string{"(encrypted note)"}
+	}
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return []string{"(cannot read note)"}
+	}
+	if isBinary(content) {
+		return []string{"(binary file)"}
+	}
+	lines := splitLines(string(content))
+	lines = lines[frontmatterEnd(lines):]
+	code := codeLines(lines)
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines, code = lines[1:], code[1:]
+	}
+
+	preview := make([]string, len(lines))
+	for i, line := range lines {
+		line = strings.TrimRight(strings.ReplaceAll(line, "\t", "    "), " ")
+		if len([]rune(line)) > width {
+			line = padRight(line, width)
+		}
+		if !code[i] && headingLevel(line) > 0 {
+			line = ColorCyan + line + ColorReset
+		}
+		preview[i] = line
+	}
+	return preview
+}
+
+// fuzzyFilter returns the names containing the characters of query in
+// order, ignoring case, best matches first; names keep their order when
+// query is empty or they match equally well
+func fuzzyFilter(names []string, query string) []string {
+	if query == "" {
+		return names
+	}
+	type match struct {
+		name  string
+		score int
+	}
+	var matches []match
+	for _, name := range names {
+		if score, ok := fuzzyScore(name, query); ok {
+			matches = append(matches, match{name, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	result := make([]string, len(matches))
+	for i, m := range matches {
+		result[i] = m.name
+	}
+	return result
+}
+
+// fuzzyScore reports whether query's characters appear in name in order,
+// scoring runs of adjacent characters and matches at the start of a word
+// higher
+func fuzzyScore(name, query string) (int, bool) {
+	nameRunes := []rune(strings.ToLower(name))
+	score, last := 0, -2
+	i := 0
+	for _, q := range strings.ToLower(query) {
+		for i < len(nameRunes) && nameRunes[i] != q {
+			i++
+		}
+		if i == len(nameRunes) {
+			return 0, false
+		}
+		score++
+		if i == last+1 {
+			score += 2
+		}
+		if i == 0 || strings.ContainsRune("-_ /.", nameRunes[i-1]) {
+			score += 3
+		}
+		last = i
+		i++
+	}
+	return score, true
+}
+
+// promptLine reads a line of input on the bottom line of the screen;
+// Esc or Ctrl-C cancels and returns ""
+func promptLine(reader *bufio.Reader, prompt string) string {
+	return editLine(reader, prompt, "")
+}
+
+// editLine is promptLine with the input starting as initial
+func editLine(reader *bufio.Reader, prompt, initial string) string {
+	input := []rune(initial)
+	for {
+		fmt.Printf("\r\033[K%s%s", prompt, string(input))
+		key, err := readKey(reader)
+		if err != nil {
+			return ""
+		}
+		switch key {
+		case "\x1b", "\x03":
+			return ""
+		case "\r", "\n":
+			return strings.TrimSpace(string(input))
+		case "\x7f", "\b":
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+			}
+		default:
+			if r := []rune(key); len(r) == 1 && unicode.IsPrint(r[0]) {
+				input = append(input, r[0])
+			}
+		}
+	}
+}
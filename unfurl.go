@@ -0,0 +1,172 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultUnfurlTimeout bounds fetching one page title unless timeout is set
+const DefaultUnfurlTimeout = 5 * time.Second
+
+var (
+	bareURLPattern   = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+	pageTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// unfurlText turns the bare URLs in appended text into [Title](url) links
+// when unfurl is enabled. Titles are cached in the user cache directory, and
+// URLs whose title can't be fetched in time are left as they are; Ctrl-C
+// skips the remaining fetches.
+func unfurlText(config Config, text string) string {
+	if !config.boolOption("unfurl") {
+		return text
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return text
+	}
+	cachePath := filepath.Join(cacheDir, "note", "link-titles")
+	ctx, stop := interruptible()
+	defer stop()
+	return unfurlLinks(text, func(url string) string {
+		return cachedPageTitle(cachePath, url, func(url string) (string, error) {
+			ctx, cancel := context.WithTimeout(ctx, config.netTimeout(DefaultUnfurlTimeout))
+			defer cancel()
+			return fetchPageTitle(ctx, url)
+		})
+	})
+}
+
+// unfurlLinks replaces each bare URL in text with a markdown link titled by
+// titleOf. URLs that are already links (<url>, [text](url)), inside code
+// or titled "" are left alone.
+func unfurlLinks(text string, titleOf func(url string) string) string {
+	lines := strings.Split(text, "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, loc := range bareURLPattern.FindAllStringIndex(line, -1) {
+			start, end := loc[0], loc[1]
+			// Sentence punctuation after a URL is not part of it
+			end = start + len(strings.TrimRight(line[start:end], ".,;:!?"))
+			before := line[:start]
+			if strings.HasSuffix(before, "](") || strings.HasSuffix(before, "<") || strings.HasSuffix(before, "]: ") ||
+				strings.Count(before, "`")%2 == 1 {
+				continue
+			}
+			title := titleOf(line[start:end])
+			if title == "" {
+				continue
+			}
+			b.WriteString(line[last:start])
+			fmt.Fprintf(&b, "[%s](%s)", escapeLinkText(title), line[start:end])
+			last = end
+		}
+		b.WriteString(line[last:])
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func escapeLinkText(text string) string {
+	return strings.NewReplacer(`[`, `\[`, `]`, `\]`).Replace(text)
+}
+
+// cachedPageTitle returns url's title from the "url<TAB>title" lines in
+// cachePath, fetching and caching it when it isn't there. Failed fetches
+// are not cached, so they are retried next time.
+func cachedPageTitle(cachePath, url string, fetch func(url string) (string, error)) string {
+	if file, err := os.Open(cachePath); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if cachedURL, title, ok := strings.Cut(scanner.Text(), "\t"); ok && cachedURL == url {
+				file.Close()
+				return title
+			}
+		}
+		file.Close()
+	}
+
+	title, err := fetch(url)
+	if err != nil || title == "" {
+		return ""
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+		withStateLock(cachePath, func() error {
+			file, err := os.OpenFile(cachePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(file, "%s\t%s\n", url, title)
+			return file.Close()
+		})
+	}
+	return title
+}
+
+// fetchPageTitle fetches an HTML page and returns its <title>, collapsed
+// to one line
+func fetchPageTitle(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" {
+		return "", fmt.Errorf("%s is not an HTML page", url)
+	}
+
+	// The title is in the head, so the start of the page is enough
+	page, err := io.ReadAll(io.LimitReader(resp.Body, 512<<10))
+	if err != nil {
+		return "", err
+	}
+	match := pageTitlePattern.FindSubmatch(page)
+	if match == nil {
+		return "", fmt.Errorf("%s has no title", url)
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(string(match[1]))), " "), nil
+}
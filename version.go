@@ -0,0 +1,144 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LatestReleaseURL is queried by the opt-in update check
+const LatestReleaseURL = "https://api.github.com/repos/brockers/note/releases/latest"
+
+// UpdateCheckInterval is how long a fetched release tag is reused before
+// asking again
+const UpdateCheckInterval = 24 * time.Hour
+
+// DefaultUpdateCheckTimeout bounds the release check unless timeout is set
+const DefaultUpdateCheckTimeout = 3 * time.Second
+
+// printVersion prints the bare version on the first line, so scripts can
+// keep reading it with head -1, followed by build details
+func printVersion(config Config) {
+	fmt.Println(Version)
+	fmt.Printf("  Commit:     %s\n", CommitSHA)
+	fmt.Printf("  Build date: %s\n", BuildDate)
+	fmt.Printf("  Go version: %s (%s/%s)\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	// The update check is opt-in and only runs here, never in the
+	// background. It sends nothing but an anonymous request for the
+	// latest release tag.
+	if !config.boolOption("updatecheck") || Version == "dev" {
+		return
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return
+	}
+	ctx, stop := interruptible()
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, config.netTimeout(DefaultUpdateCheckTimeout))
+	defer cancel()
+	latest := latestRelease(filepath.Join(cacheDir, "note", "latest-release"), time.Now(), func() (string, error) {
+		return fetchLatestRelease(ctx)
+	})
+	if latest != "" && compareVersions(latest, Version) > 0 {
+		fmt.Printf("\nA newer release is available: %s (https://github.com/brockers/note/releases)\n", latest)
+	}
+}
+
+// latestRelease returns the latest release tag, reusing the tag cached in
+// cachePath when it is recent enough and calling fetch otherwise. Failures
+// return "" so an offline machine just skips the check.
+func latestRelease(cachePath string, now time.Time, fetch func() (string, error)) string {
+	if data, err := os.ReadFile(cachePath); err == nil {
+		stamp, tag, ok := strings.Cut(strings.TrimSpace(string(data)), "\t")
+		if seconds, err := strconv.ParseInt(stamp, 10, 64); ok && err == nil {
+			if now.Sub(time.Unix(seconds, 0)) < UpdateCheckInterval {
+				return tag
+			}
+		}
+	}
+
+	tag, err := fetch()
+	if err != nil {
+		return ""
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+		writeFileAtomic(cachePath, []byte(fmt.Sprintf("%d\t%s\n", now.Unix(), tag)), 0644)
+	}
+	return tag
+}
+
+func fetchLatestRelease(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, LatestReleaseURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("release check returned %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", err
+	}
+	return release.TagName, nil
+}
+
+// compareVersions compares two semantic versions such as "v0.2.0" and
+// "0.1.5", returning -1, 0 or 1. Pre-release and build suffixes are ignored.
+func compareVersions(a, b string) int {
+	parse := func(version string) [3]int {
+		var parts [3]int
+		version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+		if i := strings.IndexAny(version, "-+"); i >= 0 {
+			version = version[:i]
+		}
+		for i, field := range strings.SplitN(version, ".", 3) {
+			parts[i], _ = strconv.Atoi(field)
+		}
+		return parts
+	}
+
+	va, vb := parse(a), parse(b)
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,315 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// notesGitignore keeps note's own state files and editor leftovers out of
+// the history of a repository note creates
+const notesGitignore = ".note_*\n*.swp\n*~\n"
+
+// gitIn runs git in dir, returning its trimmed output
+func gitIn(dir string, args ...string) (string, error) {
+	output, err := gitOutput(dir, args...)
+	return strings.TrimSpace(string(output)), err
+}
+
+// gitOutput runs git in dir, returning its output as is. Error output is
+// folded into the error so callers can show what went wrong.
+func gitOutput(dir string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// notesRepo makes sure there is a repository to read history from: the
+// notes directory's own, or a new one when git=true
+func notesRepo(config Config) error {
+	if _, err := exec.LookPath("git"); err == nil {
+		if _, err := gitIn(config.NotesDir, "rev-parse", "--show-toplevel"); err == nil {
+			return nil
+		}
+	}
+	if !config.boolOption("git") {
+		return fmt.Errorf("no version history; set git=true in ~/.note to commit every save")
+	}
+	return ensureNotesRepo(config.NotesDir)
+}
+
+// ensureNotesRepo makes sure the notes directory is inside a git
+// repository, creating one (with a .gitignore and a first commit of the
+// existing notes) on first use
+func ensureNotesRepo(notesDir string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git=true but git is not installed; notes are not being versioned")
+	}
+	if _, err := gitIn(notesDir, "rev-parse", "--show-toplevel"); err == nil {
+		return nil
+	}
+	if _, err := gitIn(notesDir, "init", "--quiet"); err != nil {
+		return err
+	}
+	ignore := filepath.Join(notesDir, ".gitignore")
+	if !pathExists(ignore) {
+		if err := os.WriteFile(ignore, []byte(notesGitignore), 0644); err != nil {
+			return fmt.Errorf("error writing .gitignore: %w", err)
+		}
+	}
+	return commitNotes(notesDir, "Start note history")
+}
+
+// commitNotes commits every change under the notes directory, and nothing
+// else when it lives inside a bigger repository. It is a no-op when there
+// is nothing to commit.
+func commitNotes(notesDir, message string) error {
+	if _, err := gitIn(notesDir, "add", "--all", "--", "."); err != nil {
+		return err
+	}
+	if _, err := gitIn(notesDir, "diff", "--cached", "--quiet", "--", "."); err == nil {
+		return nil
+	}
+	args := []string{"commit", "--quiet", "--no-verify", "-m", message}
+	// Commit even on machines where no git identity is set up
+	if email, _ := gitIn(notesDir, "config", "user.email"); email == "" {
+		args = append([]string{"-c", "user.name=note", "-c", "user.email=note@localhost"}, args...)
+	}
+	_, err := gitIn(notesDir, append(args, "--", ".")...)
+	return err
+}
+
+// autoCommit records the current state of the notes when git=true.
+// Failures are warnings only: a save never fails for want of a commit.
+func autoCommit(config Config, message string) {
+	if !config.boolOption("git") {
+		return
+	}
+	err := ensureNotesRepo(config.NotesDir)
+	if err == nil {
+		err = commitNotes(config.NotesDir, message)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// noteRelPath returns a note's path relative to the notes directory, for
+// messages and git pathspecs
+func noteRelPath(config Config, notePath string) string {
+	if rel, err := filepath.Rel(config.NotesDir, notePath); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.Base(notePath)
+}
+
+// runHistory shows the commits that changed a note, following renames such
+// as archiving:
+//
+//	note --history <name>
+func runHistory(config Config, name string) error {
+	notePath, err := existingNotePath(config, name)
+	if err != nil {
+		return err
+	}
+	if err := notesRepo(config); err != nil {
+		return err
+	}
+	rel := noteRelPath(config, notePath)
+	log, err := gitIn(config.NotesDir, "log", "--follow", "--date=format:%Y-%m-%d %H:%M", "--format=%h  %ad  %s", "--", rel)
+	if err != nil {
+		return err
+	}
+	if log == "" {
+		fmt.Printf("No history for %s yet\n", rel)
+		return nil
+	}
+	fmt.Println(log)
+	return nil
+}
+
+// runRevert restores a note to how it was at a commit from --history,
+// recording the restore as a new commit so it can itself be undone:
+//
+//	note --revert <name> <ref>
+func runRevert(config Config, flags *ParsedFlags, args []string) error {
+	if len(args) < 2 {
+		return usageErrorf("usage: note --revert <name> <ref>")
+	}
+	ref := args[len(args)-1]
+	notePath, err := existingNotePath(config, strings.Join(args[:len(args)-1], " "))
+	if err != nil {
+		return err
+	}
+	if err := notesRepo(config); err != nil {
+		return err
+	}
+	rel := noteRelPath(config, notePath)
+	prefix, err := gitIn(config.NotesDir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return err
+	}
+	content, err := gitOutput(config.NotesDir, "show", ref+":"+prefix+rel)
+	if err != nil {
+		return fmt.Errorf("%s has no version at %s", rel, ref)
+	}
+
+	wasLocked, err := checkNoteWritable(notePath, flags.Force)
+	if err != nil {
+		return err
+	}
+	defer restoreLock(notePath, wasLocked)
+	// Snapshot the current version first so reverting never loses edits
+	if err := commitNotes(config.NotesDir, "Update "+rel); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(notePath, content, notePerm(notePath)); err != nil {
+		return fmt.Errorf("error writing %s: %w", rel, err)
+	}
+	if err := commitNotes(config.NotesDir, fmt.Sprintf("Revert %s to %s", rel, ref)); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s from %s\n", rel, ref)
+	return nil
+}
+
+// DefaultStaleAfter is how old a line must be for --annotate to flag it
+const DefaultStaleAfter = "26w"
+
+// lineOrigin is when one line of a note was last changed
+type lineOrigin struct {
+	Time        time.Time // zero for changes not committed yet
+	Commit      string
+	Text        string
+	Uncommitted bool
+}
+
+// runAnnotate prints every line of a note with the date it was last
+// changed, flagging lines older than --stale (default 26w):
+//
+//	note --annotate <name> [--stale 12w]
+func runAnnotate(config Config, args []string) error {
+	stale := DefaultStaleAfter
+	var nameArgs []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--stale" {
+			if i+1 >= len(args) {
+				return usageErrorf("--stale requires an age such as 12w or 90d")
+			}
+			i++
+			stale = args[i]
+			continue
+		}
+		nameArgs = append(nameArgs, args[i])
+	}
+	if len(nameArgs) == 0 {
+		return usageErrorf("usage: note --annotate <name> [--stale 12w]")
+	}
+	staleAfter, err := parseTTL(stale)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	notePath, err := existingNotePath(config, strings.Join(nameArgs, " "))
+	if err != nil {
+		return err
+	}
+	if err := notesRepo(config); err != nil {
+		return err
+	}
+	origins, err := blameNote(config, notePath)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	color := isOutputToTerminal()
+	width := len(strconv.Itoa(len(origins)))
+	for i, origin := range origins {
+		when := fmt.Sprintf("%-16s", "not committed")
+		if !origin.Uncommitted {
+			when = fmt.Sprintf("%s %5s", origin.Time.Format("2006-01-02"), formatAge(now.Sub(origin.Time)))
+		}
+		if color && !origin.Uncommitted && now.Sub(origin.Time) > staleAfter {
+			when = ColorYellow + when + ColorReset
+		}
+		fmt.Printf("%s  %*d  %s\n", when, width, i+1, origin.Text)
+	}
+	return nil
+}
+
+// blameHeader starts each line's block in git blame --line-porcelain
+var blameHeader = regexp.MustCompile(`^([0-9a-f]{40,64}) \d+ \d+`)
+
+// blameNote returns where each line of a note came from, using git blame
+// on the working copy so unsaved-to-git edits show as uncommitted
+func blameNote(config Config, notePath string) ([]lineOrigin, error) {
+	output, err := gitOutput(config.NotesDir, "blame", "--line-porcelain", "--", noteRelPath(config, notePath))
+	if err != nil {
+		return nil, fmt.Errorf("no history for %s yet", noteRelPath(config, notePath))
+	}
+
+	var origins []lineOrigin
+	var current lineOrigin
+	for _, line := range strings.Split(string(output), "\n") {
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			current.Text = line[1:]
+			origins = append(origins, current)
+			current = lineOrigin{}
+		case strings.HasPrefix(line, "author-time "):
+			if seconds, err := strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64); err == nil {
+				current.Time = time.Unix(seconds, 0)
+			}
+		case blameHeader.MatchString(line):
+			current.Commit = blameHeader.FindStringSubmatch(line)[1]
+			current.Uncommitted = strings.Trim(current.Commit, "0") == ""
+		}
+	}
+	return origins, nil
+}
+
+// formatAge describes a duration in the largest whole unit, e.g. 3d, 5mo
+func formatAge(age time.Duration) string {
+	days := int(age.Hours() / 24)
+	switch {
+	case days < 1:
+		return "today"
+	case days < 30:
+		return fmt.Sprintf("%dd", days)
+	case days < 365:
+		return fmt.Sprintf("%dmo", days/30)
+	}
+	return fmt.Sprintf("%dy", days/365)
+}
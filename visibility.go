@@ -0,0 +1,98 @@
+/*
+Copyright (C) 2025  Note CLI Contributors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Who --serve shows a notebook's notes to, set in the [visibility] section
+const (
+	VisibilityPrivate = "private" // never served
+	VisibilityShared  = "shared"  // to API tokens and share links
+	VisibilityPublic  = "public"  // also to anyone, without a token
+)
+
+// checkVisibility rejects [visibility] values --serve would not understand,
+// so a typo never exposes a notebook
+func checkVisibility(config Config) error {
+	for _, notebook := range sortedKeys(config.Visibility) {
+		switch config.Visibility[notebook] {
+		case VisibilityPrivate, VisibilityShared, VisibilityPublic:
+		default:
+			return fmt.Errorf("invalid visibility '%s' for %s (use private, shared or public)", config.Visibility[notebook], notebook)
+		}
+	}
+	return nil
+}
+
+// noteVisibility returns the visibility of the note at notePath: that of
+// the most specific notebook (or note) listed in [visibility] containing
+// it, else the * entry, else shared. Unknown values count as private.
+func noteVisibility(config Config, notePath string) string {
+	rel, err := filepath.Rel(config.NotesDir, notePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return VisibilityPrivate
+	}
+	rel = filepath.ToSlash(rel)
+
+	visibility, found := config.Visibility["*"], config.Visibility["*"] != ""
+	longest := -1
+	for notebook, value := range config.Visibility {
+		name := strings.Trim(filepath.ToSlash(notebook), "/")
+		if name == "*" || name == "" || len(name) <= longest || !inNotebook(rel, []string{name}) {
+			continue
+		}
+		visibility, found, longest = value, true, len(name)
+	}
+	if !found {
+		return VisibilityShared
+	}
+	switch visibility {
+	case VisibilityShared, VisibilityPublic:
+		return visibility
+	}
+	return VisibilityPrivate
+}
+
+// hasPublicNotes reports whether anything is public, which is the only case
+// in which --serve answers requests without a token
+func hasPublicNotes(config Config) bool {
+	for _, value := range config.Visibility {
+		if value == VisibilityPublic {
+			return true
+		}
+	}
+	return false
+}
+
+// visibleTo returns whether --serve may show a note to a caller, who
+// sees shared and public notes with a token and only public ones without
+func visibleTo(config Config, authenticated bool) func(notePath string) bool {
+	return func(notePath string) bool {
+		switch noteVisibility(config, notePath) {
+		case VisibilityPublic:
+			return true
+		case VisibilityShared:
+			return authenticated
+		}
+		return false
+	}
+}